@@ -11,10 +11,16 @@ import (
 	devxCfg "github.com/xBlaz3kx/DevX/configuration"
 	devxHttp "github.com/xBlaz3kx/DevX/http"
 	"github.com/xBlaz3kx/DevX/observability"
+	grpcApi "github.com/xBlaz3kx/distributed-scheduler/internal/api/grpc"
 	api "github.com/xBlaz3kx/distributed-scheduler/internal/api/http"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/egress"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/logger"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/events"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/idempotency"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/webhook"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
 	"go.uber.org/zap"
 )
@@ -31,7 +37,13 @@ var configFilePath string
 type config struct {
 	Observability observability.Config   `mapstructure:"observability" yaml:"observability" json:"observability"`
 	Http          devxHttp.Configuration `mapstructure:"http" yaml:"http" json:"http"`
+	GRPC          grpcApi.Config         `mapstructure:"grpc" yaml:"grpc" json:"grpc"`
 	DB            database.Config        `mapstructure:"db" yaml:"db" json:"db"`
+	Egress        egress.Config          `mapstructure:"egress" yaml:"egress" json:"egress"`
+	Auth          api.AuthConfig         `mapstructure:"auth" yaml:"auth" json:"auth"`
+	Idempotency   api.IdempotencyConfig  `mapstructure:"idempotency" yaml:"idempotency" json:"idempotency"`
+	Events        api.EventsConfig       `mapstructure:"events" yaml:"events" json:"events"`
+	Webhooks      api.WebhooksConfig     `mapstructure:"webhooks" yaml:"webhooks" json:"webhooks"`
 	OpenAPI       struct {
 		Scheme string `conf:"default:http" json:"scheme,omitempty"`
 		Enable bool   `conf:"default:true" json:"enable,omitempty"`
@@ -46,15 +58,24 @@ var rootCmd = &cobra.Command{
 		devxCfg.SetDefaults(serviceName)
 		devxCfg.SetupEnv(serviceName)
 
-		viper.SetDefault("storage.encryption.key", "ishouldreallybechanged")
+		viper.SetDefault("storage.encryption.devMode", false)
+		viper.SetDefault("log.sensitiveHeaders", model.DefaultSensitiveHeaders)
+		viper.SetDefault("egress.deniedCidrs", egress.DefaultDeniedCIDRs)
+		viper.SetDefault("auth.enabled", true)
+		viper.SetDefault("auth.jwt.enabled", false)
+		viper.SetDefault("auth.jwt.rolesClaim", "roles")
+		viper.SetDefault("auth.jwt.namespaceClaim", "namespace")
+		viper.SetDefault("idempotency.keyTtl", idempotency.DefaultTTL)
+		viper.SetDefault("events.retention", events.DefaultRetention)
+		viper.SetDefault("webhooks.retention", webhook.DefaultRetention)
+		viper.SetDefault("grpc.enabled", true)
+		viper.SetDefault("grpc.address", "0.0.0.0:9090")
 		viper.SetDefault("db.disable_tls", true)
 		viper.SetDefault("db.max_open_conns", 1)
 		viper.SetDefault("db.max_idle_conns", 10)
 		viper.SetDefault("observability.logging.level", observability.LogLevelInfo)
 
 		devxCfg.InitConfig("", "./config", ".")
-
-		postgres.SetEncryptor(security.NewEncryptorFromEnv())
 	},
 	Run: runCmd,
 }
@@ -92,9 +113,28 @@ func runCmd(cmd *cobra.Command, args []string) {
 	log.Info("Starting the manager", zap.String("version", serviceInfo.Version), zap.Any("config", cfg))
 	defer log.Info("shutdown complete")
 
+	model.SetSensitiveHeaders(viper.GetStringSlice("log.sensitiveHeaders"))
+
+	egressPolicy, err := egress.NewPolicy(cfg.Egress)
+	if err != nil {
+		log.Fatal("invalid egress policy", zap.Error(err))
+	}
+
+	// Encryption
+	encryptor, err := security.NewEncryptorFromEnv()
+	if err != nil {
+		log.Fatal("invalid encryption configuration", zap.Error(err))
+	}
+
+	if viper.GetBool("storage.encryption.devMode") {
+		log.Warn("encryption dev mode is enabled, insecure encryption keys are accepted")
+	}
+
+	postgres.SetEncryptor(encryptor)
+
 	// Database Support
 	log.Info("Connecting to the database", zap.String("host", cfg.DB.Host))
-	db, err := database.Open(database.Config{
+	dbConfig := database.Config{
 		User:         cfg.DB.User,
 		Password:     cfg.DB.Password,
 		Host:         cfg.DB.Host,
@@ -102,26 +142,51 @@ func runCmd(cmd *cobra.Command, args []string) {
 		MaxIdleConns: cfg.DB.MaxIdleConns,
 		MaxOpenConns: cfg.DB.MaxOpenConns,
 		DisableTLS:   cfg.DB.DisableTLS,
-	})
+	}
+	db, err := database.Open(dbConfig)
 	if err != nil {
 		log.Fatal("failed to connect to the database", zap.Error(err))
 	}
 
+	if err := postgres.VerifyEncryptionCanary(ctx, db); err != nil {
+		log.Fatal("encryption key check failed", zap.Error(err))
+	}
+
 	defer func() {
 		log.Info("Closing the database connection")
 		_ = db.Close()
 	}()
 
+	// Execution events are received on a dedicated connection and streamed to clients
+	eventsService := events.NewService(postgres.New(db, log), database.NewListener(dbConfig), log).WithRetention(cfg.Events.Retention)
+	go eventsService.Run(ctx)
+
+	// Webhook events written to the outbox are delivered in the background
+	webhookService := webhook.NewService(postgres.New(db, log), log).WithEgressPolicy(egressPolicy).WithRetention(cfg.Webhooks.Retention)
+	go webhookService.Run(ctx)
+
+	// Responses of requests with an Idempotency-Key header are kept for retries, and deleted in the background
+	idempotencyService := idempotency.NewService(postgres.New(db, log), log).WithTTL(cfg.Idempotency.KeyTTL)
+	go idempotencyService.Run(ctx)
+
 	httpServer := devxHttp.NewServer(cfg.Http, obs)
-	api.Api(httpServer.Router(), api.APIMuxConfig{
-		Log: log,
-		DB:  db,
+	err = api.Api(httpServer.Router(), api.APIMuxConfig{
+		Log:         log,
+		DB:          db,
+		Egress:      egressPolicy,
+		Auth:        cfg.Auth,
+		Idempotency: idempotencyService,
+		Events:      eventsService,
+		Webhooks:    webhookService,
 		OpenApi: api.OpenApiConfig{
 			Enabled: cfg.OpenAPI.Enable,
 			Scheme:  cfg.OpenAPI.Scheme,
 			Host:    cfg.OpenAPI.Host,
 		},
 	})
+	if err != nil {
+		log.Fatal("failed to set up the API", zap.Error(err))
+	}
 
 	go func() {
 		log.Info("Starting HTTP server", zap.String("host", cfg.Http.Address))
@@ -129,6 +194,27 @@ func runCmd(cmd *cobra.Command, args []string) {
 		httpServer.Run(databaseCheck)
 	}()
 
+	// The gRPC API shares the services of the REST API on a separate port
+	if cfg.GRPC.Enabled {
+		grpcServer, err := grpcApi.NewServer(grpcApi.APIConfig{
+			Log:    log,
+			DB:     db,
+			Egress: egressPolicy,
+			Auth:   cfg.Auth,
+			Events: eventsService,
+		})
+		if err != nil {
+			log.Fatal("failed to set up the gRPC API", zap.Error(err))
+		}
+
+		go func() {
+			log.Info("Starting gRPC server", zap.String("host", cfg.GRPC.Address))
+			if err := grpcServer.Run(ctx, cfg.GRPC.Address); err != nil {
+				log.Fatal("gRPC server failed", zap.Error(err))
+			}
+		}()
+	}
+
 	// Shutdown
 	<-ctx.Done()
 	log.Info("Shutting down the manager")
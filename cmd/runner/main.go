@@ -14,11 +14,14 @@ import (
 	devxHttp "github.com/xBlaz3kx/DevX/http"
 	"github.com/xBlaz3kx/DevX/observability"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/executor"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/egress"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/logger"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/metrics"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/runner"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/credential"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
 	"go.uber.org/zap"
@@ -37,6 +40,7 @@ type config struct {
 	Observability        observability.Config        `mapstructure:"observability" yaml:"observability" json:"observability"`
 	Http                 devxHttp.Configuration      `mapstructure:"http" yaml:"http" json:"http"`
 	DB                   database.Config             `mapstructure:"db" yaml:"db" json:"db"`
+	Egress               egress.Config               `mapstructure:"egress" yaml:"egress" json:"egress"`
 	ID                   string                      `mapstructure:"id" yaml:"id" json:"id,omitempty"`
 	JobExecutionSettings runner.JobExecutionSettings `mapstructure:"jobExecutionSettings" yaml:"jobExecutionSettings" json:"jobExecutionSettings"`
 }
@@ -48,7 +52,9 @@ var rootCmd = &cobra.Command{
 		devxCfg.SetDefaults(serviceName)
 		devxCfg.SetupEnv(serviceName)
 
-		viper.SetDefault("storage.encryption.key", "ishouldreallybechanged")
+		viper.SetDefault("storage.encryption.devMode", false)
+		viper.SetDefault("log.sensitiveHeaders", model.DefaultSensitiveHeaders)
+		viper.SetDefault("egress.deniedCidrs", egress.DefaultDeniedCIDRs)
 		viper.SetDefault("db.disableTls", true)
 		viper.SetDefault("db.maxOpenConns", 1)
 		viper.SetDefault("db.maxIdleConns", 10)
@@ -59,8 +65,6 @@ var rootCmd = &cobra.Command{
 		viper.SetDefault("jobExecutionSettings.maxJobLockTime", time.Minute)
 
 		devxCfg.InitConfig(configFilePath, "./config", ".")
-
-		postgres.SetEncryptor(security.NewEncryptorFromEnv())
 	},
 	Run: runCmd,
 }
@@ -99,6 +103,25 @@ func runCmd(cmd *cobra.Command, args []string) {
 
 	log.Info("Using config", zap.Any("config", cfg))
 
+	model.SetSensitiveHeaders(viper.GetStringSlice("log.sensitiveHeaders"))
+
+	egressPolicy, err := egress.NewPolicy(cfg.Egress)
+	if err != nil {
+		log.Fatal("Invalid egress policy", zap.Error(err))
+	}
+
+	// Encryption
+	encryptor, err := security.NewEncryptorFromEnv()
+	if err != nil {
+		log.Fatal("Invalid encryption configuration", zap.Error(err))
+	}
+
+	if viper.GetBool("storage.encryption.devMode") {
+		log.Warn("Encryption dev mode is enabled, insecure encryption keys are accepted")
+	}
+
+	postgres.SetEncryptor(encryptor)
+
 	// Database
 	log.Info("Connecting to the database", zap.String("host", cfg.DB.Host))
 	db, err := database.Open(database.Config{
@@ -114,6 +137,10 @@ func runCmd(cmd *cobra.Command, args []string) {
 		log.Fatal("Unable to establish DB connection", zap.Error(err))
 	}
 
+	if err := postgres.VerifyEncryptionCanary(ctx, db); err != nil {
+		log.Fatal("Encryption key check failed", zap.Error(err))
+	}
+
 	defer func() {
 		log.Info("Closing the database connection")
 		_ = db.Close()
@@ -126,15 +153,18 @@ func runCmd(cmd *cobra.Command, args []string) {
 
 	jobService := job.NewService(store, log)
 
-	executorFactory := executor.NewFactory(&http.Client{Timeout: 30 * time.Second})
+	executorFactory := executor.NewFactory(&http.Client{Timeout: 30 * time.Second}, egressPolicy)
+
+	credentialService := credential.NewService(store, log)
 
 	runner := runner.New(runner.Config{
-		JobService:      jobService,
-		Metrics:           metrics.NewRunnerMetrics(cfg.Observability.Metrics),
-		Log:             log,
-		ExecutorFactory: executorFactory,
-		InstanceId:      cfg.ID,
-		JobExecution:    cfg.JobExecutionSettings,
+		JobService:         jobService,
+		CredentialResolver: credentialService,
+		Metrics:            metrics.NewRunnerMetrics(cfg.Observability.Metrics),
+		Log:                log,
+		ExecutorFactory:    executorFactory,
+		InstanceId:         cfg.ID,
+		JobExecution:       cfg.JobExecutionSettings,
 	})
 	runner.Start()
 
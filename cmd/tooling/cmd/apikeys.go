@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/apikey"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
+)
+
+var apiKeysCmd = &cobra.Command{
+	Use:   "apikeys",
+	Short: "Manage API keys.",
+}
+
+var apiKeysCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Issue a new API key. The key is printed once and can't be retrieved again.",
+	Args:  cobra.ExactArgs(1),
+	Run:   apiKeysCreateRun,
+}
+
+var apiKeysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys.",
+	Run:   apiKeysListRun,
+}
+
+var apiKeysRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke an API key.",
+	Args:  cobra.ExactArgs(1),
+	Run:   apiKeysRevokeRun,
+}
+
+var (
+	apiKeysDBConfig  database.Config
+	apiKeyRole       string
+	apiKeysNamespace string
+)
+
+func init() {
+	rootCmd.AddCommand(apiKeysCmd)
+	apiKeysCmd.AddCommand(apiKeysCreateCmd, apiKeysListCmd, apiKeysRevokeCmd)
+
+	apiKeysCmd.PersistentFlags().StringVar(&apiKeysDBConfig.User, "user", "scheduler", "database user")
+	apiKeysCmd.PersistentFlags().StringVar(&apiKeysDBConfig.Password, "pass", "scheduler", "database password")
+	apiKeysCmd.PersistentFlags().StringVar(&apiKeysDBConfig.Host, "host", "localhost:5432", "database host")
+	apiKeysCmd.PersistentFlags().StringVar(&apiKeysDBConfig.Name, "name", "scheduler", "database name")
+	apiKeysCmd.PersistentFlags().BoolVar(&apiKeysDBConfig.DisableTLS, "disable_tls", true, "database sslmode disabled")
+	apiKeysCmd.PersistentFlags().IntVar(&apiKeysDBConfig.MaxIdleConns, "max_idle_conns", 3, "database max idle connections")
+	apiKeysCmd.PersistentFlags().IntVar(&apiKeysDBConfig.MaxOpenConns, "max_open_conns", 2, "database max open connections")
+	apiKeysCmd.PersistentFlags().StringVar(&apiKeysNamespace, "namespace", model.DefaultNamespace, "namespace of the keys")
+
+	apiKeysCreateCmd.Flags().StringVar(&apiKeyRole, "role", string(model.RoleAdmin), "role granted to the key (viewer, operator or admin)")
+}
+
+// apiKeyService connects to the database and returns the API key service. The returned function closes the connection.
+func apiKeyService() (*apikey.Service, func()) {
+	logger := otelzap.L()
+
+	db, err := database.Open(apiKeysDBConfig)
+	if err != nil {
+		logger.Sugar().Fatalf("unable to create database connection: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := database.StatusCheck(ctx, db); err != nil {
+		logger.Sugar().Fatalf("unable to connect to the database: %v", err)
+	}
+
+	return apikey.NewService(postgres.New(db, logger), logger), func() { _ = db.Close() }
+}
+
+func apiKeysCreateRun(cmd *cobra.Command, args []string) {
+	service, closeDB := apiKeyService()
+	defer closeDB()
+
+	created, err := service.CreateAPIKey(context.Background(), apiKeysNamespace, model.APIKeyCreate{Name: args[0], Role: model.Role(apiKeyRole)})
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to create API key: %v", err)
+		return
+	}
+
+	fmt.Printf("Created %s API key %q in namespace %s (ID %s, prefix %s).\n", created.Role, created.Name, created.Namespace, created.ID, created.Prefix)
+	fmt.Println("Store the key securely, it will not be shown again:")
+	fmt.Println(created.Key)
+}
+
+func apiKeysListRun(cmd *cobra.Command, args []string) {
+	service, closeDB := apiKeyService()
+	defer closeDB()
+
+	apiKeys, err := service.ListAPIKeys(context.Background(), apiKeysNamespace, 1000, 0)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to list API keys: %v", err)
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "ID\tNAME\tPREFIX\tROLE\tCREATED\tLAST USED\tREVOKED")
+	for _, apiKey := range apiKeys {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			apiKey.ID,
+			apiKey.Name,
+			apiKey.Prefix,
+			apiKey.Role,
+			apiKey.CreatedAt.Format(time.RFC3339),
+			formatNullTime(apiKey.LastUsedAt.Time, apiKey.LastUsedAt.Valid),
+			formatNullTime(apiKey.RevokedAt.Time, apiKey.RevokedAt.Valid),
+		)
+	}
+	_ = writer.Flush()
+}
+
+func formatNullTime(t time.Time, valid bool) string {
+	if !valid {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func apiKeysRevokeRun(cmd *cobra.Command, args []string) {
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("invalid API key ID: %v", err)
+		return
+	}
+
+	service, closeDB := apiKeyService()
+	defer closeDB()
+
+	if err := service.RevokeAPIKey(context.Background(), apiKeysNamespace, id); err != nil {
+		otelzap.L().Sugar().Fatalf("unable to revoke API key: %v", err)
+		return
+	}
+
+	fmt.Printf("Revoked API key %s.\n", id)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/spf13/cobra"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/namespace"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
+	"gopkg.in/guregu/null.v4"
+)
+
+var namespacesCmd = &cobra.Command{
+	Use:   "namespaces",
+	Short: "Manage namespace quotas.",
+}
+
+var namespacesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List namespaces with quotas.",
+	Run:   namespacesListRun,
+}
+
+var namespacesSetQuotaCmd = &cobra.Command{
+	Use:   "set-quota <namespace>",
+	Short: "Set the quota of a namespace. Negative limits are unlimited.",
+	Args:  cobra.ExactArgs(1),
+	Run:   namespacesSetQuotaRun,
+}
+
+var (
+	namespacesDBConfig     database.Config
+	maxJobs                int64
+	maxExecutionsPerMinute int64
+)
+
+func init() {
+	rootCmd.AddCommand(namespacesCmd)
+	namespacesCmd.AddCommand(namespacesListCmd, namespacesSetQuotaCmd)
+
+	namespacesCmd.PersistentFlags().StringVar(&namespacesDBConfig.User, "user", "scheduler", "database user")
+	namespacesCmd.PersistentFlags().StringVar(&namespacesDBConfig.Password, "pass", "scheduler", "database password")
+	namespacesCmd.PersistentFlags().StringVar(&namespacesDBConfig.Host, "host", "localhost:5432", "database host")
+	namespacesCmd.PersistentFlags().StringVar(&namespacesDBConfig.Name, "name", "scheduler", "database name")
+	namespacesCmd.PersistentFlags().BoolVar(&namespacesDBConfig.DisableTLS, "disable_tls", true, "database sslmode disabled")
+	namespacesCmd.PersistentFlags().IntVar(&namespacesDBConfig.MaxIdleConns, "max_idle_conns", 3, "database max idle connections")
+	namespacesCmd.PersistentFlags().IntVar(&namespacesDBConfig.MaxOpenConns, "max_open_conns", 2, "database max open connections")
+
+	namespacesSetQuotaCmd.Flags().Int64Var(&maxJobs, "max-jobs", -1, "maximum number of jobs")
+	namespacesSetQuotaCmd.Flags().Int64Var(&maxExecutionsPerMinute, "max-executions-per-minute", -1, "maximum number of job executions per minute")
+}
+
+// namespaceService connects to the database and returns the namespace service. The returned function closes the connection.
+func namespaceService() (*namespace.Service, func()) {
+	logger := otelzap.L()
+
+	db, err := database.Open(namespacesDBConfig)
+	if err != nil {
+		logger.Sugar().Fatalf("unable to create database connection: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := database.StatusCheck(ctx, db); err != nil {
+		logger.Sugar().Fatalf("unable to connect to the database: %v", err)
+	}
+
+	return namespace.NewService(postgres.New(db, logger), logger), func() { _ = db.Close() }
+}
+
+// quotaLimit converts a limit flag to a quota limit, where negative limits are unlimited.
+func quotaLimit(limit int64) null.Int {
+	if limit < 0 {
+		return null.Int{}
+	}
+
+	return null.IntFrom(limit)
+}
+
+func formatQuotaLimit(limit null.Int) string {
+	if !limit.Valid {
+		return "unlimited"
+	}
+
+	return fmt.Sprint(limit.Int64)
+}
+
+func namespacesListRun(cmd *cobra.Command, args []string) {
+	service, closeDB := namespaceService()
+	defer closeDB()
+
+	namespaces, err := service.ListNamespaces(context.Background(), 1000, 0)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to list namespaces: %v", err)
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "NAME\tMAX JOBS\tMAX EXECUTIONS PER MINUTE\tUPDATED")
+	for _, ns := range namespaces {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+			ns.Name,
+			formatQuotaLimit(ns.MaxJobs),
+			formatQuotaLimit(ns.MaxExecutionsPerMinute),
+			ns.UpdatedAt.Format(time.RFC3339),
+		)
+	}
+	_ = writer.Flush()
+}
+
+func namespacesSetQuotaRun(cmd *cobra.Command, args []string) {
+	service, closeDB := namespaceService()
+	defer closeDB()
+
+	ns, err := service.SetQuota(context.Background(), args[0], model.NamespaceQuota{
+		MaxJobs:                quotaLimit(maxJobs),
+		MaxExecutionsPerMinute: quotaLimit(maxExecutionsPerMinute),
+	})
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to set namespace quota: %v", err)
+		return
+	}
+
+	fmt.Printf("Set quota of namespace %s: max jobs %s, max executions per minute %s.\n",
+		ns.Name, formatQuotaLimit(ns.MaxJobs), formatQuotaLimit(ns.MaxExecutionsPerMinute))
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/spf13/cobra"
+	devxCfg "github.com/xBlaz3kx/DevX/configuration"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
+)
+
+var rotateKeysCmd = &cobra.Command{
+	Use:   "rotate-keys",
+	Short: "Re-encrypt all stored secrets with the active encryption key.",
+	Long: `Re-encrypt the secrets of all jobs and credentials with the active encryption key.
+
+The encryption is configured like the manager's (storage.encryption), either from the configuration file passed in
+--config or from the MANAGER_STORAGE_ENCRYPTION_* environment variables, e.g.
+  MANAGER_STORAGE_ENCRYPTION_KEYS='{"v1": "<old key>", "v2": "<new key>"}' MANAGER_STORAGE_ENCRYPTION_ACTIVEKEY=v2 tooling rotate-keys
+
+All keys that existing secrets may be encrypted with must be part of the keyring. Keys are validated like by the manager.
+Make sure the manager and runner are configured with the same keyring (with the new key active)
+before rotating, and only remove the old key from their configuration once the rotation completes.
+
+If a KEK provider is configured with storage.encryption.provider, the secrets are migrated to envelope encryption
+with the provider, and the keys are only used to decrypt the existing secrets.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		// Keys are never passed as flags, so they don't end up in the process list or the shell history
+		devxCfg.SetupEnv("manager")
+		devxCfg.InitConfig(rotateConfigFile, "./config", ".")
+	},
+	Run: rotateKeysRun,
+}
+
+var (
+	rotateConfigFile string
+	rotateBatchSize  int
+	rotateKeysDBCfg  database.Config
+)
+
+func init() {
+	rootCmd.AddCommand(rotateKeysCmd)
+	rotateKeysCmd.Flags().StringVar(&rotateConfigFile, "config", "", "configuration file of the manager with the encryption keys")
+	rotateKeysCmd.Flags().IntVar(&rotateBatchSize, "batch-size", 100, "number of records re-encrypted per transaction")
+	rotateKeysCmd.Flags().StringVar(&rotateKeysDBCfg.User, "user", "scheduler", "database user")
+	rotateKeysCmd.Flags().StringVar(&rotateKeysDBCfg.Password, "pass", "scheduler", "database password")
+	rotateKeysCmd.Flags().StringVar(&rotateKeysDBCfg.Host, "host", "localhost:5432", "database host")
+	rotateKeysCmd.Flags().StringVar(&rotateKeysDBCfg.Name, "name", "scheduler", "database name")
+	rotateKeysCmd.Flags().BoolVar(&rotateKeysDBCfg.DisableTLS, "disable_tls", true, "database sslmode disabled")
+	rotateKeysCmd.Flags().IntVar(&rotateKeysDBCfg.MaxIdleConns, "max_idle_conns", 3, "database max idle connections")
+	rotateKeysCmd.Flags().IntVar(&rotateKeysDBCfg.MaxOpenConns, "max_open_conns", 2, "database max open connections")
+}
+
+func rotateKeysRun(cmd *cobra.Command, args []string) {
+	logger := otelzap.L()
+	sugar := logger.Sugar()
+
+	encryptor, err := security.NewEncryptorFromEnv()
+	if err != nil {
+		sugar.Fatalf("invalid encryption configuration: %v", err)
+		return
+	}
+
+	postgres.SetEncryptor(encryptor)
+
+	db, err := database.Open(rotateKeysDBCfg)
+	if err != nil {
+		sugar.Fatalf("unable to create database connection: %v", err)
+		return
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := database.StatusCheck(ctx, db); err != nil {
+		sugar.Fatalf("unable to connect to the database: %v", err)
+		return
+	}
+
+	result, err := postgres.NewKeyRotator(db, logger).Rotate(ctx, rotateBatchSize)
+	if err != nil {
+		sugar.Fatalf("unable to rotate encryption keys (rotated %d jobs, %d job versions, %d credentials, %d webhook subscriptions so far): %v", result.Jobs, result.JobVersions, result.Credentials, result.WebhookSubscriptions, err)
+		return
+	}
+
+	sugar.Infof("Encryption key rotation complete! Rotated %d jobs, %d job versions, %d credentials and %d webhook subscriptions", result.Jobs, result.JobVersions, result.Credentials, result.WebhookSubscriptions)
+}
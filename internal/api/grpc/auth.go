@@ -0,0 +1,191 @@
+package grpc
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	schedulerv1 "github.com/xBlaz3kx/distributed-scheduler/api/proto/scheduler/v1"
+	api "github.com/xBlaz3kx/distributed-scheduler/internal/api/http"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/audit"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/auth"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys of calls, the gRPC equivalents of the headers of the REST API.
+const (
+	APIKeyMetadata        = "x-api-key"
+	AuthorizationMetadata = "authorization"
+	RequestIDMetadata     = "x-request-id"
+)
+
+// methodRoles are the roles required to call the methods of the job service, the same as for the REST routes.
+var methodRoles = map[string]model.Role{
+	schedulerv1.JobService_CreateJob_FullMethodName:         model.RoleOperator,
+	schedulerv1.JobService_GetJob_FullMethodName:            model.RoleViewer,
+	schedulerv1.JobService_UpdateJob_FullMethodName:         model.RoleOperator,
+	schedulerv1.JobService_DeleteJob_FullMethodName:         model.RoleAdmin,
+	schedulerv1.JobService_ListJobs_FullMethodName:          model.RoleViewer,
+	schedulerv1.JobService_ListJobExecutions_FullMethodName: model.RoleViewer,
+	schedulerv1.JobService_WatchExecutions_FullMethodName:   model.RoleViewer,
+}
+
+// anonymous is the identity of all calls when authentication is disabled.
+var anonymous = &auth.Identity{Subject: "anonymous", Namespace: model.DefaultNamespace, Role: model.RoleAdmin, Method: auth.MethodNone}
+
+// requestIDPattern limits the request IDs accepted from clients, so they can be safely logged and stored.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// identityContextKey is the context key of the authenticated identity.
+type identityContextKey struct{}
+
+// authenticator authenticates and authorizes the calls of the job service. The health service and server reflection
+// don't require authentication.
+type authenticator struct {
+	apiKeys api.APIKeyAuthenticator
+
+	// tokens validates bearer tokens that aren't API keys; nil if JWT authentication is disabled
+	tokens api.TokenValidator
+
+	// anonymous grants full access to the default namespace to all calls
+	anonymous bool
+}
+
+func (a *authenticator) unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (a *authenticator) stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(stream.Context(), info.FullMethod)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authorize authenticates the call and checks the role required by the method. The returned context carries the
+// identity, and the actor and request ID for the audit log.
+func (a *authenticator) authorize(ctx context.Context, method string) (context.Context, error) {
+	role, ok := methodRoles[method]
+	if !ok {
+		// Methods of the job service without a role are never allowed
+		if strings.HasPrefix(method, "/"+schedulerv1.JobService_ServiceDesc.ServiceName+"/") {
+			return nil, errs.ErrForbidden
+		}
+
+		return ctx, nil
+	}
+
+	identity := anonymous
+	if !a.anonymous {
+		var err error
+		identity, err = a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !identity.Role.Includes(role) {
+		return nil, errs.ErrForbidden
+	}
+
+	requestID := metadataValue(ctx, RequestIDMetadata)
+	if !requestIDPattern.MatchString(requestID) {
+		requestID = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, requestID))
+
+	ctx = context.WithValue(ctx, identityContextKey{}, identity)
+	ctx = audit.WithActor(ctx, identity.Actor())
+	ctx = audit.WithRequestID(ctx, requestID)
+
+	return ctx, nil
+}
+
+// authenticate reads the API key from the x-api-key metadata or from the authorization metadata as a bearer token.
+// Other bearer tokens are validated as JWTs, if a token validator is set.
+func (a *authenticator) authenticate(ctx context.Context) (*auth.Identity, error) {
+	key := metadataValue(ctx, APIKeyMetadata)
+	if key == "" {
+		scheme, token, ok := strings.Cut(metadataValue(ctx, AuthorizationMetadata), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, errs.ErrMissingAPIKey
+		}
+
+		token = strings.TrimSpace(token)
+		if a.tokens != nil && !strings.HasPrefix(token, security.APIKeyPrefix) {
+			return a.tokens.Validate(ctx, token)
+		}
+
+		key = token
+	}
+
+	apiKey, err := a.apiKeys.Authenticate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Identity{
+		Subject:   apiKey.Prefix,
+		Namespace: apiKey.Namespace,
+		Role:      apiKey.Role,
+		Method:    auth.MethodAPIKey,
+	}, nil
+}
+
+// metadataValue returns the first value of the incoming metadata with the given key.
+func metadataValue(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// currentIdentity returns the identity the call was authenticated with, if any.
+func currentIdentity(ctx context.Context) (*auth.Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(*auth.Identity)
+	return identity, ok
+}
+
+// hasRole reports whether the call was authenticated with at least the given role.
+func hasRole(ctx context.Context, role model.Role) bool {
+	identity, ok := currentIdentity(ctx)
+	return ok && identity.Role.Includes(role)
+}
+
+// currentNamespace returns the namespace of the authenticated identity. Every call is scoped to this namespace.
+func currentNamespace(ctx context.Context) string {
+	identity, ok := currentIdentity(ctx)
+	if !ok || identity.Namespace == "" {
+		return model.DefaultNamespace
+	}
+
+	return identity.Namespace
+}
+
+// serverStream replaces the context of a stream with the authorized context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
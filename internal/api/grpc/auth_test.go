@@ -0,0 +1,148 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	schedulerv1 "github.com/xBlaz3kx/distributed-scheduler/api/proto/scheduler/v1"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/audit"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/auth"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"google.golang.org/grpc/metadata"
+)
+
+// apiKeys authenticates its keys.
+type apiKeys map[string]*model.APIKey
+
+func (k apiKeys) Authenticate(_ context.Context, key string) (*model.APIKey, error) {
+	apiKey, ok := k[key]
+	if !ok {
+		return nil, errs.ErrInvalidAPIKey
+	}
+
+	return apiKey, nil
+}
+
+// tokens validates a single token.
+type tokens struct{}
+
+func (tokens) Validate(_ context.Context, token string) (*auth.Identity, error) {
+	if token != "valid.jwt" {
+		return nil, errs.ErrInvalidToken
+	}
+
+	return &auth.Identity{Subject: "alice", Namespace: "team-a", Role: model.RoleAdmin, Method: auth.MethodJWT}, nil
+}
+
+func TestAuthenticator_Authorize(t *testing.T) {
+	authenticator := &authenticator{
+		apiKeys: apiKeys{
+			"dsk_viewer": {Prefix: "dsk_viewer", Namespace: "team-a", Role: model.RoleViewer},
+			"dsk_admin":  {Prefix: "dsk_admin", Namespace: "team-b", Role: model.RoleAdmin},
+		},
+		tokens: tokens{},
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		metadata metadata.MD
+		want     error
+
+		wantNamespace string
+		wantActor     string
+	}{
+		{
+			name:          "API key",
+			method:        schedulerv1.JobService_GetJob_FullMethodName,
+			metadata:      metadata.Pairs(APIKeyMetadata, "dsk_viewer"),
+			wantNamespace: "team-a",
+			wantActor:     "api_key:dsk_viewer",
+		},
+		{
+			name:          "API key as bearer token",
+			method:        schedulerv1.JobService_DeleteJob_FullMethodName,
+			metadata:      metadata.Pairs(AuthorizationMetadata, "Bearer dsk_admin"),
+			wantNamespace: "team-b",
+			wantActor:     "api_key:dsk_admin",
+		},
+		{
+			name:          "JWT",
+			method:        schedulerv1.JobService_CreateJob_FullMethodName,
+			metadata:      metadata.Pairs(AuthorizationMetadata, "Bearer valid.jwt"),
+			wantNamespace: "team-a",
+			wantActor:     "jwt:alice",
+		},
+		{
+			name:     "missing credentials",
+			method:   schedulerv1.JobService_GetJob_FullMethodName,
+			metadata: metadata.MD{},
+			want:     errs.ErrMissingAPIKey,
+		},
+		{
+			name:     "invalid API key",
+			method:   schedulerv1.JobService_GetJob_FullMethodName,
+			metadata: metadata.Pairs(APIKeyMetadata, "dsk_unknown"),
+			want:     errs.ErrInvalidAPIKey,
+		},
+		{
+			name:     "invalid token",
+			method:   schedulerv1.JobService_GetJob_FullMethodName,
+			metadata: metadata.Pairs(AuthorizationMetadata, "Bearer invalid.jwt"),
+			want:     errs.ErrInvalidToken,
+		},
+		{
+			name:     "missing role",
+			method:   schedulerv1.JobService_UpdateJob_FullMethodName,
+			metadata: metadata.Pairs(APIKeyMetadata, "dsk_viewer"),
+			want:     errs.ErrForbidden,
+		},
+		{
+			name:     "unknown method of the job service",
+			method:   "/scheduler.v1.JobService/PurgeJobs",
+			metadata: metadata.Pairs(APIKeyMetadata, "dsk_admin"),
+			want:     errs.ErrForbidden,
+		},
+		{
+			name:     "health check",
+			method:   "/grpc.health.v1.Health/Check",
+			metadata: metadata.MD{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.metadata)
+
+			ctx, err := authenticator.authorize(ctx, tt.method)
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantNamespace == "" {
+				_, ok := currentIdentity(ctx)
+				assert.False(t, ok)
+				return
+			}
+
+			assert.Equal(t, tt.wantNamespace, currentNamespace(ctx))
+			assert.Equal(t, tt.wantActor, audit.Actor(ctx))
+			assert.NotEmpty(t, audit.RequestID(ctx))
+		})
+	}
+}
+
+func TestAuthenticator_Anonymous(t *testing.T) {
+	authenticator := &authenticator{apiKeys: apiKeys{}, anonymous: true}
+
+	ctx, err := authenticator.authorize(context.Background(), schedulerv1.JobService_DeleteJob_FullMethodName)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.DefaultNamespace, currentNamespace(ctx))
+	assert.True(t, hasRole(ctx, model.RoleAdmin))
+}
@@ -0,0 +1,343 @@
+package grpc
+
+import (
+	"fmt"
+
+	schedulerv1 "github.com/xBlaz3kx/distributed-scheduler/api/proto/scheduler/v1"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/guregu/null.v4"
+)
+
+// Messages are converted from and to the models of the REST API. Unset strings of messages are null fields of models.
+
+func jobTypeFromProto(jobType schedulerv1.JobType) model.JobType {
+	switch jobType {
+	case schedulerv1.JobType_JOB_TYPE_HTTP:
+		return model.JobTypeHTTP
+	case schedulerv1.JobType_JOB_TYPE_AMQP:
+		return model.JobTypeAMQP
+	default:
+		return ""
+	}
+}
+
+func jobTypeToProto(jobType model.JobType) schedulerv1.JobType {
+	switch jobType {
+	case model.JobTypeHTTP:
+		return schedulerv1.JobType_JOB_TYPE_HTTP
+	case model.JobTypeAMQP:
+		return schedulerv1.JobType_JOB_TYPE_AMQP
+	default:
+		return schedulerv1.JobType_JOB_TYPE_UNSPECIFIED
+	}
+}
+
+func jobStatusFromProto(status schedulerv1.JobStatus) model.JobStatus {
+	switch status {
+	case schedulerv1.JobStatus_JOB_STATUS_RUNNING:
+		return model.JobStatusRunning
+	case schedulerv1.JobStatus_JOB_STATUS_STOPPED:
+		return model.JobStatusStopped
+	default:
+		return ""
+	}
+}
+
+func jobStatusToProto(status model.JobStatus) schedulerv1.JobStatus {
+	switch status {
+	case model.JobStatusRunning:
+		return schedulerv1.JobStatus_JOB_STATUS_RUNNING
+	case model.JobStatusStopped:
+		return schedulerv1.JobStatus_JOB_STATUS_STOPPED
+	default:
+		return schedulerv1.JobStatus_JOB_STATUS_UNSPECIFIED
+	}
+}
+
+func jobCreateFromProto(req *schedulerv1.CreateJobRequest) *model.JobCreate {
+	return &model.JobCreate{
+		Type:         jobTypeFromProto(req.GetType()),
+		ExecuteAt:    timeFromProto(req.GetExecuteAt()),
+		CronSchedule: stringFromProto(req.GetCronSchedule()),
+		HTTPJob:      httpJobFromProto(req.GetHttpJob()),
+		AMQPJob:      amqpJobFromProto(req.GetAmqpJob()),
+		Tags:         req.GetTags(),
+		UniqueKey:    stringFromProto(req.GetUniqueKey()),
+	}
+}
+
+func jobUpdateFromProto(req *schedulerv1.UpdateJobRequest) model.JobUpdate {
+	update := model.JobUpdate{
+		HTTP: httpJobFromProto(req.GetHttpJob()),
+		AMQP: amqpJobFromProto(req.GetAmqpJob()),
+	}
+
+	if req.GetType() != schedulerv1.JobType_JOB_TYPE_UNSPECIFIED {
+		jobType := jobTypeFromProto(req.GetType())
+		update.Type = &jobType
+	}
+
+	if req.GetCronSchedule() != "" {
+		cronSchedule := req.GetCronSchedule()
+		update.CronSchedule = &cronSchedule
+	}
+
+	if req.GetExecuteAt() != nil {
+		executeAt := req.GetExecuteAt().AsTime()
+		update.ExecuteAt = &executeAt
+	}
+
+	if req.GetTags() != nil {
+		tags := append([]string{}, req.GetTags().GetTags()...)
+		update.Tags = &tags
+	}
+
+	return update
+}
+
+func jobToProto(job *model.Job) (*schedulerv1.Job, error) {
+	amqpJob, err := amqpJobToProto(job.AMQPJob)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedulerv1.Job{
+		Id:           job.ID.String(),
+		Namespace:    job.Namespace,
+		Type:         jobTypeToProto(job.Type),
+		Status:       jobStatusToProto(job.Status),
+		Version:      job.Version,
+		UniqueKey:    job.UniqueKey.ValueOrZero(),
+		ExecuteAt:    timeToProto(job.ExecuteAt),
+		CronSchedule: job.CronSchedule.ValueOrZero(),
+		HttpJob:      httpJobToProto(job.HTTPJob),
+		AmqpJob:      amqpJob,
+		Tags:         job.Tags,
+		CreatedAt:    timestamppb.New(job.CreatedAt),
+		UpdatedAt:    timestamppb.New(job.UpdatedAt),
+		NextRun:      timeToProto(job.NextRun),
+	}, nil
+}
+
+func httpJobFromProto(httpJob *schedulerv1.HttpJob) *model.HTTPJob {
+	if httpJob == nil {
+		return nil
+	}
+
+	var validResponseCodes []int
+	for _, code := range httpJob.GetValidResponseCodes() {
+		validResponseCodes = append(validResponseCodes, int(code))
+	}
+
+	auth := httpJob.GetAuth()
+
+	var oauth2 *model.OAuth2ClientCredentials
+	if auth.GetOauth2() != nil {
+		oauth2 = &model.OAuth2ClientCredentials{
+			TokenURL:     auth.GetOauth2().GetTokenUrl(),
+			ClientID:     auth.GetOauth2().GetClientId(),
+			ClientSecret: stringFromProto(auth.GetOauth2().GetClientSecret()),
+			Scopes:       auth.GetOauth2().GetScopes(),
+			Audience:     auth.GetOauth2().GetAudience(),
+		}
+	}
+
+	var tls *model.TLSConfig
+	if httpJob.GetTls() != nil {
+		tls = &model.TLSConfig{
+			ClientCert: stringFromProto(httpJob.GetTls().GetClientCert()),
+			ClientKey:  stringFromProto(httpJob.GetTls().GetClientKey()),
+			CABundle:   httpJob.GetTls().GetCaBundle(),
+			ServerName: httpJob.GetTls().GetServerName(),
+			MinVersion: model.TLSVersion(httpJob.GetTls().GetMinVersion()),
+		}
+	}
+
+	return &model.HTTPJob{
+		URL:                httpJob.GetUrl(),
+		Method:             httpJob.GetMethod(),
+		Headers:            httpJob.GetHeaders(),
+		Body:               stringFromProto(httpJob.GetBody()),
+		ValidResponseCodes: validResponseCodes,
+		Auth: model.Auth{
+			Type:        model.AuthType(auth.GetType()),
+			Username:    stringFromProto(auth.GetUsername()),
+			Password:    stringFromProto(auth.GetPassword()),
+			BearerToken: stringFromProto(auth.GetBearerToken()),
+			HMACSecret:  stringFromProto(auth.GetHmacSecret()),
+			HMACScheme:  model.HMACScheme(auth.GetHmacScheme()),
+			OAuth2:      oauth2,
+			Credential:  stringFromProto(auth.GetCredential()),
+		},
+		TLS: tls,
+	}
+}
+
+func httpJobToProto(httpJob *model.HTTPJob) *schedulerv1.HttpJob {
+	if httpJob == nil {
+		return nil
+	}
+
+	var validResponseCodes []int32
+	for _, code := range httpJob.ValidResponseCodes {
+		validResponseCodes = append(validResponseCodes, int32(code))
+	}
+
+	auth := &schedulerv1.Auth{
+		Type:        string(httpJob.Auth.Type),
+		Username:    httpJob.Auth.Username.ValueOrZero(),
+		Password:    httpJob.Auth.Password.ValueOrZero(),
+		BearerToken: httpJob.Auth.BearerToken.ValueOrZero(),
+		HmacSecret:  httpJob.Auth.HMACSecret.ValueOrZero(),
+		HmacScheme:  string(httpJob.Auth.HMACScheme),
+		Credential:  httpJob.Auth.Credential.ValueOrZero(),
+	}
+
+	if oauth2 := httpJob.Auth.OAuth2; oauth2 != nil {
+		auth.Oauth2 = &schedulerv1.OAuth2ClientCredentials{
+			TokenUrl:     oauth2.TokenURL,
+			ClientId:     oauth2.ClientID,
+			ClientSecret: oauth2.ClientSecret.ValueOrZero(),
+			Scopes:       oauth2.Scopes,
+			Audience:     oauth2.Audience,
+		}
+	}
+
+	var tls *schedulerv1.TlsConfig
+	if httpJob.TLS != nil {
+		tls = &schedulerv1.TlsConfig{
+			ClientCert: httpJob.TLS.ClientCert.ValueOrZero(),
+			ClientKey:  httpJob.TLS.ClientKey.ValueOrZero(),
+			CaBundle:   httpJob.TLS.CABundle,
+			ServerName: httpJob.TLS.ServerName,
+			MinVersion: string(httpJob.TLS.MinVersion),
+		}
+	}
+
+	return &schedulerv1.HttpJob{
+		Url:                httpJob.URL,
+		Method:             httpJob.Method,
+		Headers:            httpJob.Headers,
+		Body:               httpJob.Body.ValueOrZero(),
+		ValidResponseCodes: validResponseCodes,
+		Auth:               auth,
+		Tls:                tls,
+	}
+}
+
+func amqpJobFromProto(amqpJob *schedulerv1.AmqpJob) *model.AMQPJob {
+	if amqpJob == nil {
+		return nil
+	}
+
+	job := &model.AMQPJob{
+		Connection:  amqpJob.GetConnection(),
+		Exchange:    amqpJob.GetExchange(),
+		RoutingKey:  amqpJob.GetRoutingKey(),
+		Body:        amqpJob.GetBody(),
+		ContentType: amqpJob.GetContentType(),
+	}
+
+	if amqpJob.GetHeaders() != nil {
+		job.Headers = amqpJob.GetHeaders().AsMap()
+	}
+
+	if amqpJob.GetBodyEncoding() != "" {
+		bodyEncoding := model.BodyEncoding(amqpJob.GetBodyEncoding())
+		job.BodyEncoding = &bodyEncoding
+	}
+
+	if amqpJob.GetConnectionCredential() != "" {
+		connectionCredential := amqpJob.GetConnectionCredential()
+		job.ConnectionCredential = &connectionCredential
+	}
+
+	return job
+}
+
+func amqpJobToProto(amqpJob *model.AMQPJob) (*schedulerv1.AmqpJob, error) {
+	if amqpJob == nil {
+		return nil, nil
+	}
+
+	var headers *structpb.Struct
+	if amqpJob.Headers != nil {
+		var err error
+		headers, err = structpb.NewStruct(amqpJob.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert AMQP headers: %w", err)
+		}
+	}
+
+	job := &schedulerv1.AmqpJob{
+		Connection:  amqpJob.Connection,
+		Exchange:    amqpJob.Exchange,
+		RoutingKey:  amqpJob.RoutingKey,
+		Headers:     headers,
+		Body:        amqpJob.Body,
+		ContentType: amqpJob.ContentType,
+	}
+
+	if amqpJob.BodyEncoding != nil {
+		job.BodyEncoding = string(*amqpJob.BodyEncoding)
+	}
+
+	if amqpJob.ConnectionCredential != nil {
+		job.ConnectionCredential = *amqpJob.ConnectionCredential
+	}
+
+	return job, nil
+}
+
+func jobExecutionToProto(execution *model.JobExecution) *schedulerv1.JobExecution {
+	if execution == nil {
+		return nil
+	}
+
+	return &schedulerv1.JobExecution{
+		Id:           int64(execution.ID),
+		JobId:        execution.JobID.String(),
+		JobVersion:   execution.JobVersion.ValueOrZero(),
+		ScheduledAt:  timeToProto(execution.ScheduledAt),
+		StartTime:    timestamppb.New(execution.StartTime),
+		EndTime:      timestamppb.New(execution.EndTime),
+		Success:      execution.Success,
+		ErrorMessage: execution.ErrorMessage.ValueOrZero(),
+		InstanceId:   execution.InstanceID.ValueOrZero(),
+	}
+}
+
+func executionEventToProto(event *model.ExecutionEvent) *schedulerv1.ExecutionEvent {
+	return &schedulerv1.ExecutionEvent{
+		Id:          event.ID,
+		Type:        string(event.Type),
+		Namespace:   event.Namespace,
+		JobId:       event.JobID.String(),
+		InstanceId:  event.InstanceID.ValueOrZero(),
+		ScheduledAt: timeToProto(event.ScheduledAt),
+		Execution:   jobExecutionToProto(event.Execution),
+		CreatedAt:   timestamppb.New(event.CreatedAt),
+	}
+}
+
+func stringFromProto(value string) null.String {
+	return null.NewString(value, value != "")
+}
+
+func timeFromProto(timestamp *timestamppb.Timestamp) null.Time {
+	if timestamp == nil {
+		return null.Time{}
+	}
+
+	return null.TimeFrom(timestamp.AsTime())
+}
+
+func timeToProto(value null.Time) *timestamppb.Timestamp {
+	if !value.Valid {
+		return nil
+	}
+
+	return timestamppb.New(value.Time)
+}
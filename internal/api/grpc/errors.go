@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusCodes maps the HTTP status codes of errors, as returned by the REST API, to gRPC status codes.
+var statusCodes = map[int]codes.Code{
+	400: codes.InvalidArgument,
+	401: codes.Unauthenticated,
+	403: codes.PermissionDenied,
+	404: codes.NotFound,
+	409: codes.Aborted,
+	412: codes.FailedPrecondition,
+	422: codes.InvalidArgument,
+	428: codes.FailedPrecondition,
+	429: codes.ResourceExhausted,
+	503: codes.Unavailable,
+}
+
+// toStatus converts the error to a gRPC status error with the code matching the status code of the REST API.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, errs.ErrEventStreamClosed):
+		// Clients resume closed streams with the ID of the last event
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	jobErr := errs.ToCustomJobError(err)
+
+	code, ok := statusCodes[jobErr.Code]
+	if !ok {
+		code = codes.Internal
+	}
+
+	return status.Error(code, jobErr.Error())
+}
@@ -0,0 +1,250 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	schedulerv1 "github.com/xBlaz3kx/distributed-scheduler/api/proto/scheduler/v1"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/events"
+	jobService "github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"gopkg.in/guregu/null.v4"
+)
+
+// defaultLimit is the page size of list calls without a limit, like for the REST API.
+const defaultLimit = 10
+
+// JobServer implements the job service of the gRPC API on top of the job service shared with the REST API.
+type JobServer struct {
+	schedulerv1.UnimplementedJobServiceServer
+
+	service *jobService.Service
+	events  *events.Service
+}
+
+func NewJobServer(service *jobService.Service, events *events.Service) *JobServer {
+	return &JobServer{
+		service: service,
+		events:  events,
+	}
+}
+
+func (j *JobServer) CreateJob(ctx context.Context, req *schedulerv1.CreateJobRequest) (*schedulerv1.CreateJobResponse, error) {
+	job, created, err := j.service.CreateJob(ctx, currentNamespace(ctx), jobCreateFromProto(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response, err := j.jobResponse(job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedulerv1.CreateJobResponse{Job: response, Created: created}, nil
+}
+
+func (j *JobServer) GetJob(ctx context.Context, req *schedulerv1.GetJobRequest) (*schedulerv1.Job, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	job, err := j.service.GetJob(ctx, currentNamespace(ctx), id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return j.jobResponse(job)
+}
+
+func (j *JobServer) UpdateJob(ctx context.Context, req *schedulerv1.UpdateJobRequest) (*schedulerv1.Job, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	version, err := expectedVersion(req.GetExpectedVersion(), req.GetAnyVersion())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	job, err := j.service.UpdateJob(ctx, currentNamespace(ctx), id, version, jobUpdateFromProto(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return j.jobResponse(job)
+}
+
+func (j *JobServer) DeleteJob(ctx context.Context, req *schedulerv1.DeleteJobRequest) (*emptypb.Empty, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	version, err := expectedVersion(req.GetExpectedVersion(), req.GetAnyVersion())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := j.service.DeleteJob(ctx, currentNamespace(ctx), id, version); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (j *JobServer) ListJobs(ctx context.Context, req *schedulerv1.ListJobsRequest) (*schedulerv1.ListJobsResponse, error) {
+	query := model.JobQuery{
+		JobFilter: model.JobFilter{
+			Type:        jobTypeFromProto(req.GetType()),
+			Status:      jobStatusFromProto(req.GetStatus()),
+			Tags:        req.GetTags(),
+			TagMatch:    model.TagMatch(req.GetTagMatch()),
+			URL:         req.GetUrl(),
+			Exchange:    req.GetExchange(),
+			NextRunFrom: timeFromProto(req.GetNextRunFrom()),
+			NextRunTo:   timeFromProto(req.GetNextRunTo()),
+			CreatedFrom: timeFromProto(req.GetCreatedFrom()),
+			CreatedTo:   timeFromProto(req.GetCreatedTo()),
+		},
+		Sort:   model.JobSort(req.GetSort()),
+		Cursor: req.GetCursor(),
+		Limit:  limitOrDefault(req.GetLimit()),
+		Offset: uint64(req.GetOffset()),
+	}
+
+	page, err := j.service.ListJobs(ctx, currentNamespace(ctx), query)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &schedulerv1.ListJobsResponse{
+		NextCursor: page.NextCursor,
+		TotalCount: page.Total,
+	}
+
+	for i := range page.Jobs {
+		job, err := j.jobResponse(&page.Jobs[i])
+		if err != nil {
+			return nil, err
+		}
+
+		response.Jobs = append(response.Jobs, job)
+	}
+
+	return response, nil
+}
+
+func (j *JobServer) ListJobExecutions(ctx context.Context, req *schedulerv1.ListJobExecutionsRequest) (*schedulerv1.ListJobExecutionsResponse, error) {
+	jobID, err := uuid.Parse(req.GetJobId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	executions, err := j.service.GetJobExecutions(ctx, currentNamespace(ctx), jobID, req.GetFailedOnly(), limitOrDefault(req.GetLimit()), uint64(req.GetOffset()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	// Error messages can contain response bodies of the called services
+	operator := hasRole(ctx, model.RoleOperator)
+
+	response := &schedulerv1.ListJobExecutionsResponse{}
+	for _, execution := range executions {
+		if !operator {
+			execution.ErrorMessage = null.String{}
+		}
+
+		response.Executions = append(response.Executions, jobExecutionToProto(execution))
+	}
+
+	return response, nil
+}
+
+// WatchExecutions sends the execution events of the namespace, or of the job, until the client cancels the call or
+// the subscription is closed; the client then watches again with the ID of the last event.
+func (j *JobServer) WatchExecutions(req *schedulerv1.WatchExecutionsRequest, stream schedulerv1.JobService_WatchExecutionsServer) error {
+	ctx := stream.Context()
+	namespace := currentNamespace(ctx)
+
+	if req.GetLastEventId() < 0 {
+		return toStatus(errs.ErrInvalidLastEventID)
+	}
+
+	jobID := uuid.NullUUID{}
+	if req.GetJobId() != "" {
+		id, err := uuid.Parse(req.GetJobId())
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if _, err := j.service.GetJob(ctx, namespace, id); err != nil {
+			return toStatus(err)
+		}
+
+		jobID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	subscription, err := j.events.Subscribe(ctx, namespace, jobID, req.GetLastEventId())
+	if err != nil {
+		return toStatus(err)
+	}
+	defer j.events.Unsubscribe(subscription)
+
+	// Error messages can contain response bodies of the called services
+	operator := hasRole(ctx, model.RoleOperator)
+
+	for {
+		event, err := subscription.Next(ctx)
+		if err != nil {
+			// the client cancelled the call, or the subscription was closed
+			return toStatus(err)
+		}
+
+		if event.Execution != nil && !operator {
+			event.Execution.ErrorMessage = null.String{}
+		}
+
+		if err := stream.Send(executionEventToProto(event)); err != nil {
+			return err
+		}
+	}
+}
+
+// jobResponse converts the job to a message without its credentials.
+func (j *JobServer) jobResponse(job *model.Job) (*schedulerv1.Job, error) {
+	job.RemoveCredentials()
+
+	response, err := jobToProto(job)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return response, nil
+}
+
+// expectedVersion returns the job version the client expects. Changing a job requires either the expected version
+// or any version, so concurrent changes don't overwrite each other, like the If-Match header of the REST API.
+func expectedVersion(version int64, anyVersion bool) (null.Int, error) {
+	switch {
+	case version > 0:
+		return null.IntFrom(version), nil
+	case anyVersion:
+		return null.Int{}, nil
+	default:
+		return null.Int{}, errs.ErrJobPreconditionRequired
+	}
+}
+
+func limitOrDefault(limit uint32) uint64 {
+	if limit == 0 {
+		return defaultLimit
+	}
+
+	return uint64(limit)
+}
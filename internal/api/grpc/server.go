@@ -0,0 +1,130 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/jmoiron/sqlx"
+	schedulerv1 "github.com/xBlaz3kx/distributed-scheduler/api/proto/scheduler/v1"
+	api "github.com/xBlaz3kx/distributed-scheduler/internal/api/http"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/auth"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/egress"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/apikey"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/events"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// shutdownTimeout is how long running calls are given to finish when the server stops, before they are cancelled.
+const shutdownTimeout = 10 * time.Second
+
+// Config configures the gRPC API.
+type Config struct {
+	// Enabled serves the gRPC API next to the REST API
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+
+	// Address the gRPC API listens on, e.g. 0.0.0.0:9090
+	Address string `mapstructure:"address" yaml:"address" json:"address"`
+}
+
+// APIConfig contains all the mandatory systems required by the gRPC API.
+type APIConfig struct {
+	Log    *otelzap.Logger
+	DB     *sqlx.DB
+	Egress *egress.Policy
+	Auth   api.AuthConfig
+
+	// Events streams execution events; it must be running
+	Events *events.Service
+}
+
+// Server serves the gRPC API, the gRPC health service and server reflection.
+type Server struct {
+	server *grpc.Server
+	health *health.Server
+	log    *otelzap.Logger
+}
+
+// NewServer creates a gRPC server with the job service. Calls are authenticated like requests to the REST API.
+func NewServer(cfg APIConfig) (*Server, error) {
+	// Create a new PostgresSQL store
+	store := postgres.New(cfg.DB, cfg.Log)
+
+	authenticator := &authenticator{apiKeys: apikey.NewService(store, cfg.Log)}
+	switch {
+	case cfg.Auth.Enabled && cfg.Auth.JWT.Enabled:
+		jwtValidator, err := auth.NewJWTValidator(cfg.Auth.JWT)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticator.tokens = jwtValidator
+	case !cfg.Auth.Enabled:
+		cfg.Log.Warn("gRPC API authentication is disabled")
+		authenticator.anonymous = true
+	}
+
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(authenticator.unary()),
+		grpc.ChainStreamInterceptor(authenticator.stream()),
+	)
+
+	jobService := job.NewService(store, cfg.Log).WithEgressPolicy(cfg.Egress)
+	schedulerv1.RegisterJobServiceServer(server, NewJobServer(jobService, cfg.Events))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(schedulerv1.JobService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return &Server{
+		server: server,
+		health: healthServer,
+		log:    cfg.Log,
+	}, nil
+}
+
+// Run serves the API on the address until the context is done.
+func (s *Server) Run(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.stop()
+	}()
+
+	return s.server.Serve(listener)
+}
+
+// stop reports the server as not serving and waits for running calls to finish. Calls still running after the
+// shutdown timeout, e.g. execution watches, are cancelled.
+func (s *Server) stop() {
+	s.log.Info("Stopping gRPC server")
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		s.log.Warn("gRPC calls did not finish in time, cancelling them", zap.Duration("timeout", shutdownTimeout))
+		s.server.Stop()
+	}
+}
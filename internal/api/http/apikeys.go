@@ -0,0 +1,123 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	apiKeyService "github.com/xBlaz3kx/distributed-scheduler/internal/service/apikey"
+)
+
+func APIKeysRoutesV1(router gin.IRouter, apiKeysHandler *APIKeys) {
+	apiKeysRouter := router.Group("/v1/api-keys", RequireRole(model.RoleAdmin))
+	{
+		apiKeysRouter.POST("", apiKeysHandler.CreateAPIKey())
+		apiKeysRouter.GET("", apiKeysHandler.ListAPIKeys())
+		apiKeysRouter.DELETE("/:id", apiKeysHandler.RevokeAPIKey())
+	}
+}
+
+func NewAPIKeysHandler(service *apiKeyService.Service) *APIKeys {
+	return &APIKeys{
+		service: service,
+	}
+}
+
+type APIKeys struct {
+	service *apiKeyService.Service
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Issue a new API key. The key is only returned in this response; only its prefix is shown afterwards.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param apiKey body model.APIKeyCreate true "API key"
+// @Success 201 {object} model.CreatedAPIKey
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys [post]
+func (a *APIKeys) CreateAPIKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		create := model.APIKeyCreate{}
+		if err := ctx.BindJSON(&create); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		apiKey, err := a.service.CreateAPIKey(ctx.Request.Context(), currentNamespace(ctx), create)
+		if err != nil {
+			apiKeyErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(apiKeyErr.Code, ErrorResponse{Error: apiKeyErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, apiKey)
+	}
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List API keys with the given limit and offset. Keys are identified by their prefix.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.APIKey
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys [get]
+func (a *APIKeys) ListAPIKeys() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		limit, offset := LimitAndOffset(ctx)
+
+		apiKeys, err := a.service.ListAPIKeys(ctx.Request.Context(), currentNamespace(ctx), limit, offset)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, apiKeys)
+	}
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke the API key with the given ID. Requests using the key are rejected from then on.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys/{id} [delete]
+func (a *APIKeys) RevokeAPIKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := a.service.RevokeAPIKey(ctx.Request.Context(), currentNamespace(ctx), id); err != nil {
+			apiKeyErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(apiKeyErr.Code, ErrorResponse{Error: apiKeyErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	jobService "github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+	"gopkg.in/guregu/null.v4"
+)
+
+func AuditRoutesV1(router gin.IRouter, auditHandler *Audit) {
+	auditRouter := router.Group("/v1/audit", RequireRole(model.RoleOperator))
+	{
+		auditRouter.GET("", auditHandler.ListAudit())
+	}
+}
+
+func NewAuditHandler(service *jobService.Service) *Audit {
+	return &Audit{
+		service: service,
+	}
+}
+
+type Audit struct {
+	service *jobService.Service
+}
+
+// ListAudit godoc
+// @Summary List the audit log
+// @Description List the audit log of job changes in the namespace, newest first, filtered by time range, action and actor
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param from query string false "Entries created at or after, RFC 3339"
+// @Param to query string false "Entries created before, RFC 3339"
+// @Param action query string false "Action: create, update, pause, resume, rollback or delete"
+// @Param actor query string false "Actor, e.g. jwt:alice"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.JobAudit
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /audit [get]
+func (a *Audit) ListAudit() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		from, err := parseTimeQuery(ctx, "from", errors.ErrInvalidAuditTimeRange)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		to, err := parseTimeQuery(ctx, "to", errors.ErrInvalidAuditTimeRange)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		filter := model.AuditFilter{
+			Action: model.AuditAction(ctx.Query("action")),
+			Actor:  ctx.Query("actor"),
+			From:   from,
+			To:     to,
+		}
+
+		limit, offset := LimitAndOffset(ctx)
+
+		entries, err := a.service.ListAudit(ctx.Request.Context(), currentNamespace(ctx), filter, limit, offset)
+		if err != nil {
+			auditErr := errors.ToCustomJobError(err)
+			ctx.JSON(auditErr.Code, ErrorResponse{Error: auditErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, entries)
+	}
+}
+
+// parseTimeQuery parses the RFC 3339 timestamp in the query parameter, if it is set. If it is invalid, invalidErr is
+// returned.
+func parseTimeQuery(ctx *gin.Context, key string, invalidErr error) (null.Time, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return null.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return null.Time{}, invalidErr
+	}
+
+	return null.TimeFrom(parsed), nil
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/audit"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/auth"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+)
+
+// identityContextKey is the gin context key of the authenticated identity.
+const identityContextKey = "identity"
+
+// APIKeyAuthenticator authenticates API keys.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*model.APIKey, error)
+}
+
+// TokenValidator validates bearer tokens.
+type TokenValidator interface {
+	Validate(ctx context.Context, token string) (*auth.Identity, error)
+}
+
+// Authenticate rejects requests without valid credentials. API keys are read from the X-API-Key header or from the
+// Authorization header as a bearer token. Other bearer tokens are validated as JWTs, if a token validator is set.
+func Authenticate(apiKeys APIKeyAuthenticator, tokens TokenValidator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity, err := authenticate(ctx, apiKeys, tokens)
+		if err != nil {
+			authErr := errors.ToCustomJobError(err)
+			ctx.AbortWithStatusJSON(authErr.Code, ErrorResponse{Error: authErr.Error()})
+			return
+		}
+
+		setIdentity(ctx, identity)
+		ctx.Next()
+	}
+}
+
+func authenticate(ctx *gin.Context, apiKeys APIKeyAuthenticator, tokens TokenValidator) (*auth.Identity, error) {
+	key := ctx.GetHeader("X-API-Key")
+	if key == "" {
+		scheme, token, ok := strings.Cut(ctx.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, errors.ErrMissingAPIKey
+		}
+
+		token = strings.TrimSpace(token)
+		if tokens != nil && !strings.HasPrefix(token, security.APIKeyPrefix) {
+			return tokens.Validate(ctx.Request.Context(), token)
+		}
+
+		key = token
+	}
+
+	apiKey, err := apiKeys.Authenticate(ctx.Request.Context(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Identity{
+		Subject:   apiKey.Prefix,
+		Namespace: apiKey.Namespace,
+		Role:      apiKey.Role,
+		Method:    auth.MethodAPIKey,
+	}, nil
+}
+
+// Anonymous grants full access to the default namespace to all requests. It is used when authentication is disabled.
+func Anonymous() gin.HandlerFunc {
+	identity := &auth.Identity{Subject: "anonymous", Namespace: model.DefaultNamespace, Role: model.RoleAdmin, Method: auth.MethodNone}
+
+	return func(ctx *gin.Context) {
+		setIdentity(ctx, identity)
+		ctx.Next()
+	}
+}
+
+// setIdentity stores the identity in the gin context, and its actor in the request context for the audit log.
+func setIdentity(ctx *gin.Context, identity *auth.Identity) {
+	ctx.Set(identityContextKey, identity)
+	ctx.Request = ctx.Request.WithContext(audit.WithActor(ctx.Request.Context(), identity.Actor()))
+}
+
+// RequireRole rejects requests of identities without the given role.
+func RequireRole(role model.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity, ok := CurrentIdentity(ctx)
+		if !ok || !identity.Role.Includes(role) {
+			authErr := errors.ToCustomJobError(errors.ErrForbidden)
+			ctx.AbortWithStatusJSON(authErr.Code, ErrorResponse{Error: authErr.Error()})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// CurrentIdentity returns the identity the request was authenticated with, if any.
+func CurrentIdentity(ctx *gin.Context) (*auth.Identity, bool) {
+	value, ok := ctx.Get(identityContextKey)
+	if !ok {
+		return nil, false
+	}
+
+	identity, ok := value.(*auth.Identity)
+	return identity, ok
+}
+
+// hasRole reports whether the request was authenticated with at least the given role.
+func hasRole(ctx *gin.Context, role model.Role) bool {
+	identity, ok := CurrentIdentity(ctx)
+	return ok && identity.Role.Includes(role)
+}
+
+// currentNamespace returns the namespace of the authenticated identity. Every request is scoped to this namespace.
+func currentNamespace(ctx *gin.Context) string {
+	identity, ok := CurrentIdentity(ctx)
+	if !ok || identity.Namespace == "" {
+		return model.DefaultNamespace
+	}
+
+	return identity.Namespace
+}
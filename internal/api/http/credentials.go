@@ -0,0 +1,186 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	credentialService "github.com/xBlaz3kx/distributed-scheduler/internal/service/credential"
+)
+
+func CredentialsRoutesV1(router gin.IRouter, credentialsHandler *Credentials) {
+	credentialsRouter := router.Group("/v1/credentials")
+	{
+		credentialsRouter.POST("", RequireRole(model.RoleAdmin), credentialsHandler.CreateCredential())
+		credentialsRouter.GET("/:name", RequireRole(model.RoleOperator), credentialsHandler.GetCredential())
+		credentialsRouter.PUT("/:name", RequireRole(model.RoleAdmin), credentialsHandler.UpdateCredential())
+		credentialsRouter.DELETE("/:name", RequireRole(model.RoleAdmin), credentialsHandler.DeleteCredential())
+		credentialsRouter.GET("", RequireRole(model.RoleOperator), credentialsHandler.ListCredentials())
+	}
+}
+
+func NewCredentialsHandler(service *credentialService.Service) *Credentials {
+	return &Credentials{
+		service: service,
+	}
+}
+
+type Credentials struct {
+	service *credentialService.Service
+}
+
+// CreateCredential godoc
+// @Summary Create a credential
+// @Description Create a named credential that jobs can reference. Secrets are stored encrypted and never returned.
+// @Tags credentials
+// @Accept json
+// @Produce json
+// @Param credential body model.Credential true "Credential"
+// @Success 201 {object} model.Credential
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /credentials [post]
+func (c *Credentials) CreateCredential() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		credential := &model.Credential{}
+		if err := ctx.BindJSON(credential); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		credential, err := c.service.CreateCredential(ctx.Request.Context(), currentNamespace(ctx), credential)
+		if err != nil {
+			credentialErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(credentialErr.Code, ErrorResponse{Error: credentialErr.Error()})
+			return
+		}
+
+		credential.RemoveSecrets()
+
+		ctx.JSON(http.StatusCreated, credential)
+	}
+}
+
+// GetCredential godoc
+// @Summary Get a credential
+// @Description Get a credential with the given name, without its secrets
+// @Tags credentials
+// @Accept json
+// @Produce json
+// @Param name path string true "Credential name"
+// @Success 200 {object} model.Credential
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /credentials/{name} [get]
+func (c *Credentials) GetCredential() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		credential, err := c.service.GetCredential(ctx.Request.Context(), currentNamespace(ctx), ctx.Param("name"))
+		if err != nil {
+			credentialErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(credentialErr.Code, ErrorResponse{Error: credentialErr.Error()})
+			return
+		}
+
+		credential.RemoveSecrets()
+
+		ctx.JSON(http.StatusOK, credential)
+	}
+}
+
+// UpdateCredential godoc
+// @Summary Update a credential
+// @Description Replace the secrets of a credential. Jobs referencing it use the new secrets on their next execution.
+// @Tags credentials
+// @Accept json
+// @Produce json
+// @Param name path string true "Credential name"
+// @Param credential body model.CredentialUpdate true "Credential Update"
+// @Success 200 {object} model.Credential
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /credentials/{name} [put]
+func (c *Credentials) UpdateCredential() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		update := model.CredentialUpdate{}
+		if err := ctx.BindJSON(&update); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		credential, err := c.service.UpdateCredential(ctx.Request.Context(), currentNamespace(ctx), ctx.Param("name"), update)
+		if err != nil {
+			credentialErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(credentialErr.Code, ErrorResponse{Error: credentialErr.Error()})
+			return
+		}
+
+		credential.RemoveSecrets()
+
+		ctx.JSON(http.StatusOK, credential)
+	}
+}
+
+// DeleteCredential godoc
+// @Summary Delete a credential
+// @Description Delete a credential with the given name. Credentials referenced by jobs cannot be deleted.
+// @Tags credentials
+// @Accept json
+// @Produce json
+// @Param name path string true "Credential name"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /credentials/{name} [delete]
+func (c *Credentials) DeleteCredential() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		if err := c.service.DeleteCredential(ctx.Request.Context(), currentNamespace(ctx), ctx.Param("name")); err != nil {
+			credentialErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(credentialErr.Code, ErrorResponse{Error: credentialErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// ListCredentials godoc
+// @Summary List credentials
+// @Description List credentials with the given limit and offset, without their secrets
+// @Tags credentials
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.Credential
+// @Failure 500 {object} ErrorResponse
+// @Router /credentials [get]
+func (c *Credentials) ListCredentials() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		limit, offset := LimitAndOffset(ctx)
+
+		credentials, err := c.service.ListCredentials(ctx.Request.Context(), currentNamespace(ctx), limit, offset)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		for i := range credentials {
+			credentials[i].RemoveSecrets()
+		}
+
+		ctx.JSON(http.StatusOK, credentials)
+	}
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// setJobETag returns the version of the job as its ETag, so clients can send it back in the If-Match header.
+func setJobETag(ctx *gin.Context, job *model.Job) {
+	ctx.Header("ETag", job.ETag())
+}
+
+// ifMatchVersion returns the job version the client expects from the If-Match header. The header is required to
+// change a job, so concurrent changes don't overwrite each other; "*" matches any version.
+func ifMatchVersion(ctx *gin.Context) (null.Int, error) {
+	ifMatch := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	switch ifMatch {
+	case "":
+		return null.Int{}, errors.ErrJobPreconditionRequired
+	case "*":
+		return null.Int{}, nil
+	}
+
+	// Weak ETags never match, as If-Match uses the strong comparison
+	tag, err := strconv.Unquote(ifMatch)
+	if err != nil {
+		return null.Int{}, errors.ErrJobVersionMismatch
+	}
+
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return null.Int{}, errors.ErrJobVersionMismatch
+	}
+
+	return null.IntFrom(version), nil
+}
@@ -0,0 +1,317 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/events"
+	jobService "github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+	"gopkg.in/guregu/null.v4"
+)
+
+func ExecutionsRoutesV1(router gin.IRouter, executionsHandler *Executions) {
+	executionsRouter := router.Group("/v1/executions", RequireRole(model.RoleViewer))
+	{
+		executionsRouter.GET("", executionsHandler.SearchExecutions())
+		executionsRouter.GET("/stream", executionsHandler.StreamExecutions())
+	}
+
+	router.GET("/v1/jobs/:id/executions/stream", RequireRole(model.RoleViewer), executionsHandler.StreamJobExecutions())
+}
+
+func NewExecutionsHandler(service *jobService.Service, events *events.Service) *Executions {
+	return &Executions{
+		service: service,
+		events:  events,
+	}
+}
+
+type Executions struct {
+	service *jobService.Service
+	events  *events.Service
+}
+
+// SearchExecutions godoc
+// @Summary Search job executions
+// @Description Search the executions of all jobs in the namespace, the most recent first. The total number of matching executions is returned in the X-Total-Count header and the cursor of the next page in the X-Next-Cursor header. Error messages are only returned to, and can only be searched by, operators and admins.
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param status query string false "Execution status, SUCCESSFUL or FAILED"
+// @Param from query string false "Executions started at or after, RFC 3339"
+// @Param to query string false "Executions started before, RFC 3339"
+// @Param since query string false "Executions started within the duration, e.g. 15m; can't be combined with from"
+// @Param jobType query string false "Job type"
+// @Param tags query []string false "Job tags" collectionFormat(multi)
+// @Param tagMatch query string false "Match all (default) or any of the tags"
+// @Param instanceId query string false "Runner instance ID"
+// @Param error query string false "Substring of the error message, case-insensitive"
+// @Param cursor query string false "Cursor of the next page, from the X-Next-Cursor header"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.JobExecution
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /executions [get]
+func (e *Executions) SearchExecutions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		query, err := executionQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// Error messages can contain response bodies of the called services
+		operator := hasRole(ctx, model.RoleOperator)
+		if query.Error != "" && !operator {
+			forbiddenErr := errors.ToCustomJobError(errors.ErrForbidden)
+			ctx.JSON(forbiddenErr.Code, ErrorResponse{Error: forbiddenErr.Error()})
+			return
+		}
+
+		page, err := e.service.SearchJobExecutions(ctx.Request.Context(), currentNamespace(ctx), query)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		if !operator {
+			for i := range page.Executions {
+				page.Executions[i].ErrorMessage = null.String{}
+			}
+		}
+
+		ctx.Header(TotalCountHeader, strconv.FormatInt(page.Total, 10))
+		if page.NextCursor != "" {
+			ctx.Header(NextCursorHeader, page.NextCursor)
+		}
+
+		ctx.JSON(http.StatusOK, page.Executions)
+	}
+}
+
+// executionQuery parses the filters and pagination of an execution search from the query parameters.
+func executionQuery(ctx *gin.Context) (model.ExecutionQuery, error) {
+	limit, offset := LimitAndOffset(ctx)
+
+	query := model.ExecutionQuery{
+		ExecutionFilter: model.ExecutionFilter{
+			Status:     model.JobExecutionStatus(ctx.Query("status")),
+			JobType:    model.JobType(ctx.Query("jobType")),
+			Tags:       ctx.QueryArray("tags"),
+			TagMatch:   model.TagMatch(ctx.Query("tagMatch")),
+			InstanceID: ctx.Query("instanceId"),
+			Error:      ctx.Query("error"),
+		},
+		Cursor: ctx.Query("cursor"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	var err error
+	if query.From, err = parseTimeQuery(ctx, "from", errors.ErrInvalidExecutionTimeRange); err != nil {
+		return model.ExecutionQuery{}, err
+	}
+
+	if query.To, err = parseTimeQuery(ctx, "to", errors.ErrInvalidExecutionTimeRange); err != nil {
+		return model.ExecutionQuery{}, err
+	}
+
+	if since := ctx.Query("since"); since != "" {
+		duration, err := time.ParseDuration(since)
+		if err != nil || duration <= 0 || query.From.Valid {
+			return model.ExecutionQuery{}, errors.ErrInvalidExecutionTimeRange
+		}
+
+		query.From = null.TimeFrom(time.Now().Add(-duration))
+	}
+
+	return query, nil
+}
+
+const (
+	// LastEventIDHeader holds the ID of the last event a client received, to resume a stream after it.
+	LastEventIDHeader = "Last-Event-ID"
+
+	// maxStreamDuration is how long a stream is kept open before the client is asked to reconnect
+	maxStreamDuration = 5 * time.Minute
+
+	// streamHeartbeatInterval is how often a comment is sent on an idle stream, so proxies keep it open
+	streamHeartbeatInterval = 15 * time.Second
+
+	// bufferedStreamDelay is how long a buffered stream waits for more events after the first one
+	bufferedStreamDelay = 100 * time.Millisecond
+)
+
+// StreamExecutions godoc
+// @Summary Stream execution events
+// @Description Stream the execution.started and execution.finished events of all jobs in the namespace as server-sent events. A stream is resumed after the last received event with the Last-Event-ID header or the lastEventId query parameter. Error messages are only sent to operators and admins.
+// @Tags executions
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "ID of the last received event"
+// @Param lastEventId query int false "ID of the last received event, for clients that can't set headers"
+// @Success 200 {object} model.ExecutionEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /executions/stream [get]
+func (e *Executions) StreamExecutions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		e.stream(ctx, uuid.NullUUID{})
+	}
+}
+
+// StreamJobExecutions godoc
+// @Summary Stream execution events of a job
+// @Description Stream the execution.started and execution.finished events of a job as server-sent events. A stream is resumed after the last received event with the Last-Event-ID header or the lastEventId query parameter. Error messages are only sent to operators and admins.
+// @Tags executions
+// @Produce text/event-stream
+// @Param id path string true "Job ID"
+// @Param Last-Event-ID header int false "ID of the last received event"
+// @Param lastEventId query int false "ID of the last received event, for clients that can't set headers"
+// @Success 200 {object} model.ExecutionEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /jobs/{id}/executions/stream [get]
+func (e *Executions) StreamJobExecutions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		jobID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if _, err := e.service.GetJob(ctx.Request.Context(), currentNamespace(ctx), jobID); err != nil {
+			jobErr := errors.ToCustomJobError(err)
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		e.stream(ctx, uuid.NullUUID{UUID: jobID, Valid: true})
+	}
+}
+
+// stream writes the events of the namespace, or of the job, as server-sent events until the client disconnects or
+// the stream expires; the client then reconnects with the ID of the last event.
+func (e *Executions) stream(ctx *gin.Context, jobID uuid.NullUUID) {
+	lastEventID, err := lastEventIDQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	subscription, err := e.events.Subscribe(ctx.Request.Context(), currentNamespace(ctx), jobID, lastEventID)
+	if err != nil {
+		streamErr := errors.ToCustomJobError(err)
+		ctx.JSON(streamErr.Code, ErrorResponse{Error: streamErr.Error()})
+		return
+	}
+	defer e.events.Unsubscribe(subscription)
+
+	// Requests with a deadline are handled by a timeout middleware that buffers the response until the handler
+	// returns, and must not be flushed before. The stream is then ended right after events were written and before
+	// the deadline, and the client reconnects immediately.
+	buffered := false
+	end := time.Now().Add(maxStreamDuration)
+	if deadline, ok := ctx.Request.Context().Deadline(); ok {
+		buffered = true
+		end = deadline.Add(-time.Second)
+	}
+
+	streamCtx, cancel := context.WithDeadline(ctx.Request.Context(), end)
+	defer cancel()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	flush := func() {
+		if !buffered {
+			ctx.Writer.Flush()
+		}
+	}
+
+	retry := 3 * time.Second
+	if buffered {
+		retry = 0
+	}
+	_, _ = fmt.Fprintf(ctx.Writer, "retry: %d\n\n", retry.Milliseconds())
+	flush()
+
+	operator := hasRole(ctx, model.RoleOperator)
+	written := false
+
+	for {
+		wait := streamHeartbeatInterval
+		if buffered && written {
+			wait = bufferedStreamDelay
+		}
+
+		nextCtx, cancelNext := context.WithTimeout(streamCtx, wait)
+		event, err := subscription.Next(nextCtx)
+		cancelNext()
+
+		switch {
+		case err == nil:
+		case stderrors.Is(err, context.DeadlineExceeded) && streamCtx.Err() == nil:
+			if written && buffered {
+				return
+			}
+
+			_, _ = fmt.Fprint(ctx.Writer, ": heartbeat\n\n")
+			flush()
+			continue
+		default:
+			// the client disconnected, the stream expired or the subscription was closed
+			return
+		}
+
+		// Error messages can contain response bodies of the called services
+		if event.Execution != nil && !operator {
+			event.Execution.ErrorMessage = null.String{}
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+
+		_, _ = fmt.Fprintf(ctx.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		flush()
+		written = true
+	}
+}
+
+// lastEventIDQuery returns the ID of the last event the client received, from the Last-Event-ID header or the
+// lastEventId query parameter, or 0 if the client didn't receive events yet.
+func lastEventIDQuery(ctx *gin.Context) (int64, error) {
+	value := ctx.GetHeader(LastEventIDHeader)
+	if value == "" {
+		value = ctx.Query("lastEventId")
+	}
+
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, errors.ErrInvalidLastEventID
+	}
+
+	return id, nil
+}
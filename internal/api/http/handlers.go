@@ -1,10 +1,20 @@
 package http
 
 import (
+	"time"
+
 	"github.com/GLCharge/otelzap"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/auth"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/egress"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/apikey"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/credential"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/events"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/idempotency"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/webhook"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
 )
 
@@ -13,26 +23,132 @@ type APIMuxConfig struct {
 	Log     *otelzap.Logger
 	DB      *sqlx.DB
 	OpenApi OpenApiConfig
+	Egress  *egress.Policy
+	Auth    AuthConfig
+
+	// Idempotency keeps the responses of requests with an Idempotency-Key header; it must be running to delete
+	// expired keys
+	Idempotency *idempotency.Service
+
+	// Encryptor encrypts the stored secrets; the one set with postgres.SetEncryptor is used if nil
+	Encryptor security.Encryptor
+
+	// Events streams execution events; it must be running
+	Events *events.Service
+
+	// Webhooks manages webhook subscriptions; it must be running to deliver events
+	Webhooks *webhook.Service
+}
+
+// IdempotencyConfig configures the handling of requests with an Idempotency-Key header.
+type IdempotencyConfig struct {
+	// KeyTTL is how long the responses of requests with an Idempotency-Key header are kept for retries
+	KeyTTL time.Duration `mapstructure:"keyTtl" yaml:"keyTtl" json:"keyTtl"`
+}
+
+// EventsConfig configures the streams of execution events.
+type EventsConfig struct {
+	// Retention is how long execution events are kept, so interrupted streams can be resumed
+	Retention time.Duration `mapstructure:"retention" yaml:"retention" json:"retention"`
+}
+
+// WebhooksConfig configures the delivery of webhook events.
+type WebhooksConfig struct {
+	// Retention is how long webhook events are kept in the delivery log once they were delivered
+	Retention time.Duration `mapstructure:"retention" yaml:"retention" json:"retention"`
+}
+
+// AuthConfig configures the authentication of the API.
+type AuthConfig struct {
+	// Enabled requires a valid API key or JWT for all /v1 routes
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+
+	// JWT enables authentication with JWT bearer tokens issued by an identity provider
+	JWT auth.JWTConfig `mapstructure:"jwt" yaml:"jwt" json:"jwt"`
 }
 
 // Api constructs a http.Handler with all application routes defined.
-func Api(router *gin.Engine, cfg APIMuxConfig) {
+func Api(router *gin.Engine, cfg APIMuxConfig) error {
 	// ==================
 	// OpenAPI (will only mount if enabled)
 	OpenApiRoute(cfg.OpenApi, router)
 
+	// Create a new PostgresSQL store
+	var storeOpts []postgres.Option
+	if cfg.Encryptor != nil {
+		storeOpts = append(storeOpts, postgres.WithEncryptor(cfg.Encryptor))
+	}
+
+	store := postgres.New(cfg.DB, cfg.Log, storeOpts...)
+
+	// Assign an ID to every request, recorded in the audit log
+	router.Use(RequestID())
+
 	// ==================
-	// Jobs
+	// Authentication
+
+	apiKeyService := apikey.NewService(store, cfg.Log)
+
+	var v1 *gin.RouterGroup
+	switch {
+	case cfg.Auth.Enabled && cfg.Auth.JWT.Enabled:
+		jwtValidator, err := auth.NewJWTValidator(cfg.Auth.JWT)
+		if err != nil {
+			return err
+		}
 
-	// Create a new PostgresSQL job store
-	jobStore := postgres.New(cfg.DB, cfg.Log)
+		v1 = router.Group("", Authenticate(apiKeyService, jwtValidator))
+	case cfg.Auth.Enabled:
+		v1 = router.Group("", Authenticate(apiKeyService, nil))
+	default:
+		cfg.Log.Warn("API authentication is disabled")
+		v1 = router.Group("", Anonymous())
+	}
+
+	// ==================
+	// Jobs
 
 	// Create a new job service with the job store and logger
-	jobService := job.NewService(jobStore, cfg.Log)
+	jobService := job.NewService(store, cfg.Log).WithEgressPolicy(cfg.Egress)
 
 	// Create a new jobs handler with the job service
 	jobsHandler := NewJobsHandler(jobService)
 
-	// Define a group of routes for the jobs endpoint
-	JobsRoutesV1(router, jobsHandler)
+	// Define a group of routes for the jobs endpoint; responses of job creation requests with an Idempotency-Key
+	// header are kept for retries
+	JobsRoutesV1(v1, jobsHandler, cfg.Idempotency)
+
+	// ==================
+	// Audit log
+
+	AuditRoutesV1(v1, NewAuditHandler(jobService))
+
+	// ==================
+	// Executions across all jobs
+
+	ExecutionsRoutesV1(v1, NewExecutionsHandler(jobService, cfg.Events))
+
+	// ==================
+	// Execution statistics
+
+	StatsRoutesV1(v1, NewStatsHandler(jobService))
+
+	// ==================
+	// Credentials
+
+	credentialsHandler := NewCredentialsHandler(credential.NewService(store, cfg.Log))
+
+	CredentialsRoutesV1(v1, credentialsHandler)
+
+	// ==================
+	// Webhook subscriptions
+
+	SubscriptionsRoutesV1(v1, NewSubscriptionsHandler(cfg.Webhooks))
+
+	// ==================
+	// API keys
+
+	APIKeysRoutesV1(v1, NewAPIKeysHandler(apiKeyService))
+
+	return nil
 }
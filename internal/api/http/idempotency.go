@@ -0,0 +1,107 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/idempotency"
+)
+
+const (
+	// IdempotencyKeyHeader makes a request safe to retry: retries with the same key return the original response.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses replayed for a retried request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// responseRecorder keeps a copy of the response body, so it can be stored for retries.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Idempotent processes requests with an Idempotency-Key header at most once per key and namespace. The response is
+// stored with its ETag, and retries of the request with the same key get the stored response with the
+// Idempotent-Replayed header until the key expires. Responses of failed requests that may succeed when retried (5xx and 429) are not stored.
+// Requests without the header are processed as usual.
+func Idempotent(service *idempotency.Service) gin.HandlerFunc {
+	return idempotent(service, func(ctx *gin.Context) { ctx.Next() })
+}
+
+// idempotent wraps the handler in the Idempotent middleware, for handlers dispatched outside the gin handler chain.
+func idempotent(service *idempotency.Service, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			handler(ctx)
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		namespace := currentNamespace(ctx)
+		requestHash := hashRequest(ctx.Request.Method, ctx.Request.URL.Path, body)
+
+		stored, err := service.Reserve(ctx.Request.Context(), namespace, key, requestHash)
+		if err != nil {
+			keyErr := errors.ToCustomJobError(err)
+			ctx.AbortWithStatusJSON(keyErr.Code, ErrorResponse{Error: keyErr.Error()})
+			return
+		}
+
+		if stored != nil {
+			ctx.Header(IdempotentReplayedHeader, "true")
+			if stored.ETag.Valid {
+				ctx.Header("ETag", stored.ETag.String)
+			}
+			ctx.Data(int(stored.StatusCode.Int64), gin.MIMEJSON+"; charset=utf-8", stored.Response)
+			ctx.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		handler(ctx)
+
+		// The request is over, but the key must be completed or released even if the client went away
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			service.Release(storeCtx, namespace, key)
+			return
+		}
+
+		service.Complete(storeCtx, namespace, key, requestHash, status, recorder.Header().Get("ETag"), recorder.body.Bytes())
+	}
+}
+
+// hashRequest identifies a request by its method, path and body.
+func hashRequest(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -8,19 +8,29 @@ import (
 	"github.com/google/uuid"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
 	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/idempotency"
 	jobService "github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+	"gopkg.in/guregu/null.v4"
 )
 
-func JobsRoutesV1(router *gin.Engine, jobsHandler *Jobs) {
+func JobsRoutesV1(router gin.IRouter, jobsHandler *Jobs, idempotencyService *idempotency.Service) {
 	jobsRouter := router.Group("/v1/jobs")
 	{
-		jobsRouter.POST("", jobsHandler.CreateJob())
-		jobsRouter.GET("/:id", jobsHandler.GetJob())
-		jobsRouter.PUT("/:id", jobsHandler.UpdateJob())
-		jobsRouter.DELETE("/:id", jobsHandler.DeleteJob())
-		jobsRouter.GET("", jobsHandler.ListJobs())
-		jobsRouter.GET("/:id/executions", jobsHandler.GetJobExecutions())
+		jobsRouter.POST("", RequireRole(model.RoleOperator), Idempotent(idempotencyService), jobsHandler.CreateJob())
+		jobsRouter.GET("/:id", RequireRole(model.RoleViewer), jobsHandler.GetJob())
+		jobsRouter.PUT("/:id", RequireRole(model.RoleOperator), jobsHandler.UpdateJob())
+		jobsRouter.PATCH("/:id", RequireRole(model.RoleOperator), jobsHandler.PatchJob())
+		jobsRouter.DELETE("/:id", RequireRole(model.RoleAdmin), jobsHandler.DeleteJob())
+		jobsRouter.GET("", RequireRole(model.RoleViewer), jobsHandler.ListJobs())
+		jobsRouter.GET("/:id/executions", RequireRole(model.RoleViewer), jobsHandler.GetJobExecutions())
+		jobsRouter.GET("/:id/versions", RequireRole(model.RoleViewer), jobsHandler.GetJobVersions())
+		jobsRouter.POST("/:id/rollback", RequireRole(model.RoleOperator), jobsHandler.RollbackJob())
+		jobsRouter.GET("/:id/audit", RequireRole(model.RoleOperator), jobsHandler.GetJobAudit())
+		jobsRouter.GET("/:id/stats", RequireRole(model.RoleViewer), jobsHandler.GetJobStats())
 	}
+
+	// Custom methods, e.g. POST /v1/jobs:batchCreate; the route can't be relative to the group, as it has no separator
+	router.POST("/v1/jobs:method", customMethods(jobsCustomMethods(jobsHandler, idempotencyService)))
 }
 
 func NewJobsHandler(service *jobService.Service) *Jobs {
@@ -39,13 +49,19 @@ type ErrorResponse struct {
 
 // CreateJob godoc
 // @Summary Create a job
-// @Description Create a job with the given job create request
+// @Description Create a job with the given job create request. If the namespace already has a job with the request's unique key, the existing job is returned.
+// @Description Retries of a request with the same Idempotency-Key header return the response of the original request.
 // @Tags jobs
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Key making the request safe to retry"
 // @Param job body model.JobCreate true "Job Create"
+// @Success 200 {object} model.Job "Existing job with the unique key"
 // @Success 201 {object} model.Job
+// @Header 201 {string} ETag "Version of the job"
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Request with the same Idempotency-Key in progress"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key used for a different request"
 // @Failure 500 {object} ErrorResponse
 // @Router /jobs [post]
 func (j *Jobs) CreateJob() gin.HandlerFunc {
@@ -57,7 +73,7 @@ func (j *Jobs) CreateJob() gin.HandlerFunc {
 			return
 		}
 
-		job, err := j.service.CreateJob(ctx.Request.Context(), create)
+		job, created, err := j.service.CreateJob(ctx.Request.Context(), currentNamespace(ctx), create)
 		if err != nil {
 			jobErr := errors.ToCustomJobError(err)
 
@@ -67,20 +83,31 @@ func (j *Jobs) CreateJob() gin.HandlerFunc {
 
 		job.RemoveCredentials()
 
+		setJobETag(ctx, job)
+		if !created {
+			ctx.JSON(http.StatusOK, job)
+			return
+		}
+
 		ctx.JSON(http.StatusCreated, job)
 	}
 }
 
 // UpdateJob godoc
 // @Summary Update a job
-// @Description Update a job with the given job update request
+// @Description Update a job with the given job update request. The If-Match header must contain the ETag of the job, or "*".
 // @Tags jobs
 // @Accept json
 // @Produce json
 // @Param id path string true "Job ID"
+// @Param If-Match header string true "ETag of the job"
 // @Param job body model.JobUpdate true "Job Update"
 // @Success 200 {object} model.Job
+// @Header 200 {string} ETag "Version of the job"
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /jobs/{id} [put]
 func (j *Jobs) UpdateJob() gin.HandlerFunc {
@@ -92,13 +119,21 @@ func (j *Jobs) UpdateJob() gin.HandlerFunc {
 			return
 		}
 
+		expectedVersion, err := ifMatchVersion(ctx)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
 		update := model.JobUpdate{}
 		if err := ctx.BindJSON(&update); err != nil {
 			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		job, err := j.service.UpdateJob(ctx.Request.Context(), id, update)
+		job, err := j.service.UpdateJob(ctx.Request.Context(), currentNamespace(ctx), id, expectedVersion, update)
 		if err != nil {
 			jobErr := errors.ToCustomJobError(err)
 
@@ -108,11 +143,73 @@ func (j *Jobs) UpdateJob() gin.HandlerFunc {
 
 		job.RemoveCredentials()
 
+		setJobETag(ctx, job)
 		ctx.JSON(http.StatusOK, job)
 
 	}
 }
 
+// PatchJob godoc
+// @Summary Patch a job
+// @Description Apply a JSON merge patch (RFC 7386) to the job with the given ID, e.g. {"status": "STOPPED"} to pause it. Fields omitted from the patch, including secrets, keep their values; null removes a field. The ID, namespace, version and timestamps can't be patched. The If-Match header must contain the ETag of the job, or "*".
+// @Tags jobs
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param If-Match header string true "ETag of the job"
+// @Param patch body object true "JSON merge patch of the job"
+// @Success 200 {object} model.Job
+// @Header 200 {string} ETag "Version of the job"
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id} [patch]
+func (j *Jobs) PatchJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if contentType := ctx.ContentType(); contentType != "application/merge-patch+json" && contentType != "application/json" {
+			ctx.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "content type must be application/merge-patch+json"})
+			return
+		}
+
+		expectedVersion, err := ifMatchVersion(ctx)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		patch, err := ctx.GetRawData()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		job, err := j.service.PatchJob(ctx.Request.Context(), currentNamespace(ctx), id, expectedVersion, patch)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		job.RemoveCredentials()
+
+		setJobETag(ctx, job)
+		ctx.JSON(http.StatusOK, job)
+	}
+}
+
 // GetJob godoc
 // @Summary Get a job
 // @Description Get a job with the given job ID
@@ -121,6 +218,7 @@ func (j *Jobs) UpdateJob() gin.HandlerFunc {
 // @Produce json
 // @Param id path string true "Job ID"
 // @Success 200 {object} model.Job
+// @Header 200 {string} ETag "Version of the job"
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /jobs/{id} [get]
@@ -133,7 +231,7 @@ func (j *Jobs) GetJob() gin.HandlerFunc {
 			return
 		}
 
-		job, err := j.service.GetJob(ctx.Request.Context(), id)
+		job, err := j.service.GetJob(ctx.Request.Context(), currentNamespace(ctx), id)
 		if err != nil {
 			jobErr := errors.ToCustomJobError(err)
 
@@ -143,19 +241,24 @@ func (j *Jobs) GetJob() gin.HandlerFunc {
 
 		job.RemoveCredentials()
 
+		setJobETag(ctx, job)
 		ctx.JSON(http.StatusOK, job)
 	}
 }
 
 // DeleteJob godoc
 // @Summary Delete a job
-// @Description Delete a job with the given job ID
+// @Description Delete a job with the given job ID. The If-Match header must contain the ETag of the job, or "*".
 // @Tags jobs
 // @Accept json
 // @Produce json
 // @Param id path string true "Job ID"
+// @Param If-Match header string true "ETag of the job"
 // @Success 204
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /jobs/{id} [delete]
 func (j *Jobs) DeleteJob() gin.HandlerFunc {
@@ -167,7 +270,15 @@ func (j *Jobs) DeleteJob() gin.HandlerFunc {
 			return
 		}
 
-		if err := j.service.DeleteJob(ctx.Request.Context(), id); err != nil {
+		expectedVersion, err := ifMatchVersion(ctx)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		if err := j.service.DeleteJob(ctx.Request.Context(), currentNamespace(ctx), id, expectedVersion); err != nil {
 			jobErr := errors.ToCustomJobError(err)
 
 			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
@@ -180,42 +291,109 @@ func (j *Jobs) DeleteJob() gin.HandlerFunc {
 
 // ListJobs godoc
 // @Summary List jobs
-// @Description List jobs with the given limit and offset
+// @Description List jobs matching the filters, newest first unless sorted otherwise. Pages are fetched either by offset, or by passing the X-Next-Cursor header of the previous page as cursor, which stays stable when jobs are created or deleted in the meantime.
 // @Tags jobs
 // @Accept json
 // @Produce json
+// @Param type query string false "Job type, HTTP or AMQP"
+// @Param status query string false "Job status, RUNNING or STOPPED"
+// @Param tags query array false "Tags"
+// @Param tagMatch query string false "Whether jobs must have all (default) or any of the tags"
+// @Param url query string false "Substring of the URL of HTTP jobs"
+// @Param exchange query string false "Substring of the exchange of AMQP jobs"
+// @Param nextRunFrom query string false "Jobs running next at or after, RFC 3339"
+// @Param nextRunTo query string false "Jobs running next before, RFC 3339"
+// @Param createdFrom query string false "Jobs created at or after, RFC 3339"
+// @Param createdTo query string false "Jobs created before, RFC 3339"
+// @Param sort query string false "created_at, updated_at or next_run, prefixed with - for descending order; default -created_at"
+// @Param cursor query string false "Cursor of the next page"
 // @Param limit query int false "Limit"
 // @Param offset query int false "Offset"
-// @Param tags query array false "Tags"
 // @Success 200 {object} []model.Job
+// @Header 200 {integer} X-Total-Count "Number of jobs matching the filters"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, unless this is the last page"
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /jobs [get]
 func (j *Jobs) ListJobs() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 
-		limit, offset := LimitAndOffset(ctx)
-
-		tags := ctx.QueryArray("tags")
+		query, err := jobQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
 
-		jobs, err := j.service.ListJobs(ctx.Request.Context(), limit, offset, tags)
+		page, err := j.service.ListJobs(ctx.Request.Context(), currentNamespace(ctx), query)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
 			return
 		}
 
 		// Remove credentials from the jobs
-		for i := range jobs {
-			jobs[i].RemoveCredentials()
+		for i := range page.Jobs {
+			page.Jobs[i].RemoveCredentials()
+		}
+
+		ctx.Header(TotalCountHeader, strconv.FormatInt(page.Total, 10))
+		if page.NextCursor != "" {
+			ctx.Header(NextCursorHeader, page.NextCursor)
 		}
 
-		ctx.JSON(http.StatusOK, jobs)
+		ctx.JSON(http.StatusOK, page.Jobs)
 	}
 }
 
+const (
+	// TotalCountHeader holds the number of items matching the filters of a list request, on all pages.
+	TotalCountHeader = "X-Total-Count"
+
+	// NextCursorHeader holds the cursor of the next page of a list request.
+	NextCursorHeader = "X-Next-Cursor"
+)
+
+// jobQuery parses the filters, order and page of a job list request.
+func jobQuery(ctx *gin.Context) (model.JobQuery, error) {
+	limit, offset := LimitAndOffset(ctx)
+
+	query := model.JobQuery{
+		JobFilter: model.JobFilter{
+			Type:     model.JobType(ctx.Query("type")),
+			Status:   model.JobStatus(ctx.Query("status")),
+			Tags:     ctx.QueryArray("tags"),
+			TagMatch: model.TagMatch(ctx.Query("tagMatch")),
+			URL:      ctx.Query("url"),
+			Exchange: ctx.Query("exchange"),
+		},
+		Sort:   model.JobSort(ctx.Query("sort")),
+		Cursor: ctx.Query("cursor"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	ranges := map[string]*null.Time{
+		"nextRunFrom": &query.NextRunFrom,
+		"nextRunTo":   &query.NextRunTo,
+		"createdFrom": &query.CreatedFrom,
+		"createdTo":   &query.CreatedTo,
+	}
+
+	for key, value := range ranges {
+		parsed, err := parseTimeQuery(ctx, key, errors.ErrInvalidJobTimeRange)
+		if err != nil {
+			return model.JobQuery{}, err
+		}
+		*value = parsed
+	}
+
+	return query, nil
+}
+
 // GetJobExecutions godoc
 // @Summary Get job executions
-// @Description Get job executions with the given job ID, failed only flag, limit and offset
+// @Description Get job executions with the given job ID, failed only flag, limit and offset. Error messages are only returned to operators and admins.
 // @Tags jobs
 // @Accept json
 // @Produce json
@@ -240,7 +418,7 @@ func (j *Jobs) GetJobExecutions() gin.HandlerFunc {
 
 		limit, offset := LimitAndOffset(ctx)
 
-		executions, err := j.service.GetJobExecutions(ctx.Request.Context(), jobID, failedOnly, limit, offset)
+		executions, err := j.service.GetJobExecutions(ctx.Request.Context(), currentNamespace(ctx), jobID, failedOnly, limit, offset)
 		if err != nil {
 			jobErr := errors.ToCustomJobError(err)
 
@@ -248,6 +426,13 @@ func (j *Jobs) GetJobExecutions() gin.HandlerFunc {
 			return
 		}
 
+		// Error messages can contain response bodies of the called services
+		if !hasRole(ctx, model.RoleOperator) {
+			for i := range executions {
+				executions[i].ErrorMessage = null.String{}
+			}
+		}
+
 		ctx.JSON(http.StatusOK, map[string]interface {
 		}{
 			"executions": executions,
@@ -255,6 +440,161 @@ func (j *Jobs) GetJobExecutions() gin.HandlerFunc {
 	}
 }
 
+// GetJobVersions godoc
+// @Summary Get job versions
+// @Description Get the versions of the job definition with the given ID, newest first. A version is stored whenever the job is created, updated or rolled back.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.JobVersion
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/versions [get]
+func (j *Jobs) GetJobVersions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		jobID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		limit, offset := LimitAndOffset(ctx)
+
+		versions, err := j.service.GetJobVersions(ctx.Request.Context(), currentNamespace(ctx), jobID, limit, offset)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		// Remove credentials from the versions
+		for i := range versions {
+			versions[i].RemoveCredentials()
+		}
+
+		ctx.JSON(http.StatusOK, versions)
+	}
+}
+
+// RollbackJob godoc
+// @Summary Roll back a job
+// @Description Restore the definition of the job with the given ID to the given version. The restored definition is stored as a new version.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param version query int true "Version"
+// @Success 200 {object} model.Job
+// @Header 200 {string} ETag "Version of the job"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/rollback [post]
+func (j *Jobs) RollbackJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		jobID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		version, err := strconv.ParseInt(ctx.Query("version"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: errors.ErrInvalidJobVersion.Error()})
+			return
+		}
+
+		job, err := j.service.RollbackJob(ctx.Request.Context(), currentNamespace(ctx), jobID, version)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		job.RemoveCredentials()
+
+		setJobETag(ctx, job)
+		ctx.JSON(http.StatusOK, job)
+	}
+}
+
+// GetJobStats godoc
+// @Summary Get job execution statistics
+// @Description Get the success rate, duration percentiles, average scheduling lag and failure streaks of the executions of a job, over one or more windows ending now
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param window query []string false "Windows like 15m, 24h or 7d, at most 90 days (default: 24h)" collectionFormat(multi)
+// @Success 200 {object} model.JobStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/stats [get]
+func (j *Jobs) GetJobStats() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		jobID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		stats, err := j.service.GetJobStats(ctx.Request.Context(), currentNamespace(ctx), jobID, statsWindows(ctx))
+		if err != nil {
+			statsErr := errors.ToCustomJobError(err)
+			ctx.JSON(statsErr.Code, ErrorResponse{Error: statsErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, stats)
+	}
+}
+
+// GetJobAudit godoc
+// @Summary Get the audit log of a job
+// @Description Get the changes made to the job with the given ID, newest first. Secrets are redacted. The audit log of deleted jobs is kept.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.JobAudit
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/audit [get]
+func (j *Jobs) GetJobAudit() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		jobID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		limit, offset := LimitAndOffset(ctx)
+
+		entries, err := j.service.GetJobAudit(ctx.Request.Context(), currentNamespace(ctx), jobID, limit, offset)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, entries)
+	}
+}
+
 func LimitAndOffset(ctx *gin.Context) (uint64, uint64) {
 	limitStr := ctx.Query("limit")
 	offsetStr := ctx.Query("offset")
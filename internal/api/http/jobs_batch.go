@@ -0,0 +1,172 @@
+package http
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/idempotency"
+	jobService "github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+)
+
+// customMethod is a custom method of a collection, e.g. POST /v1/jobs:batchCreate, with the role it requires.
+type customMethod struct {
+	role    model.Role
+	handler gin.HandlerFunc
+}
+
+// customMethods dispatches the custom methods of a collection. Gin can't route a static suffix after a path segment,
+// so the routes of the custom methods share a wildcard, and the method is taken from the "method" parameter.
+func customMethods(methods map[string]customMethod) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		name, ok := strings.CutPrefix(ctx.Param("method"), ":")
+		method, found := methods[name]
+		if !ok || !found {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "unknown method"})
+			return
+		}
+
+		if !hasRole(ctx, method.role) {
+			authErr := errors.ToCustomJobError(errors.ErrForbidden)
+			ctx.JSON(authErr.Code, ErrorResponse{Error: authErr.Error()})
+			return
+		}
+
+		method.handler(ctx)
+	}
+}
+
+// jobsCustomMethods returns the batch operations on jobs.
+func jobsCustomMethods(jobsHandler *Jobs, idempotencyService *idempotency.Service) map[string]customMethod {
+	return map[string]customMethod{
+		"batchCreate": {role: model.RoleOperator, handler: idempotent(idempotencyService, jobsHandler.BatchCreateJobs())},
+		"batchUpdate": {role: model.RoleOperator, handler: jobsHandler.BatchUpdateJobs()},
+		"batchDelete": {role: model.RoleAdmin, handler: jobsHandler.BatchDeleteJobs()},
+	}
+}
+
+// BatchCreateJobs godoc
+// @Summary Create a batch of jobs
+// @Description Create up to 1000 jobs in a single transaction. If any job is invalid, no job is created and the response holds the errors of the invalid jobs. Jobs with the unique key of an existing job are not created; the existing job is returned with status 200.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key making the request safe to retry"
+// @Param batch body model.JobBatchCreate true "Jobs to create"
+// @Success 200 {object} model.JobBatchResponse
+// @Failure 400 {object} model.JobBatchResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs:batchCreate [post]
+func (j *Jobs) BatchCreateJobs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		batch := &model.JobBatchCreate{}
+		if err := ctx.BindJSON(batch); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		results, err := j.service.BatchCreateJobs(ctx.Request.Context(), currentNamespace(ctx), batch)
+		writeBatchResponse(ctx, results, err)
+	}
+}
+
+// BatchUpdateJobs godoc
+// @Summary Update a batch of jobs
+// @Description Apply the update to up to 1000 jobs, selected by their IDs or by tags, in a single transaction. If any selected job doesn't exist, doesn't have its expected version or would be invalid after the update, no job is updated and the response holds the errors of the invalid jobs.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param batch body model.JobBatchUpdate true "Selected jobs and update"
+// @Success 200 {object} model.JobBatchResponse
+// @Failure 400 {object} model.JobBatchResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs:batchUpdate [post]
+func (j *Jobs) BatchUpdateJobs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		batch := &model.JobBatchUpdate{}
+		if err := ctx.BindJSON(batch); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		results, err := j.service.BatchUpdateJobs(ctx.Request.Context(), currentNamespace(ctx), batch)
+		writeBatchResponse(ctx, results, err)
+	}
+}
+
+// BatchDeleteJobs godoc
+// @Summary Delete a batch of jobs
+// @Description Delete up to 1000 jobs, selected by their IDs or by tags, in a single transaction. Jobs that don't exist are reported with status 404, and jobs that don't have their expected version with status 412.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param batch body model.JobBatchDelete true "Selected jobs"
+// @Success 200 {object} model.JobBatchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs:batchDelete [post]
+func (j *Jobs) BatchDeleteJobs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		batch := &model.JobBatchDelete{}
+		if err := ctx.BindJSON(batch); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		results, err := j.service.BatchDeleteJobs(ctx.Request.Context(), currentNamespace(ctx), batch)
+		writeBatchResponse(ctx, results, err)
+	}
+}
+
+// writeBatchResponse writes the results of a batch operation with the status of every item. If the batch was
+// rejected because of invalid items, only the invalid items are returned.
+func writeBatchResponse(ctx *gin.Context, results []jobService.BatchResult, err error) {
+	if err != nil && !stderrors.Is(err, errors.ErrInvalidJobBatch) {
+		jobErr := errors.ToCustomJobError(err)
+
+		ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+		return
+	}
+
+	response := model.JobBatchResponse{Results: []model.JobBatchResult{}}
+	for i, result := range results {
+		item := model.JobBatchResult{Index: i, ID: result.ID, Status: http.StatusOK}
+
+		switch {
+		case result.Err != nil:
+			itemErr := errors.ToCustomJobError(result.Err)
+			item.Status = itemErr.Code
+			item.Error = itemErr.Error()
+		case err != nil:
+			// valid items of a rejected batch are omitted
+			continue
+		case result.Created:
+			item.Status = http.StatusCreated
+		}
+
+		if result.Job != nil && result.Err == nil {
+			result.Job.RemoveCredentials()
+			item.Job = result.Job
+		}
+
+		response.Results = append(response.Results, item)
+	}
+
+	if err != nil {
+		response.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/audit"
+)
+
+// RequestIDHeader carries the ID of a request, which is recorded in the audit log.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern limits the request IDs accepted from clients, so they can be safely logged and stored.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID assigns an ID to every request. The ID is taken from the X-Request-ID header, e.g. as set by a proxy, or
+// generated if the header is missing or invalid, and returned in the X-Request-ID response header.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Header(RequestIDHeader, requestID)
+		ctx.Request = ctx.Request.WithContext(audit.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	jobService "github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+)
+
+func StatsRoutesV1(router gin.IRouter, statsHandler *Stats) {
+	router.GET("/v1/stats", RequireRole(model.RoleViewer), statsHandler.GetStats())
+}
+
+func NewStatsHandler(service *jobService.Service) *Stats {
+	return &Stats{
+		service: service,
+	}
+}
+
+type Stats struct {
+	service *jobService.Service
+}
+
+// GetStats godoc
+// @Summary Get execution statistics
+// @Description Get the success rate, duration percentiles, average scheduling lag and failure streaks of the executions of all jobs in the namespace, over one or more windows ending now
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param window query []string false "Windows like 15m, 24h or 7d, at most 90 days (default: 24h)" collectionFormat(multi)
+// @Success 200 {object} model.NamespaceStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /stats [get]
+func (s *Stats) GetStats() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		stats, err := s.service.GetStats(ctx.Request.Context(), currentNamespace(ctx), statsWindows(ctx))
+		if err != nil {
+			statsErr := errors.ToCustomJobError(err)
+			ctx.JSON(statsErr.Code, ErrorResponse{Error: statsErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, stats)
+	}
+}
+
+// statsWindows returns the windows of the window query parameters, or the default window if there are none.
+func statsWindows(ctx *gin.Context) []model.StatsWindow {
+	values := ctx.QueryArray("window")
+	if len(values) == 0 {
+		return []model.StatsWindow{model.DefaultStatsWindow}
+	}
+
+	windows := make([]model.StatsWindow, 0, len(values))
+	for _, value := range values {
+		windows = append(windows, model.StatsWindow(value))
+	}
+
+	return windows
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	webhookService "github.com/xBlaz3kx/distributed-scheduler/internal/service/webhook"
+)
+
+func SubscriptionsRoutesV1(router gin.IRouter, subscriptionsHandler *Subscriptions) {
+	subscriptionsRouter := router.Group("/v1/subscriptions")
+	{
+		subscriptionsRouter.POST("", RequireRole(model.RoleAdmin), subscriptionsHandler.CreateSubscription())
+		subscriptionsRouter.GET("/:id", RequireRole(model.RoleOperator), subscriptionsHandler.GetSubscription())
+		subscriptionsRouter.PUT("/:id", RequireRole(model.RoleAdmin), subscriptionsHandler.UpdateSubscription())
+		subscriptionsRouter.DELETE("/:id", RequireRole(model.RoleAdmin), subscriptionsHandler.DeleteSubscription())
+		subscriptionsRouter.GET("", RequireRole(model.RoleOperator), subscriptionsHandler.ListSubscriptions())
+		subscriptionsRouter.GET("/:id/deliveries", RequireRole(model.RoleOperator), subscriptionsHandler.ListDeliveries())
+	}
+}
+
+func NewSubscriptionsHandler(service *webhookService.Service) *Subscriptions {
+	return &Subscriptions{
+		service: service,
+	}
+}
+
+type Subscriptions struct {
+	service *webhookService.Service
+}
+
+// CreateSubscription godoc
+// @Summary Create a webhook subscription
+// @Description Subscribe a URL to job and execution events of the namespace. Deliveries are signed with the returned secret, which is not returned again.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param subscription body model.WebhookSubscriptionCreate true "Webhook Subscription"
+// @Success 201 {object} model.WebhookSubscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions [post]
+func (s *Subscriptions) CreateSubscription() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		create := &model.WebhookSubscriptionCreate{}
+		if err := ctx.BindJSON(create); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		subscription, err := s.service.CreateSubscription(ctx.Request.Context(), currentNamespace(ctx), create)
+		if err != nil {
+			subscriptionErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(subscriptionErr.Code, ErrorResponse{Error: subscriptionErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, subscription)
+	}
+}
+
+// GetSubscription godoc
+// @Summary Get a webhook subscription
+// @Description Get a webhook subscription with the given ID, without its secret
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} model.WebhookSubscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id} [get]
+func (s *Subscriptions) GetSubscription() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: errors.ErrInvalidWebhookSubscriptionID.Error()})
+			return
+		}
+
+		subscription, err := s.service.GetSubscription(ctx.Request.Context(), currentNamespace(ctx), id)
+		if err != nil {
+			subscriptionErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(subscriptionErr.Code, ErrorResponse{Error: subscriptionErr.Error()})
+			return
+		}
+
+		subscription.RemoveSecret()
+
+		ctx.JSON(http.StatusOK, subscription)
+	}
+}
+
+// UpdateSubscription godoc
+// @Summary Update a webhook subscription
+// @Description Update the URL, events or state of a webhook subscription. If rotate_secret is set, the signing secret is replaced and the new secret is returned.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param subscription body model.WebhookSubscriptionUpdate true "Webhook Subscription Update"
+// @Success 200 {object} model.WebhookSubscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id} [put]
+func (s *Subscriptions) UpdateSubscription() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: errors.ErrInvalidWebhookSubscriptionID.Error()})
+			return
+		}
+
+		update := model.WebhookSubscriptionUpdate{}
+		if err := ctx.BindJSON(&update); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		subscription, err := s.service.UpdateSubscription(ctx.Request.Context(), currentNamespace(ctx), id, update)
+		if err != nil {
+			subscriptionErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(subscriptionErr.Code, ErrorResponse{Error: subscriptionErr.Error()})
+			return
+		}
+
+		if !update.RotateSecret {
+			subscription.RemoveSecret()
+		}
+
+		ctx.JSON(http.StatusOK, subscription)
+	}
+}
+
+// DeleteSubscription godoc
+// @Summary Delete a webhook subscription
+// @Description Delete a webhook subscription with the given ID and its delivery log. Pending deliveries are dropped.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id} [delete]
+func (s *Subscriptions) DeleteSubscription() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: errors.ErrInvalidWebhookSubscriptionID.Error()})
+			return
+		}
+
+		if err := s.service.DeleteSubscription(ctx.Request.Context(), currentNamespace(ctx), id); err != nil {
+			subscriptionErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(subscriptionErr.Code, ErrorResponse{Error: subscriptionErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// ListSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Description List webhook subscriptions with the given limit and offset, without their secrets
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.WebhookSubscription
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions [get]
+func (s *Subscriptions) ListSubscriptions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		limit, offset := LimitAndOffset(ctx)
+
+		subscriptions, err := s.service.ListSubscriptions(ctx.Request.Context(), currentNamespace(ctx), limit, offset)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		for i := range subscriptions {
+			subscriptions[i].RemoveSecret()
+		}
+
+		ctx.JSON(http.StatusOK, subscriptions)
+	}
+}
+
+// ListDeliveries godoc
+// @Summary List the deliveries of a webhook subscription
+// @Description List the deliveries of a webhook subscription, newest first, with their status, attempts and the outcome of the last attempt
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.WebhookDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/deliveries [get]
+func (s *Subscriptions) ListDeliveries() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: errors.ErrInvalidWebhookSubscriptionID.Error()})
+			return
+		}
+
+		limit, offset := LimitAndOffset(ctx)
+
+		deliveries, err := s.service.ListDeliveries(ctx.Request.Context(), currentNamespace(ctx), id, limit, offset)
+		if err != nil {
+			subscriptionErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(subscriptionErr.Code, ErrorResponse{Error: subscriptionErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, deliveries)
+	}
+}
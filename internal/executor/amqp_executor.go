@@ -4,17 +4,27 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"net"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
 	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
 )
 
-type amqpExecutor struct{}
+const amqpDialTimeout = 30 * time.Second
+
+type amqpExecutor struct {
+	dial func(network, address string) (net.Conn, error)
+}
 
 func (ae *amqpExecutor) Execute(ctx context.Context, j *model.Job) error {
 	// Create a new AMQP connection
-	conn, err := amqp.Dial(j.AMQPJob.Connection)
+	conn, err := amqp.DialConfig(j.AMQPJob.Connection, amqp.Config{
+		Heartbeat: 10 * time.Second,
+		Locale:    "en_US",
+		Dial:      ae.dial,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to AMQP: %w", err)
 	}
@@ -2,8 +2,11 @@ package executor
 
 import (
 	"fmt"
+	"net"
+	"net/http"
 
 	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/egress"
 )
 
 type Factory interface {
@@ -11,12 +14,24 @@ type Factory interface {
 }
 
 type factory struct {
-	client HttpClient
+	client     HttpClient
+	tokens     *tokenSourceCache
+	transports *transportCache
+	amqpDial   func(network, address string) (net.Conn, error)
 }
 
-func NewFactory(client HttpClient) Factory {
+// NewFactory creates an executor factory. If an egress policy is given, HTTP and AMQP connections
+// to destinations denied by the policy are refused when dialing.
+func NewFactory(client HttpClient, policy *egress.Policy) Factory {
+	if httpClient, ok := client.(*http.Client); ok && policy != nil {
+		client = policy.HTTPClient(httpClient)
+	}
+
 	return &factory{
-		client: client,
+		client:     client,
+		tokens:     newTokenSourceCache(client),
+		transports: newTransportCache(client),
+		amqpDial:   policy.AMQPDial(amqpDialTimeout),
 	}
 }
 
@@ -28,9 +43,20 @@ func (f *factory) NewExecutor(job *model.Job, options ...Option) (Executor, erro
 	var executor Executor
 	switch job.Type {
 	case model.JobTypeHTTP:
-		executor = &httpExecutor{Client: f.client}
+		client := f.client
+
+		// Jobs with custom TLS settings get a client with a dedicated transport
+		if job.HTTPJob != nil && job.HTTPJob.TLS != nil {
+			var err error
+			client, err = f.transports.Client(job.HTTPJob.TLS)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		executor = &httpExecutor{Client: client, tokens: f.tokens}
 	case model.JobTypeAMQP:
-		executor = &amqpExecutor{}
+		executor = &amqpExecutor{dial: f.amqpDial}
 	default:
 		return nil, fmt.Errorf("unknown job type: %v", job.Type)
 	}
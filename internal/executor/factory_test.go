@@ -17,7 +17,7 @@ func TestNewExecutor(t *testing.T) {
 		},
 	}
 
-	factory := NewFactory(&http.Client{})
+	factory := NewFactory(&http.Client{}, nil)
 
 	executor, err := factory.NewExecutor(j)
 	assert.Nil(t, err)
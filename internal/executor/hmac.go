@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+)
+
+// Headers used by the HMAC signature schemes
+const (
+	HMACDefaultSignatureHeader = "X-Scheduler-Signature"
+	HMACDefaultTimestampHeader = "X-Scheduler-Timestamp"
+	HMACStripeSignatureHeader  = "Stripe-Signature"
+	HMACGitHubSignatureHeader  = "X-Hub-Signature-256"
+	HMACGitHubTimestampHeader  = "X-Hub-Signature-Timestamp"
+)
+
+// signHTTPRequest signs the request with the job's HMAC secret and sets the signature
+// and timestamp headers according to the configured scheme.
+func signHTTPRequest(req *http.Request, auth model.Auth, body string, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := computeHMACSignature(auth.HMACSecret.String, timestamp, req.Method, req.URL.RequestURI(), body)
+
+	switch auth.HMACScheme {
+	case model.HMACSchemeStripe:
+		req.Header.Set(HMACStripeSignatureHeader, fmt.Sprintf("t=%s,v1=%s", timestamp, signature))
+	case model.HMACSchemeGitHub:
+		req.Header.Set(HMACGitHubTimestampHeader, timestamp)
+		req.Header.Set(HMACGitHubSignatureHeader, "sha256="+signature)
+	default:
+		req.Header.Set(HMACDefaultTimestampHeader, timestamp)
+		req.Header.Set(HMACDefaultSignatureHeader, "sha256="+signature)
+	}
+}
+
+// computeHMACSignature returns the hex encoded HMAC-SHA256 of "timestamp.method.path.body".
+// Receivers should recompute the signature the same way and reject stale timestamps.
+func computeHMACSignature(secret, timestamp, method, path, body string) string {
+	return security.SignHMAC(secret, timestamp, method, path, body)
+}
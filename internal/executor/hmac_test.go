@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestSignHTTPRequest(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := `{"hello":"world"}`
+	expectedSignature := computeHMACSignature("secret", "1700000000", http.MethodPost, "/hooks?id=1", body)
+
+	tests := []struct {
+		name    string
+		scheme  model.HMACScheme
+		headers map[string]string
+	}{
+		{
+			name:   "default scheme",
+			scheme: model.HMACSchemeDefault,
+			headers: map[string]string{
+				HMACDefaultTimestampHeader: "1700000000",
+				HMACDefaultSignatureHeader: "sha256=" + expectedSignature,
+			},
+		},
+		{
+			name:   "empty scheme falls back to default",
+			scheme: "",
+			headers: map[string]string{
+				HMACDefaultTimestampHeader: "1700000000",
+				HMACDefaultSignatureHeader: "sha256=" + expectedSignature,
+			},
+		},
+		{
+			name:   "stripe scheme",
+			scheme: model.HMACSchemeStripe,
+			headers: map[string]string{
+				HMACStripeSignatureHeader: "t=1700000000,v1=" + expectedSignature,
+			},
+		},
+		{
+			name:   "github scheme",
+			scheme: model.HMACSchemeGitHub,
+			headers: map[string]string{
+				HMACGitHubTimestampHeader: "1700000000",
+				HMACGitHubSignatureHeader: "sha256=" + expectedSignature,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "https://example.com/hooks?id=1", nil)
+			require.NoError(t, err)
+
+			signHTTPRequest(req, model.Auth{
+				Type:       model.AuthTypeHMAC,
+				HMACSecret: null.StringFrom("secret"),
+				HMACScheme: tc.scheme,
+			}, body, now)
+
+			for header, value := range tc.headers {
+				assert.Equal(t, value, req.Header.Get(header))
+			}
+		})
+	}
+}
+
+func TestHTTPExecutor_createHTTPRequest_HMAC(t *testing.T) {
+	j := &model.Job{
+		HTTPJob: &model.HTTPJob{
+			Method: http.MethodPost,
+			URL:    "https://example.com/hooks",
+			Body:   null.StringFrom("payload"),
+			Auth: model.Auth{
+				Type:       model.AuthTypeHMAC,
+				HMACSecret: null.StringFrom("secret"),
+			},
+		},
+	}
+
+	httpExecutor := &httpExecutor{}
+	req, err := httpExecutor.createHTTPRequest(context.Background(), j)
+	require.NoError(t, err)
+
+	timestamp := req.Header.Get(HMACDefaultTimestampHeader)
+	assert.NotEmpty(t, timestamp)
+	assert.Equal(t, "sha256="+computeHMACSignature("secret", timestamp, http.MethodPost, "/hooks", "payload"), req.Header.Get(HMACDefaultSignatureHeader))
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
 	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
@@ -19,6 +20,9 @@ const (
 
 type httpExecutor struct {
 	Client HttpClient
+
+	// tokens caches OAuth2 tokens between executions
+	tokens *tokenSourceCache
 }
 
 // HttpClient interface
@@ -81,7 +85,9 @@ func (he *httpExecutor) createHTTPRequest(ctx context.Context, j *model.Job) (*h
 	he.setHTTPRequestHeaders(req, j.HTTPJob.Headers)
 
 	// Set the auth
-	he.setHTTPRequestAuth(req, j.HTTPJob.Auth)
+	if err := he.setHTTPRequestAuth(req, j.HTTPJob.Auth, j.HTTPJob.Body.String); err != nil {
+		return nil, err
+	}
 
 	return req, nil
 }
@@ -108,11 +114,26 @@ func (he *httpExecutor) setHTTPRequestHeaders(req *http.Request, headers map[str
 	}
 }
 
-func (he *httpExecutor) setHTTPRequestAuth(req *http.Request, auth model.Auth) {
+func (he *httpExecutor) setHTTPRequestAuth(req *http.Request, auth model.Auth, body string) error {
 	switch auth.Type {
 	case model.AuthTypeBasic:
 		req.SetBasicAuth(auth.Username.String, auth.Password.String)
 	case model.AuthTypeBearer:
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth.BearerToken.String))
+	case model.AuthTypeHMAC:
+		signHTTPRequest(req, auth, body, time.Now())
+	case model.AuthTypeOAuth2ClientCredentials:
+		if he.tokens == nil {
+			he.tokens = newTokenSourceCache(he.Client)
+		}
+
+		token, err := he.tokens.Token(auth.OAuth2)
+		if err != nil {
+			return err
+		}
+
+		token.SetAuthHeader(req)
 	}
+
+	return nil
 }
@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// oauth2ExpiryDelta is how long before the token expires it gets refreshed.
+const oauth2ExpiryDelta = 30 * time.Second
+
+// oauth2IdleTimeout is how long a token source is kept unused, if its token doesn't expire.
+const oauth2IdleTimeout = time.Hour
+
+// tokenSourceCache caches OAuth2 token sources per credential set, so that
+// jobs sharing the same credentials share a token and only refresh it once it is about to expire.
+// Token sources unused until their token expires are evicted, so rotated secrets
+// and changed scopes don't keep their tokens in memory.
+type tokenSourceCache struct {
+	mu      sync.Mutex
+	client  *http.Client
+	sources map[string]*cachedTokenSource
+	now     func() time.Time
+}
+
+// cachedTokenSource is a token source with the time it is evicted at, unless it is used again.
+type cachedTokenSource struct {
+	source    oauth2.TokenSource
+	expiresAt time.Time
+}
+
+func newTokenSourceCache(client HttpClient) *tokenSourceCache {
+	// Reuse the executor's client for token requests when possible
+	httpClient, ok := client.(*http.Client)
+	if !ok {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &tokenSourceCache{
+		client:  httpClient,
+		sources: make(map[string]*cachedTokenSource),
+		now:     time.Now,
+	}
+}
+
+// Token returns a valid access token for the given credentials, fetching a new one if needed.
+func (c *tokenSourceCache) Token(credentials *model.OAuth2ClientCredentials) (*oauth2.Token, error) {
+	if credentials == nil {
+		return nil, fmt.Errorf("%w: %w", errors.ErrOAuth2TokenFetchFailed, errors.ErrOAuth2NotDefined)
+	}
+
+	key := credentialsKey(credentials)
+
+	token, err := c.tokenSource(key, credentials).Token()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errors.ErrOAuth2TokenFetchFailed, err)
+	}
+
+	c.used(key, token)
+
+	return token, nil
+}
+
+func (c *tokenSourceCache) tokenSource(key string, credentials *model.OAuth2ClientCredentials) oauth2.TokenSource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evict()
+
+	if cached, ok := c.sources[key]; ok {
+		return cached.source
+	}
+
+	cfg := clientcredentials.Config{
+		ClientID:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret.String,
+		TokenURL:     credentials.TokenURL,
+		Scopes:       credentials.Scopes,
+	}
+
+	if credentials.Audience != "" {
+		cfg.EndpointParams = url.Values{"audience": []string{credentials.Audience}}
+	}
+
+	// The token source outlives a single execution, so it must not be bound to the execution context.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, c.client)
+	source := oauth2.ReuseTokenSourceWithExpiry(nil, cfg.TokenSource(ctx), oauth2ExpiryDelta)
+	c.sources[key] = &cachedTokenSource{
+		source:    source,
+		expiresAt: c.now().Add(oauth2IdleTimeout),
+	}
+
+	return source
+}
+
+// used keeps the token source of the credentials until the token expires,
+// or for the idle timeout if the token doesn't expire.
+func (c *tokenSourceCache) used(key string, token *oauth2.Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.sources[key]
+	if !ok {
+		return
+	}
+
+	if token.Expiry.IsZero() {
+		cached.expiresAt = c.now().Add(oauth2IdleTimeout)
+	} else {
+		cached.expiresAt = token.Expiry
+	}
+}
+
+// evict removes the token sources that weren't used before their token expired. The lock must be held.
+func (c *tokenSourceCache) evict() {
+	now := c.now()
+	for key, cached := range c.sources {
+		if now.After(cached.expiresAt) {
+			delete(c.sources, key)
+		}
+	}
+}
+
+// credentialsKey identifies a credential set. The secret is hashed so it is part of the key,
+// meaning a rotated secret results in a new token source.
+func credentialsKey(credentials *model.OAuth2ClientCredentials) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		credentials.TokenURL,
+		credentials.ClientID,
+		credentials.ClientSecret.String,
+		strings.Join(credentials.Scopes, " "),
+		credentials.Audience,
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,166 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errors "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+func newTokenServer(t *testing.T, expiresIn int, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "https://api.example.com", r.PostForm.Get("audience"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "bearer", "expires_in": %d}`, atomic.LoadInt32(requests), expiresIn)
+	}))
+}
+
+func TestHTTPExecutor_OAuth2ClientCredentials(t *testing.T) {
+	var tokenRequests int32
+	tokenServer := newTokenServer(t, 3600, &tokenRequests)
+	defer tokenServer.Close()
+
+	j := &model.Job{
+		HTTPJob: &model.HTTPJob{
+			Method: http.MethodGet,
+			URL:    "https://example.com",
+			Auth: model.Auth{
+				Type: model.AuthTypeOAuth2ClientCredentials,
+				OAuth2: &model.OAuth2ClientCredentials{
+					TokenURL:     tokenServer.URL,
+					ClientID:     "client",
+					ClientSecret: null.StringFrom("secret"),
+					Audience:     "https://api.example.com",
+				},
+			},
+		},
+	}
+
+	var authHeaders []string
+	client := &MockHttpClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			authHeaders = append(authHeaders, req.Header.Get("Authorization"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       httptest.NewRecorder().Result().Body,
+			}, nil
+		},
+	}
+
+	factory := NewFactory(client, nil)
+
+	for i := 0; i < 2; i++ {
+		executor, err := factory.NewExecutor(&model.Job{Type: model.JobTypeHTTP})
+		require.NoError(t, err)
+		require.NoError(t, executor.Execute(context.Background(), j))
+	}
+
+	// The token is fetched once and reused for both executions
+	assert.EqualValues(t, 1, atomic.LoadInt32(&tokenRequests))
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1"}, authHeaders)
+}
+
+func TestHTTPExecutor_OAuth2ClientCredentials_RefreshesExpiringToken(t *testing.T) {
+	var tokenRequests int32
+	// Token expires within the expiry delta, so it has to be refreshed on every use
+	tokenServer := newTokenServer(t, 5, &tokenRequests)
+	defer tokenServer.Close()
+
+	cache := newTokenSourceCache(http.DefaultClient)
+	credentials := &model.OAuth2ClientCredentials{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "client",
+		ClientSecret: null.StringFrom("secret"),
+		Audience:     "https://api.example.com",
+	}
+
+	token, err := cache.Token(credentials)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token.AccessToken)
+
+	token, err = cache.Token(credentials)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token.AccessToken)
+}
+
+func TestHTTPExecutor_OAuth2ClientCredentials_FetchFailure(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "invalid_client"}`, http.StatusUnauthorized)
+	}))
+	defer tokenServer.Close()
+
+	j := &model.Job{
+		HTTPJob: &model.HTTPJob{
+			Method: http.MethodGet,
+			URL:    "https://example.com",
+			Auth: model.Auth{
+				Type: model.AuthTypeOAuth2ClientCredentials,
+				OAuth2: &model.OAuth2ClientCredentials{
+					TokenURL:     tokenServer.URL,
+					ClientID:     "client",
+					ClientSecret: null.StringFrom("wrong"),
+				},
+			},
+		},
+	}
+
+	client := &MockHttpClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			t.Fatal("request must not be sent without a token")
+			return nil, nil
+		},
+	}
+
+	httpExecutor := &httpExecutor{Client: client, tokens: newTokenSourceCache(http.DefaultClient)}
+	err := httpExecutor.Execute(context.Background(), j)
+	assert.ErrorIs(t, err, errors.ErrOAuth2TokenFetchFailed)
+}
+
+func TestTokenSourceCache_EvictsUnusedTokenSources(t *testing.T) {
+	var tokenRequests int32
+	tokenServer := newTokenServer(t, 3600, &tokenRequests)
+	defer tokenServer.Close()
+
+	now := time.Now()
+	cache := newTokenSourceCache(http.DefaultClient)
+	cache.now = func() time.Time { return now }
+
+	credentials := &model.OAuth2ClientCredentials{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "client",
+		ClientSecret: null.StringFrom("secret"),
+		Audience:     "https://api.example.com",
+	}
+
+	_, err := cache.Token(credentials)
+	require.NoError(t, err)
+
+	// The secret is rotated, the token source of the old secret is kept until its token expires
+	rotated := *credentials
+	rotated.ClientSecret = null.StringFrom("rotated")
+
+	_, err = cache.Token(&rotated)
+	require.NoError(t, err)
+	assert.Len(t, cache.sources, 2)
+
+	now = now.Add(2 * time.Hour)
+
+	_, err = cache.Token(&rotated)
+	require.NoError(t, err)
+	assert.Len(t, cache.sources, 1)
+	assert.Contains(t, cache.sources, credentialsKey(&rotated))
+}
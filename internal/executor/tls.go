@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+)
+
+// transportCache builds and caches HTTP clients per TLS profile, so jobs with
+// the same TLS settings share a transport (and its connection pool).
+type transportCache struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	base    *http.Transport
+	clients map[string]*http.Client
+
+	// checkRedirect of the default client, which refuses redirects to destinations denied by the egress policy
+	checkRedirect func(req *http.Request, via []*http.Request) error
+}
+
+func newTransportCache(client HttpClient) *transportCache {
+	cache := &transportCache{
+		timeout: 30 * time.Second,
+		base:    http.DefaultTransport.(*http.Transport),
+		clients: make(map[string]*http.Client),
+	}
+
+	// Inherit the timeout, redirect policy and transport settings of the default client
+	if httpClient, ok := client.(*http.Client); ok {
+		cache.timeout = httpClient.Timeout
+		cache.checkRedirect = httpClient.CheckRedirect
+		if transport, ok := httpClient.Transport.(*http.Transport); ok {
+			cache.base = transport
+		}
+	}
+
+	return cache
+}
+
+// Client returns a client configured with the given TLS settings.
+func (c *transportCache) Client(cfg *model.TLSConfig) (HttpClient, error) {
+	key := tlsProfileKey(cfg)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[key]; ok {
+		return client, nil
+	}
+
+	tlsConfig, err := cfg.ToTLS()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := c.base.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	client := &http.Client{
+		Timeout:       c.timeout,
+		Transport:     transport,
+		CheckRedirect: c.checkRedirect,
+	}
+	c.clients[key] = client
+
+	return client, nil
+}
+
+// tlsProfileKey identifies a TLS profile. The key material is hashed, so it never ends up in memory as a map key.
+func tlsProfileKey(cfg *model.TLSConfig) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		cfg.ClientCert.String,
+		cfg.ClientKey.String,
+		cfg.CABundle,
+		cfg.ServerName,
+		string(cfg.MinVersion),
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
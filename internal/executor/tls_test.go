@@ -0,0 +1,183 @@
+package executor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/egress"
+	"gopkg.in/guregu/null.v4"
+)
+
+// generateClientCertificate creates a self-signed client certificate and returns the PEM encoded certificate and key.
+func generateClientCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "scheduler"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	return string(certPEM), string(keyPEM)
+}
+
+func TestHTTPExecutor_MutualTLS(t *testing.T) {
+	clientCert, clientKey := generateClientCertificate(t)
+
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM([]byte(clientCert)))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	factory := NewFactory(&http.Client{Timeout: 5 * time.Second}, nil)
+
+	t.Run("client certificate and custom CA", func(t *testing.T) {
+		j := &model.Job{
+			Type: model.JobTypeHTTP,
+			HTTPJob: &model.HTTPJob{
+				Method: http.MethodGet,
+				URL:    server.URL,
+				Auth:   model.Auth{Type: model.AuthTypeNone},
+				TLS: &model.TLSConfig{
+					ClientCert: null.StringFrom(clientCert),
+					ClientKey:  null.StringFrom(clientKey),
+					CABundle:   caBundle,
+					ServerName: "example.com",
+					MinVersion: model.TLSVersion12,
+				},
+			},
+		}
+
+		executor, err := factory.NewExecutor(j)
+		require.NoError(t, err)
+		assert.NoError(t, executor.Execute(context.Background(), j))
+	})
+
+	t.Run("missing client certificate", func(t *testing.T) {
+		j := &model.Job{
+			Type: model.JobTypeHTTP,
+			HTTPJob: &model.HTTPJob{
+				Method: http.MethodGet,
+				URL:    server.URL,
+				Auth:   model.Auth{Type: model.AuthTypeNone},
+				TLS: &model.TLSConfig{
+					CABundle:   caBundle,
+					ServerName: "example.com",
+				},
+			},
+		}
+
+		executor, err := factory.NewExecutor(j)
+		require.NoError(t, err)
+		assert.Error(t, executor.Execute(context.Background(), j))
+	})
+
+	t.Run("unknown CA", func(t *testing.T) {
+		j := &model.Job{
+			Type: model.JobTypeHTTP,
+			HTTPJob: &model.HTTPJob{
+				Method: http.MethodGet,
+				URL:    server.URL,
+				Auth:   model.Auth{Type: model.AuthTypeNone},
+				TLS: &model.TLSConfig{
+					ClientCert: null.StringFrom(clientCert),
+					ClientKey:  null.StringFrom(clientKey),
+				},
+			},
+		}
+
+		executor, err := factory.NewExecutor(j)
+		require.NoError(t, err)
+		assert.Error(t, executor.Execute(context.Background(), j))
+	})
+}
+
+func TestTransportCache_Client(t *testing.T) {
+	clientCert, clientKey := generateClientCertificate(t)
+	cache := newTransportCache(&http.Client{Timeout: 5 * time.Second})
+
+	profile := &model.TLSConfig{
+		ClientCert: null.StringFrom(clientCert),
+		ClientKey:  null.StringFrom(clientKey),
+		MinVersion: model.TLSVersion13,
+	}
+
+	first, err := cache.Client(profile)
+	require.NoError(t, err)
+
+	// Same profile reuses the client
+	second, err := cache.Client(&model.TLSConfig{
+		ClientCert: null.StringFrom(clientCert),
+		ClientKey:  null.StringFrom(clientKey),
+		MinVersion: model.TLSVersion13,
+	})
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	// A different profile gets a new client
+	third, err := cache.Client(&model.TLSConfig{MinVersion: model.TLSVersion12})
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+
+	client := first.(*http.Client)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+	assert.Equal(t, uint16(tls.VersionTLS13), client.Transport.(*http.Transport).TLSClientConfig.MinVersion)
+}
+
+func TestTransportCache_ClientRedirects(t *testing.T) {
+	policy, err := egress.NewPolicy(egress.Config{DeniedCIDRs: egress.DefaultDeniedCIDRs})
+	require.NoError(t, err)
+
+	cache := newTransportCache(policy.HTTPClient(&http.Client{Timeout: 5 * time.Second}))
+
+	client, err := cache.Client(&model.TLSConfig{MinVersion: model.TLSVersion12})
+	require.NoError(t, err)
+
+	// Redirects to destinations denied by the policy are refused, like by the default client
+	redirect, err := http.NewRequest(http.MethodGet, "http://169.254.169.254/latest/meta-data", nil)
+	require.NoError(t, err)
+
+	checkRedirect := client.(*http.Client).CheckRedirect
+	require.NotNil(t, checkRedirect)
+	assert.Error(t, checkRedirect(redirect, nil))
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// APIKey authenticates API clients. Only a hash of the key is stored; the key itself is returned once, when it is created.
+//
+// swagger:model APIKey
+type APIKey struct {
+	ID        uuid.UUID `json:"id"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"` // e.g., "ci-pipeline"
+
+	// Prefix identifies the key without revealing it, e.g. "dsk_k3j9x2mq"
+	Prefix string `json:"prefix"`
+
+	// Hash of the key, never returned to the user
+	Hash string `json:"-"`
+
+	Role Role `json:"role"`
+
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt null.Time `json:"last_used_at" swaggertype:"string"`
+	RevokedAt  null.Time `json:"revoked_at" swaggertype:"string"`
+}
+
+// Revoked reports whether the key was revoked.
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt.Valid
+}
+
+// swagger:model APIKeyCreate
+type APIKeyCreate struct {
+	Name string `json:"name"`
+	// Role granted to the key, defaults to viewer
+	Role Role `json:"role,omitempty"`
+}
+
+func (k *APIKeyCreate) Validate() error {
+	if k.Name == "" || len(k.Name) > 255 {
+		return error2.ErrInvalidAPIKeyName
+	}
+
+	if k.Role != "" && !k.Role.Valid() {
+		return error2.ErrInvalidRole
+	}
+
+	return nil
+}
+
+// CreatedAPIKey is returned when a key is created. It is the only time the key is returned.
+//
+// swagger:model CreatedAPIKey
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,227 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+type AuditAction string
+
+// AuditAction is the kind of change recorded in the audit log.
+const (
+	AuditActionCreate   AuditAction = "create"
+	AuditActionUpdate   AuditAction = "update"
+	AuditActionDelete   AuditAction = "delete"
+	AuditActionRollback AuditAction = "rollback"
+
+	// AuditActionPause and AuditActionResume are updates stopping and restarting the job
+	AuditActionPause  AuditAction = "pause"
+	AuditActionResume AuditAction = "resume"
+)
+
+func (a AuditAction) Valid() bool {
+	switch a {
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionRollback, AuditActionPause, AuditActionResume:
+		return true
+	default:
+		return false
+	}
+}
+
+// JobAudit records a change to a job: who made it, when, and which fields changed. Secrets are redacted.
+//
+// swagger:model JobAudit
+type JobAudit struct {
+	ID        int64       `json:"id"`
+	JobID     uuid.UUID   `json:"job_id"`
+	Namespace string      `json:"namespace"`
+	Action    AuditAction `json:"action"`
+
+	// Actor made the change, e.g. "api_key:dsk_k3j9x2mq" or "jwt:alice"
+	Actor     string      `json:"actor"`
+	RequestID null.String `json:"request_id" swaggertype:"string"`
+
+	// Changes maps the changed fields, e.g. "http_job.url", to their values before and after the change
+	Changes map[string]AuditChange `json:"changes"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditChange holds the value of a field before and after a change. Values of fields that didn't exist are null.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditFilter filters the audit log. Empty fields match all entries.
+type AuditFilter struct {
+	JobID  uuid.NullUUID
+	Action AuditAction
+	Actor  string
+	From   null.Time
+	To     null.Time
+}
+
+// Validate validates an AuditFilter struct.
+func (f *AuditFilter) Validate() error {
+	if f.Action != "" && !f.Action.Valid() {
+		return error2.ErrInvalidAuditAction
+	}
+
+	if f.From.Valid && f.To.Valid && !f.From.Time.Before(f.To.Time) {
+		return error2.ErrInvalidAuditTimeRange
+	}
+
+	return nil
+}
+
+// auditIgnoredFields change on every write and are not part of the job definition.
+var auditIgnoredFields = map[string]struct{}{
+	"version":    {},
+	"created_at": {},
+	"updated_at": {},
+	"next_run":   {},
+}
+
+// auditRedactedFields hold secrets.
+var auditRedactedFields = map[string]struct{}{
+	"http_job.auth.username":             {},
+	"http_job.auth.password":             {},
+	"http_job.auth.bearer_token":         {},
+	"http_job.auth.hmac_secret":          {},
+	"http_job.auth.oauth2.client_secret": {},
+	"http_job.tls.client_cert":           {},
+	"http_job.tls.client_key":            {},
+	"http_job.body":                      {},
+	"amqp_job.body":                      {},
+}
+
+// auditURLFields hold URLs that can contain credentials.
+var auditURLFields = map[string]struct{}{
+	"http_job.url":                   {},
+	"http_job.auth.oauth2.token_url": {},
+	"amqp_job.connection":            {},
+}
+
+// UpdateAuditAction returns the action of an update of the job: pause if it stopped the job, resume if it restarted
+// the job, or update otherwise.
+func UpdateAuditAction(before, after *Job) AuditAction {
+	switch {
+	case before.Status == JobStatusRunning && after.Status == JobStatusStopped:
+		return AuditActionPause
+	case before.Status == JobStatusStopped && after.Status == JobStatusRunning:
+		return AuditActionResume
+	default:
+		return AuditActionUpdate
+	}
+}
+
+// DiffJobs returns the fields that differ between the two versions of a job, with secrets redacted. A nil job has no
+// fields, so the diff of a created or deleted job contains all of its fields.
+func DiffJobs(before, after *Job) (map[string]AuditChange, error) {
+	beforeFields, err := flattenJob(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := flattenJob(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]AuditChange{}
+	for field, beforeValue := range beforeFields {
+		afterValue, ok := afterFields[field]
+		if !ok || !reflect.DeepEqual(beforeValue, afterValue) {
+			changes[field] = redactAuditChange(field, AuditChange{Before: beforeValue, After: afterValue})
+		}
+	}
+
+	for field, afterValue := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = redactAuditChange(field, AuditChange{After: afterValue})
+		}
+	}
+
+	return changes, nil
+}
+
+// flattenJob returns the non-null fields of the job's JSON representation, keyed by their dot separated path.
+func flattenJob(job *Job) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if job == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	flatten("", object, fields)
+
+	for field := range auditIgnoredFields {
+		delete(fields, field)
+	}
+
+	return fields, nil
+}
+
+func flatten(prefix string, object map[string]interface{}, fields map[string]interface{}) {
+	for key, value := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		switch value := value.(type) {
+		case nil:
+		case map[string]interface{}:
+			flatten(path, value, fields)
+		default:
+			fields[path] = value
+		}
+	}
+}
+
+func redactAuditChange(field string, change AuditChange) AuditChange {
+	return AuditChange{
+		Before: redactAuditValue(field, change.Before),
+		After:  redactAuditValue(field, change.After),
+	}
+}
+
+func redactAuditValue(field string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	if _, ok := auditRedactedFields[field]; ok {
+		return Redacted
+	}
+
+	if _, ok := auditURLFields[field]; ok {
+		if rawURL, ok := value.(string); ok {
+			return redactURL(rawURL)
+		}
+	}
+
+	for _, headers := range []string{"http_job.headers.", "amqp_job.headers."} {
+		if header, ok := strings.CutPrefix(field, headers); ok && isSensitiveHeader(header) {
+			return Redacted
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// ExecutionEventType is the kind of execution event.
+type ExecutionEventType string
+
+const (
+	// ExecutionEventStarted is emitted when a runner picks up a job to execute it
+	ExecutionEventStarted ExecutionEventType = "execution.started"
+
+	// ExecutionEventFinished is emitted when the execution of a job is recorded
+	ExecutionEventFinished ExecutionEventType = "execution.finished"
+)
+
+// ExecutionEvent announces that a runner started or finished executing a job. Events are numbered in the order they
+// were emitted, so a client can resume a stream of events after the last event it received.
+type ExecutionEvent struct {
+	ID        int64              `json:"id"`
+	Type      ExecutionEventType `json:"type"`
+	Namespace string             `json:"namespace"`
+	JobID     uuid.UUID          `json:"job_id"`
+
+	// InstanceID of the runner executing the job
+	InstanceID  null.String `json:"instance_id" swaggertype:"string"`
+	ScheduledAt null.Time   `json:"scheduled_at" swaggertype:"string"`
+
+	// Execution is the recorded execution of finished events
+	Execution *JobExecution `json:"execution,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches reports whether the event belongs to the namespace and, if the job ID is set, to the job.
+func (e *ExecutionEvent) Matches(namespace string, jobID uuid.NullUUID) bool {
+	return e.Namespace == namespace && (!jobID.Valid || e.JobID == jobID.UUID)
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// ExecutionFilter filters job executions across all jobs of a namespace. Empty fields match all executions.
+type ExecutionFilter struct {
+	Status JobExecutionStatus
+
+	// Range of start times, including the start and excluding the end
+	From null.Time
+	To   null.Time
+
+	// Type and tags of the executed jobs; all of the tags, unless TagMatch is "any"
+	JobType  JobType
+	Tags     []string
+	TagMatch TagMatch
+
+	// InstanceID of the runner that ran the executions
+	InstanceID string
+
+	// Error matches a substring of the error message, case-insensitive
+	Error string
+}
+
+// ExecutionQuery selects and paginates job executions, the most recent first. Pages can be fetched either by offset, or
+// by passing the cursor of the previous page, which stays stable while new executions are recorded.
+type ExecutionQuery struct {
+	ExecutionFilter
+	Cursor string
+	Limit  uint64
+	Offset uint64
+}
+
+// Validate validates an ExecutionQuery struct.
+func (q *ExecutionQuery) Validate() error {
+	if q.Status != "" && !q.Status.Valid() {
+		return error2.ErrInvalidExecutionStatus
+	}
+
+	if q.JobType != "" && !q.JobType.Valid() {
+		return error2.ErrInvalidJobType
+	}
+
+	if q.TagMatch != "" && !q.TagMatch.Valid() {
+		return error2.ErrInvalidTagMatch
+	}
+
+	if q.From.Valid && q.To.Valid && !q.From.Time.Before(q.To.Time) {
+		return error2.ErrInvalidExecutionTimeRange
+	}
+
+	if q.Cursor != "" {
+		if q.Offset > 0 {
+			return error2.ErrInvalidExecutionCursor
+		}
+
+		if _, err := ParseExecutionCursor(q.Cursor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExecutionCursor is the position of the last execution of a page, from which the next page continues.
+type ExecutionCursor struct {
+	StartTime time.Time `json:"t"`
+	ID        int       `json:"id"`
+}
+
+// NewExecutionCursor returns the opaque cursor of the page ending with the execution.
+func NewExecutionCursor(execution *JobExecution) string {
+	data, _ := json.Marshal(ExecutionCursor{StartTime: execution.StartTime, ID: execution.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseExecutionCursor parses an opaque cursor.
+func ParseExecutionCursor(cursor string) (*ExecutionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, error2.ErrInvalidExecutionCursor
+	}
+
+	parsed := &ExecutionCursor{}
+	if err := json.Unmarshal(data, parsed); err != nil || parsed.ID <= 0 || parsed.StartTime.IsZero() {
+		return nil, error2.ErrInvalidExecutionCursor
+	}
+
+	return parsed, nil
+}
+
+// ExecutionPage is a page of job executions.
+type ExecutionPage struct {
+	Executions []JobExecution
+
+	// NextCursor continues with the next page; it is empty on the last page
+	NextCursor string
+
+	// Total number of executions matching the filter, on all pages
+	Total int64
+}
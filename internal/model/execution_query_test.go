@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestExecutionQuery_Validate(t *testing.T) {
+	now := time.Now()
+	cursor := NewExecutionCursor(&JobExecution{ID: 42, StartTime: now})
+
+	tests := []struct {
+		name  string
+		query ExecutionQuery
+		want  error
+	}{
+		{name: "empty", query: ExecutionQuery{}},
+		{
+			name: "all filters",
+			query: ExecutionQuery{
+				ExecutionFilter: ExecutionFilter{
+					Status:     JobExecutionStatusFailed,
+					From:       null.TimeFrom(now.Add(-15 * time.Minute)),
+					To:         null.TimeFrom(now),
+					JobType:    JobTypeAMQP,
+					Tags:       []string{"billing"},
+					TagMatch:   TagMatchAll,
+					InstanceID: "runner-1",
+					Error:      "timeout",
+				},
+				Limit:  10,
+				Offset: 20,
+			},
+		},
+		{name: "cursor", query: ExecutionQuery{Cursor: cursor}},
+		{name: "invalid status", query: ExecutionQuery{ExecutionFilter: ExecutionFilter{Status: "failed"}}, want: error2.ErrInvalidExecutionStatus},
+		{name: "invalid job type", query: ExecutionQuery{ExecutionFilter: ExecutionFilter{JobType: "SMTP"}}, want: error2.ErrInvalidJobType},
+		{name: "invalid tag match", query: ExecutionQuery{ExecutionFilter: ExecutionFilter{TagMatch: "none"}}, want: error2.ErrInvalidTagMatch},
+		{
+			name:  "invalid time range",
+			query: ExecutionQuery{ExecutionFilter: ExecutionFilter{From: null.TimeFrom(now), To: null.TimeFrom(now.Add(-time.Minute))}},
+			want:  error2.ErrInvalidExecutionTimeRange,
+		},
+		{name: "invalid cursor", query: ExecutionQuery{Cursor: "not-a-cursor"}, want: error2.ErrInvalidExecutionCursor},
+		{name: "cursor with offset", query: ExecutionQuery{Cursor: cursor, Offset: 10}, want: error2.ErrInvalidExecutionCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.query.Validate(), tt.want)
+		})
+	}
+}
+
+func TestExecutionCursor(t *testing.T) {
+	execution := &JobExecution{ID: 7, StartTime: time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)}
+
+	cursor, err := ParseExecutionCursor(NewExecutionCursor(execution))
+	require.NoError(t, err)
+	assert.Equal(t, 7, cursor.ID)
+	assert.True(t, execution.StartTime.Equal(cursor.StartTime))
+}
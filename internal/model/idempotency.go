@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// IdempotencyKey records a request made with an Idempotency-Key header and, once the request completed, its response.
+// Retries of the request with the same key get the recorded response instead of repeating the request.
+type IdempotencyKey struct {
+	Namespace string
+	Key       string
+
+	// RequestHash identifies the request, so the key can't be reused for a different request
+	RequestHash string
+
+	// StatusCode and Response are set when the request completed, and ETag if the response had one
+	StatusCode null.Int
+	ETag       null.String
+	Response   []byte
+
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the request with the key completed.
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode.Valid
+}
+
+// ValidateIdempotencyKey validates an Idempotency-Key header value: 1-255 printable ASCII characters.
+func ValidateIdempotencyKey(key string) error {
+	if key == "" || len(key) > 255 {
+		return error2.ErrInvalidIdempotencyKey
+	}
+
+	for _, c := range key {
+		if c < ' ' || c > '~' {
+			return error2.ErrInvalidIdempotencyKey
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	for _, key := range []string{"a", "8e03978e-40d5-43e8-bc93-6894a57f9324", "order 42/create", strings.Repeat("k", 255)} {
+		assert.NoError(t, ValidateIdempotencyKey(key), key)
+	}
+
+	for _, key := range []string{"", strings.Repeat("k", 256), "line\nbreak", "tab\tkey", "ключ"} {
+		assert.ErrorIs(t, ValidateIdempotencyKey(key), error2.ErrInvalidIdempotencyKey, key)
+	}
+}
@@ -1,9 +1,14 @@
 package model
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"time"
 
 	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/mergepatch"
 	"gopkg.in/guregu/null.v4"
 
 	"github.com/google/uuid"
@@ -50,9 +55,17 @@ func (js JobStatus) Valid() bool {
 
 // swagger:model Job
 type Job struct {
-	ID     uuid.UUID `json:"id"`
-	Type   JobType   `json:"type"`
-	Status JobStatus `json:"status"`
+	ID        uuid.UUID `json:"id"`
+	Namespace string    `json:"namespace"`
+	Type      JobType   `json:"type"`
+	Status    JobStatus `json:"status"`
+
+	// Version of the job definition, incremented on every update
+	Version int64 `json:"version"`
+
+	// Optional key, unique within the namespace, set when the job is created. Creating a job with the key of an
+	// existing job returns the existing job.
+	UniqueKey null.String `json:"unique_key" swaggertype:"string"`
 
 	ExecuteAt    null.Time   `json:"execute_at" swaggertype:"string"`    // for one-off jobs
 	CronSchedule null.String `json:"cron_schedule" swaggertype:"string"` // for recurring jobs
@@ -65,9 +78,11 @@ type Job struct {
 	UpdatedAt time.Time `json:"updated_at"`
 
 	// when the job is scheduled to run next (can be null if the job is not scheduled to run again)
-	NextRun           null.Time `json:"next_run,omitempty"`
-	NumberOfRuns      *int      `json:"num_runs,omitempty"`
-	AllowedFailedRuns *int      `json:"allowed_failed_runs,omitempty"`
+	NextRun      null.Time `json:"next_run,omitempty"`
+	NumberOfRuns *int      `json:"num_runs,omitempty"`
+
+	// Optional number of consecutive failed executions after which the scheduler stops the job
+	AllowedFailedRuns *int `json:"allowed_failed_runs,omitempty"`
 
 	// Custom user tags that can be used to filter jobs
 	Tags []string `json:"tags"`
@@ -83,6 +98,8 @@ type JobUpdate struct {
 	ExecuteAt    *time.Time `json:"execute_at,omitempty"`
 
 	Tags *[]string `json:"tags,omitempty"`
+
+	AllowedFailedRuns *int `json:"allowed_failed_runs,omitempty"`
 }
 
 func (j *Job) ApplyUpdate(update JobUpdate) {
@@ -112,11 +129,55 @@ func (j *Job) ApplyUpdate(update JobUpdate) {
 		j.Tags = *update.Tags
 	}
 
+	if update.AllowedFailedRuns != nil {
+		j.AllowedFailedRuns = update.AllowedFailedRuns
+	}
+
 	j.UpdatedAt = time.Now()
 
 	j.SetInitialRunTime()
 }
 
+// ApplyMergePatch applies the JSON merge patch (RFC 7386) to the job document and returns the patched job. Fields
+// omitted from the patch, including secrets that are never returned to clients, keep their values. The ID,
+// namespace, version, unique key and timestamps of the job can't be patched.
+func (j *Job) ApplyMergePatch(patch []byte) (*Job, error) {
+	document, err := json.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := mergepatch.Apply(document, patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", error2.ErrInvalidJobPatch, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+
+	job := &Job{}
+	if err := decoder.Decode(job); err != nil {
+		return nil, fmt.Errorf("%w: %v", error2.ErrInvalidJobPatch, err)
+	}
+
+	job.ID = j.ID
+	job.Namespace = j.Namespace
+	job.Version = j.Version
+	job.UniqueKey = j.UniqueKey
+	job.CreatedAt = j.CreatedAt
+	job.UpdatedAt = time.Now()
+	job.NextRun = null.Time{}
+
+	job.SetInitialRunTime()
+
+	return job, nil
+}
+
+// ETag returns the entity tag of the job's current version, used for optimistic concurrency control.
+func (j *Job) ETag() string {
+	return strconv.Quote(strconv.FormatInt(j.Version, 10))
+}
+
 // Validate validates a Job struct.
 func (j *Job) Validate() error {
 	if j.ID == uuid.Nil {
@@ -131,6 +192,14 @@ func (j *Job) Validate() error {
 		return error2.ErrInvalidJobStatus
 	}
 
+	if j.UniqueKey.Valid && (j.UniqueKey.String == "" || len(j.UniqueKey.String) > 255) {
+		return error2.ErrInvalidJobUniqueKey
+	}
+
+	if j.AllowedFailedRuns != nil && *j.AllowedFailedRuns < 1 {
+		return error2.ErrInvalidAllowedFailedRuns
+	}
+
 	if j.Type == JobTypeHTTP {
 		if err := j.HTTPJob.Validate(); err != nil {
 			return err
@@ -231,6 +300,12 @@ type JobCreate struct {
 	AMQPJob *AMQPJob `json:"amqp_job,omitempty"`
 
 	Tags []string `json:"tags"`
+
+	// Optional key, unique within the namespace. If a job with the key exists, it is returned instead.
+	UniqueKey null.String `json:"unique_key" swaggertype:"string"`
+
+	// Optional number of consecutive failed executions after which the scheduler stops the job
+	AllowedFailedRuns *int `json:"allowed_failed_runs,omitempty"`
 }
 
 func (j *JobCreate) ToJob() *Job {
@@ -238,6 +313,8 @@ func (j *JobCreate) ToJob() *Job {
 		ID:           uuid.New(),
 		Type:         j.Type,
 		Status:       JobStatusRunning,
+		Version:      1,
+		UniqueKey:    j.UniqueKey,
 		ExecuteAt:    j.ExecuteAt,
 		CronSchedule: j.CronSchedule,
 		HTTPJob:      j.HTTPJob,
@@ -245,6 +322,8 @@ func (j *JobCreate) ToJob() *Job {
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 		Tags:         j.Tags,
+
+		AllowedFailedRuns: j.AllowedFailedRuns,
 	}
 
 	job.SetInitialRunTime()
@@ -32,6 +32,9 @@ type AMQPJob struct {
 	Body         string                 `json:"body"`          // e.g., "Hello, world!"
 	BodyEncoding *BodyEncoding          `json:"body_encoding"` // e.g., null, "base64"
 	ContentType  string                 `json:"content_type"`  // e.g., "text/plain"
+
+	// ConnectionCredential references a named "amqp_connection" credential used instead of Connection.
+	ConnectionCredential *string `json:"connection_credential,omitempty"`
 }
 
 // Validate validates an AMQPJob struct.
@@ -40,9 +43,15 @@ func (amqpJob *AMQPJob) Validate() error {
 		return error2.ErrAMQPJobNotDefined
 	}
 
-	_, err := url.Parse(amqpJob.Connection)
-	if err != nil {
-		return error2.ErrAMQPConnectionInvalid
+	if amqpJob.ConnectionCredential != nil {
+		if *amqpJob.ConnectionCredential == "" {
+			return error2.ErrInvalidCredentialName
+		}
+	} else {
+		_, err := url.Parse(amqpJob.Connection)
+		if err != nil {
+			return error2.ErrAMQPConnectionInvalid
+		}
 	}
 
 	if amqpJob.Exchange == "" {
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+// MaxJobBatchSize is the maximum number of jobs a batch operation can create, update or delete.
+const MaxJobBatchSize = 1000
+
+// JobWrite is a change to a job of a batch operation, with the audit log entry recording it.
+type JobWrite struct {
+	Job   *Job
+	Audit *JobAudit
+}
+
+// JobSelector selects the jobs of a batch operation, either by their IDs or by tags. Jobs selected by tags must have
+// all the tags.
+//
+// swagger:model JobSelector
+type JobSelector struct {
+	IDs  []uuid.UUID `json:"ids,omitempty"`
+	Tags []string    `json:"tags,omitempty"`
+
+	// ExpectedVersions maps IDs of selected jobs to the version they must still have, like the ETag sent in the
+	// If-Match header of a single job. Jobs that were modified in the meantime fail with status 412.
+	ExpectedVersions map[uuid.UUID]int64 `json:"expected_versions,omitempty"`
+}
+
+func (s *JobSelector) Validate() error {
+	if (len(s.IDs) == 0) == (len(s.Tags) == 0) {
+		return error2.ErrInvalidJobSelector
+	}
+
+	if len(s.IDs) > MaxJobBatchSize {
+		return error2.ErrJobBatchTooLarge
+	}
+
+	for id, version := range s.ExpectedVersions {
+		if version < 1 || !lo.Contains(s.IDs, id) {
+			return error2.ErrInvalidExpectedVersions
+		}
+	}
+
+	return nil
+}
+
+// JobBatchCreate creates all the jobs, or none of them if any of them is invalid.
+//
+// swagger:model JobBatchCreate
+type JobBatchCreate struct {
+	Jobs []JobCreate `json:"jobs"`
+}
+
+func (b *JobBatchCreate) Validate() error {
+	switch {
+	case len(b.Jobs) == 0:
+		return error2.ErrEmptyJobBatch
+	case len(b.Jobs) > MaxJobBatchSize:
+		return error2.ErrJobBatchTooLarge
+	}
+
+	return nil
+}
+
+// JobBatchUpdate applies the update to all the selected jobs, or to none of them if any of the updated jobs is invalid.
+//
+// swagger:model JobBatchUpdate
+type JobBatchUpdate struct {
+	JobSelector
+	Update JobUpdate `json:"update"`
+}
+
+// JobBatchDelete deletes all the selected jobs.
+//
+// swagger:model JobBatchDelete
+type JobBatchDelete struct {
+	JobSelector
+}
+
+// JobBatchResult is the result of one item of a batch operation: the job, or the reason the item failed.
+//
+// swagger:model JobBatchResult
+type JobBatchResult struct {
+	// Index of the item in the request, for batch creations
+	Index int       `json:"index"`
+	ID    uuid.UUID `json:"id"`
+
+	// HTTP status code of the item, e.g. 201 for a created job
+	Status int    `json:"status"`
+	Job    *Job   `json:"job,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// JobBatchResponse holds the results of a batch operation, in the order of the items of the request.
+//
+// swagger:model JobBatchResponse
+type JobBatchResponse struct {
+	// Error is set if the batch was rejected; the results then only hold the invalid items
+	Error   string           `json:"error,omitempty"`
+	Results []JobBatchResult `json:"results"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+func TestJobSelector_Validate(t *testing.T) {
+	assert.NoError(t, (&JobSelector{IDs: []uuid.UUID{uuid.New()}}).Validate())
+	assert.NoError(t, (&JobSelector{Tags: []string{"billing"}}).Validate())
+
+	assert.ErrorIs(t, (&JobSelector{}).Validate(), error2.ErrInvalidJobSelector)
+	assert.ErrorIs(t, (&JobSelector{IDs: []uuid.UUID{uuid.New()}, Tags: []string{"billing"}}).Validate(), error2.ErrInvalidJobSelector)
+	assert.ErrorIs(t, (&JobSelector{IDs: make([]uuid.UUID, MaxJobBatchSize+1)}).Validate(), error2.ErrJobBatchTooLarge)
+
+	id := uuid.New()
+	assert.NoError(t, (&JobSelector{IDs: []uuid.UUID{id}, ExpectedVersions: map[uuid.UUID]int64{id: 2}}).Validate())
+	assert.ErrorIs(t, (&JobSelector{IDs: []uuid.UUID{id}, ExpectedVersions: map[uuid.UUID]int64{id: 0}}).Validate(), error2.ErrInvalidExpectedVersions)
+	assert.ErrorIs(t, (&JobSelector{IDs: []uuid.UUID{id}, ExpectedVersions: map[uuid.UUID]int64{uuid.New(): 1}}).Validate(), error2.ErrInvalidExpectedVersions)
+	assert.ErrorIs(t, (&JobSelector{Tags: []string{"billing"}, ExpectedVersions: map[uuid.UUID]int64{id: 1}}).Validate(), error2.ErrInvalidExpectedVersions)
+}
+
+func TestJobBatchCreate_Validate(t *testing.T) {
+	assert.NoError(t, (&JobBatchCreate{Jobs: make([]JobCreate, MaxJobBatchSize)}).Validate())
+
+	assert.ErrorIs(t, (&JobBatchCreate{}).Validate(), error2.ErrEmptyJobBatch)
+	assert.ErrorIs(t, (&JobBatchCreate{Jobs: make([]JobCreate, MaxJobBatchSize+1)}).Validate(), error2.ErrJobBatchTooLarge)
+}
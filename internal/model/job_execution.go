@@ -10,12 +10,15 @@ import (
 type JobExecution struct {
 	ID                 int         `json:"id"`
 	JobID              uuid.UUID   `json:"job_id"`
+	JobVersion         null.Int    `json:"job_version" swaggertype:"integer"` // version of the job the execution ran with
+	ScheduledAt        null.Time   `json:"scheduled_at" swaggertype:"string"` // planned start of the execution
 	StartTime          time.Time   `json:"start_time"`
 	EndTime            time.Time   `json:"end_time"`
 	Success            bool        `json:"success"`
 	NumberOfExecutions int         `json:"number_of_executions"`
 	NumberOfRetries    int         `json:"number_of_retries"`
 	ErrorMessage       null.String `json:"error_message,omitempty" swaggertype:"string"`
+	InstanceID         null.String `json:"instance_id,omitempty" swaggertype:"string"` // runner instance the execution ran on
 }
 
 type JobExecutionStatus string
@@ -24,3 +27,7 @@ const (
 	JobExecutionStatusSuccessful JobExecutionStatus = "SUCCESSFUL"
 	JobExecutionStatusFailed     JobExecutionStatus = "FAILED"
 )
+
+func (s JobExecutionStatus) Valid() bool {
+	return s == JobExecutionStatusSuccessful || s == JobExecutionStatusFailed
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"net/url"
+
 	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
 	"gopkg.in/guregu/null.v4"
 )
@@ -11,11 +13,35 @@ const (
 	AuthTypeNone   AuthType = "none"
 	AuthTypeBasic  AuthType = "basic"
 	AuthTypeBearer AuthType = "bearer"
+	AuthTypeHMAC   AuthType = "hmac"
+
+	AuthTypeOAuth2ClientCredentials AuthType = "oauth2_client_credentials"
 )
 
 func (at AuthType) Valid() bool {
 	switch at {
-	case AuthTypeNone, AuthTypeBasic, AuthTypeBearer:
+	case AuthTypeNone, AuthTypeBasic, AuthTypeBearer, AuthTypeHMAC, AuthTypeOAuth2ClientCredentials:
+		return true
+	default:
+		return false
+	}
+}
+
+// HMACScheme determines which headers the HMAC signature and timestamp are sent in.
+type HMACScheme string
+
+const (
+	// HMACSchemeDefault sends X-Scheduler-Timestamp and X-Scheduler-Signature headers.
+	HMACSchemeDefault HMACScheme = "default"
+	// HMACSchemeStripe sends a single Stripe-Signature header in the form "t=<timestamp>,v1=<signature>".
+	HMACSchemeStripe HMACScheme = "stripe"
+	// HMACSchemeGitHub sends X-Hub-Signature-256 ("sha256=<signature>") and X-Hub-Signature-Timestamp headers.
+	HMACSchemeGitHub HMACScheme = "github"
+)
+
+func (hs HMACScheme) Valid() bool {
+	switch hs {
+	case "", HMACSchemeDefault, HMACSchemeStripe, HMACSchemeGitHub:
 		return true
 	default:
 		return false
@@ -29,6 +55,7 @@ type HTTPJob struct {
 	Body               null.String       `json:"body" swaggertype:"string"` // e.g., "{\"hello\": \"world\"}"
 	ValidResponseCodes []int             `json:"valid_response_codes"`      // e.g., [200, 201, 202]
 	Auth               Auth              `json:"auth"`                      // e.g., {"type": "basic", "username": "foo", "password": "bar"}
+	TLS                *TLSConfig        `json:"tls,omitempty"`             // e.g., {"ca_bundle": "-----BEGIN CERTIFICATE-----...", "min_version": "1.2"}
 }
 
 // Validate validates an HTTPJob struct.
@@ -49,6 +76,12 @@ func (httpJob *HTTPJob) Validate() error {
 		return err
 	}
 
+	if httpJob.TLS != nil {
+		if err := httpJob.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -56,13 +89,66 @@ func (httpJob *HTTPJob) RemoveCredentials() {
 	httpJob.Auth.Username = null.String{}
 	httpJob.Auth.Password = null.String{}
 	httpJob.Auth.BearerToken = null.String{}
+	httpJob.Auth.HMACSecret = null.String{}
+
+	if httpJob.Auth.OAuth2 != nil {
+		httpJob.Auth.OAuth2.ClientSecret = null.String{}
+	}
+
+	if httpJob.TLS != nil {
+		httpJob.TLS.ClientCert = null.String{}
+		httpJob.TLS.ClientKey = null.String{}
+	}
 }
 
 type Auth struct {
-	Type        AuthType    `json:"type"`                                        // e.g., "none", "basic", "bearer"
+	Type        AuthType    `json:"type"`                                        // e.g., "none", "basic", "bearer", "hmac", "oauth2_client_credentials"
 	Username    null.String `json:"username,omitempty" swaggertype:"string"`     // for "basic"
 	Password    null.String `json:"password,omitempty" swaggertype:"string"`     // for "basic"
 	BearerToken null.String `json:"bearer_token,omitempty" swaggertype:"string"` // for "bearer"
+	HMACSecret  null.String `json:"hmac_secret,omitempty" swaggertype:"string"`  // for "hmac"
+	HMACScheme  HMACScheme  `json:"hmac_scheme,omitempty"`                       // for "hmac", e.g., "default", "stripe", "github"
+
+	OAuth2 *OAuth2ClientCredentials `json:"oauth2,omitempty"` // for "oauth2_client_credentials"
+
+	// Credential references a named credential holding the secrets for "basic", "bearer" or "hmac".
+	// When set, the secrets are resolved by the runner at execution time instead of being stored on the job.
+	Credential null.String `json:"credential,omitempty" swaggertype:"string"`
+}
+
+// OAuth2ClientCredentials holds the configuration for the OAuth2 client credentials grant.
+// The runner fetches a token from TokenURL and reuses it until shortly before it expires.
+type OAuth2ClientCredentials struct {
+	TokenURL     string      `json:"token_url"`                                    // e.g., "https://auth.example.com/oauth/token"
+	ClientID     string      `json:"client_id"`                                    // e.g., "scheduler"
+	ClientSecret null.String `json:"client_secret,omitempty" swaggertype:"string"` // stored encrypted
+	Scopes       []string    `json:"scopes,omitempty"`                             // e.g., ["jobs:write"]
+	Audience     string      `json:"audience,omitempty"`                           // e.g., "https://api.example.com"
+}
+
+// Validate validates an OAuth2ClientCredentials struct.
+func (o *OAuth2ClientCredentials) Validate() error {
+	if o == nil {
+		return error2.ErrOAuth2NotDefined
+	}
+
+	if o.TokenURL == "" {
+		return error2.ErrEmptyOAuth2TokenURL
+	}
+
+	if _, err := url.ParseRequestURI(o.TokenURL); err != nil {
+		return error2.ErrInvalidOAuth2TokenURL
+	}
+
+	if o.ClientID == "" {
+		return error2.ErrEmptyOAuth2ClientID
+	}
+
+	if !o.ClientSecret.Valid || o.ClientSecret.String == "" {
+		return error2.ErrEmptyOAuth2ClientSecret
+	}
+
+	return nil
 }
 
 func (auth *Auth) Validate() error {
@@ -74,6 +160,10 @@ func (auth *Auth) Validate() error {
 		return error2.ErrInvalidAuthType
 	}
 
+	if auth.Credential.Valid {
+		return auth.validateCredentialReference()
+	}
+
 	if auth.Type == AuthTypeBasic {
 		if !auth.Username.Valid || auth.Username.String == "" {
 			return error2.ErrEmptyUsername
@@ -88,5 +178,39 @@ func (auth *Auth) Validate() error {
 		return error2.ErrEmptyBearerToken
 	}
 
+	if auth.Type == AuthTypeHMAC {
+		if !auth.HMACSecret.Valid || auth.HMACSecret.String == "" {
+			return error2.ErrEmptyHMACSecret
+		}
+
+		if !auth.HMACScheme.Valid() {
+			return error2.ErrInvalidHMACScheme
+		}
+	}
+
+	if auth.Type == AuthTypeOAuth2ClientCredentials {
+		if err := auth.OAuth2.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+func (auth *Auth) validateCredentialReference() error {
+	if auth.Credential.String == "" {
+		return error2.ErrInvalidCredentialName
+	}
+
+	switch auth.Type {
+	case AuthTypeBasic, AuthTypeBearer:
+		return nil
+	case AuthTypeHMAC:
+		if !auth.HMACScheme.Valid() {
+			return error2.ErrInvalidHMACScheme
+		}
+		return nil
+	default:
+		return error2.ErrCredentialTypeMismatch
+	}
+}
@@ -131,6 +131,49 @@ func TestAuthValidate(t *testing.T) {
 			},
 			want: error2.ErrEmptyBearerToken,
 		},
+		{
+			name: "valid auth: oauth2 client credentials",
+			auth: Auth{
+				Type: AuthTypeOAuth2ClientCredentials,
+				OAuth2: &OAuth2ClientCredentials{
+					TokenURL:     "https://auth.example.com/oauth/token",
+					ClientID:     "client",
+					ClientSecret: null.StringFrom("secret"),
+					Scopes:       []string{"jobs"},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "invalid auth: missing oauth2 settings",
+			auth: Auth{
+				Type: AuthTypeOAuth2ClientCredentials,
+			},
+			want: error2.ErrOAuth2NotDefined,
+		},
+		{
+			name: "invalid auth: invalid oauth2 token URL",
+			auth: Auth{
+				Type: AuthTypeOAuth2ClientCredentials,
+				OAuth2: &OAuth2ClientCredentials{
+					TokenURL:     "not a url",
+					ClientID:     "client",
+					ClientSecret: null.StringFrom("secret"),
+				},
+			},
+			want: error2.ErrInvalidOAuth2TokenURL,
+		},
+		{
+			name: "invalid auth: missing oauth2 client secret",
+			auth: Auth{
+				Type: AuthTypeOAuth2ClientCredentials,
+				OAuth2: &OAuth2ClientCredentials{
+					TokenURL: "https://auth.example.com/oauth/token",
+					ClientID: "client",
+				},
+			},
+			want: error2.ErrEmptyOAuth2ClientSecret,
+		},
 	}
 
 	for _, tc := range tests {
@@ -140,3 +183,49 @@ func TestAuthValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestTLSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		tls  TLSConfig
+		want error
+	}{
+		{
+			name: "valid tls: empty",
+			tls:  TLSConfig{},
+			want: nil,
+		},
+		{
+			name: "valid tls: min version and server name",
+			tls:  TLSConfig{MinVersion: TLSVersion13, ServerName: "internal.example.com"},
+			want: nil,
+		},
+		{
+			name: "invalid tls: unknown min version",
+			tls:  TLSConfig{MinVersion: "2.0"},
+			want: error2.ErrInvalidTLSMinVersion,
+		},
+		{
+			name: "invalid tls: key without certificate",
+			tls:  TLSConfig{ClientKey: null.StringFrom("key")},
+			want: error2.ErrIncompleteTLSClientKeyPair,
+		},
+		{
+			name: "invalid tls: malformed key pair",
+			tls:  TLSConfig{ClientCert: null.StringFrom("cert"), ClientKey: null.StringFrom("key")},
+			want: error2.ErrInvalidTLSClientKeyPair,
+		},
+		{
+			name: "invalid tls: malformed CA bundle",
+			tls:  TLSConfig{CABundle: "not a certificate"},
+			want: error2.ErrInvalidTLSCABundle,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.tls.Validate()
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
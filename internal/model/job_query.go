@@ -0,0 +1,195 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// JobSort orders jobs by a field, ascending or, prefixed with "-", descending, e.g. "-created_at". Jobs with the same
+// value are ordered by ID.
+type JobSort string
+
+const (
+	JobSortCreatedAt JobSort = "created_at"
+	JobSortUpdatedAt JobSort = "updated_at"
+	JobSortNextRun   JobSort = "next_run"
+
+	// DefaultJobSort lists the newest jobs first
+	DefaultJobSort = "-" + JobSortCreatedAt
+)
+
+// Field returns the field jobs are ordered by.
+func (s JobSort) Field() JobSort {
+	return JobSort(strings.TrimPrefix(string(s), "-"))
+}
+
+// Descending reports whether jobs are ordered from the highest to the lowest value.
+func (s JobSort) Descending() bool {
+	return strings.HasPrefix(string(s), "-")
+}
+
+func (s JobSort) Valid() bool {
+	switch s.Field() {
+	case JobSortCreatedAt, JobSortUpdatedAt, JobSortNextRun:
+		return true
+	default:
+		return false
+	}
+}
+
+// value returns the value of the sort field of the job, as stored in a cursor. Jobs that won't run again are ordered
+// after all other jobs by next run.
+func (s JobSort) value(job *Job) string {
+	switch s.Field() {
+	case JobSortUpdatedAt:
+		return job.UpdatedAt.Format(time.RFC3339Nano)
+	case JobSortNextRun:
+		if !job.NextRun.Valid {
+			return "infinity"
+		}
+		return job.NextRun.Time.Format(time.RFC3339Nano)
+	default:
+		return job.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// TagMatch defines whether jobs must have all or any of the tags of a filter.
+type TagMatch string
+
+const (
+	TagMatchAll TagMatch = "all"
+	TagMatchAny TagMatch = "any"
+)
+
+func (m TagMatch) Valid() bool {
+	return m == TagMatchAll || m == TagMatchAny
+}
+
+// JobFilter filters jobs. Empty fields match all jobs.
+type JobFilter struct {
+	Type   JobType
+	Status JobStatus
+
+	// Tags the jobs must have; all of them, unless TagMatch is "any"
+	Tags     []string
+	TagMatch TagMatch
+
+	// URL and Exchange match substrings of the URL of HTTP jobs and the exchange of AMQP jobs, case-insensitive
+	URL      string
+	Exchange string
+
+	// Ranges of next run and creation times, including the start and excluding the end
+	NextRunFrom null.Time
+	NextRunTo   null.Time
+	CreatedFrom null.Time
+	CreatedTo   null.Time
+}
+
+// JobQuery selects, orders and paginates jobs. Pages can be fetched either by offset, or by passing the cursor of the
+// previous page, which stays stable when jobs are created or deleted in the meantime.
+type JobQuery struct {
+	JobFilter
+	Sort   JobSort
+	Cursor string
+	Limit  uint64
+	Offset uint64
+}
+
+// Validate validates a JobQuery struct.
+func (q *JobQuery) Validate() error {
+	if q.Type != "" && !q.Type.Valid() {
+		return error2.ErrInvalidJobType
+	}
+
+	if q.Status != "" && !q.Status.Valid() {
+		return error2.ErrInvalidJobStatus
+	}
+
+	if q.TagMatch != "" && !q.TagMatch.Valid() {
+		return error2.ErrInvalidTagMatch
+	}
+
+	if q.NextRunFrom.Valid && q.NextRunTo.Valid && !q.NextRunFrom.Time.Before(q.NextRunTo.Time) {
+		return error2.ErrInvalidJobTimeRange
+	}
+
+	if q.CreatedFrom.Valid && q.CreatedTo.Valid && !q.CreatedFrom.Time.Before(q.CreatedTo.Time) {
+		return error2.ErrInvalidJobTimeRange
+	}
+
+	if q.Sort != "" && !q.Sort.Valid() {
+		return error2.ErrInvalidJobSort
+	}
+
+	if q.Cursor != "" {
+		if q.Offset > 0 {
+			return error2.ErrInvalidJobCursor
+		}
+
+		if _, err := ParseJobCursor(q.Cursor, q.SortOrDefault()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SortOrDefault returns the order of the query, or the default order if it has none.
+func (q *JobQuery) SortOrDefault() JobSort {
+	if q.Sort == "" {
+		return DefaultJobSort
+	}
+
+	return q.Sort
+}
+
+// JobCursor is the position of the last job of a page, from which the next page continues.
+type JobCursor struct {
+	Sort JobSort `json:"s"`
+
+	// Value of the sort field of the last job
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// NewJobCursor returns the opaque cursor of the page ending with the job.
+func NewJobCursor(sort JobSort, job *Job) string {
+	data, _ := json.Marshal(JobCursor{Sort: sort, Value: sort.value(job), ID: job.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseJobCursor parses an opaque cursor. The cursor must have been created for the same order.
+func ParseJobCursor(cursor string, sort JobSort) (*JobCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, error2.ErrInvalidJobCursor
+	}
+
+	parsed := &JobCursor{}
+	if err := json.Unmarshal(data, parsed); err != nil || parsed.Sort != sort || parsed.ID == uuid.Nil {
+		return nil, error2.ErrInvalidJobCursor
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, parsed.Value); err != nil && parsed.Value != "infinity" {
+		return nil, error2.ErrInvalidJobCursor
+	}
+
+	return parsed, nil
+}
+
+// JobPage is a page of jobs.
+type JobPage struct {
+	Jobs []Job
+
+	// NextCursor continues with the next page; it is empty on the last page
+	NextCursor string
+
+	// Total number of jobs matching the filter, on all pages
+	Total int64
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestJobSort(t *testing.T) {
+	assert.Equal(t, JobSortCreatedAt, JobSort("-created_at").Field())
+	assert.True(t, JobSort("-created_at").Descending())
+	assert.False(t, JobSortNextRun.Descending())
+
+	for _, sort := range []JobSort{JobSortCreatedAt, JobSortUpdatedAt, JobSortNextRun, DefaultJobSort, "-next_run"} {
+		assert.True(t, sort.Valid(), sort)
+	}
+
+	for _, sort := range []JobSort{"", "-", "id", "--created_at", "created_at-"} {
+		assert.False(t, sort.Valid(), sort)
+	}
+}
+
+func TestJobQuery_Validate(t *testing.T) {
+	now := time.Now()
+	cursor := NewJobCursor(DefaultJobSort, &Job{ID: uuid.New(), CreatedAt: now})
+
+	tests := []struct {
+		name  string
+		query JobQuery
+		want  error
+	}{
+		{name: "empty", query: JobQuery{}},
+		{
+			name: "all filters",
+			query: JobQuery{
+				JobFilter: JobFilter{
+					Type:        JobTypeHTTP,
+					Status:      JobStatusStopped,
+					Tags:        []string{"billing"},
+					TagMatch:    TagMatchAny,
+					URL:         "example.com",
+					NextRunFrom: null.TimeFrom(now),
+					NextRunTo:   null.TimeFrom(now.Add(time.Hour)),
+				},
+				Sort:   "-next_run",
+				Limit:  10,
+				Offset: 20,
+			},
+		},
+		{name: "cursor", query: JobQuery{Cursor: cursor}},
+		{name: "invalid type", query: JobQuery{JobFilter: JobFilter{Type: "SMTP"}}, want: error2.ErrInvalidJobType},
+		{name: "invalid status", query: JobQuery{JobFilter: JobFilter{Status: "PAUSED"}}, want: error2.ErrInvalidJobStatus},
+		{name: "invalid tag match", query: JobQuery{JobFilter: JobFilter{TagMatch: "none"}}, want: error2.ErrInvalidTagMatch},
+		{
+			name:  "invalid time range",
+			query: JobQuery{JobFilter: JobFilter{CreatedFrom: null.TimeFrom(now), CreatedTo: null.TimeFrom(now)}},
+			want:  error2.ErrInvalidJobTimeRange,
+		},
+		{name: "invalid sort", query: JobQuery{Sort: "id"}, want: error2.ErrInvalidJobSort},
+		{name: "invalid cursor", query: JobQuery{Cursor: "not-a-cursor"}, want: error2.ErrInvalidJobCursor},
+		{name: "cursor of another sort", query: JobQuery{Cursor: cursor, Sort: JobSortCreatedAt}, want: error2.ErrInvalidJobCursor},
+		{name: "cursor with offset", query: JobQuery{Cursor: cursor, Offset: 10}, want: error2.ErrInvalidJobCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.query.Validate(), tt.want)
+		})
+	}
+}
+
+func TestJobCursor(t *testing.T) {
+	job := &Job{ID: uuid.New(), CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)}
+
+	cursor, err := ParseJobCursor(NewJobCursor(DefaultJobSort, job), DefaultJobSort)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, cursor.ID)
+	assert.Equal(t, "2024-05-01T12:00:00.123456Z", cursor.Value)
+
+	// jobs that won't run again are sorted last by next run
+	cursor, err = ParseJobCursor(NewJobCursor(JobSortNextRun, job), JobSortNextRun)
+	require.NoError(t, err)
+	assert.Equal(t, "infinity", cursor.Value)
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// StatsWindow is a period ending now over which execution statistics are computed, as a duration like "15m" or "24h",
+// or a number of days like "7d".
+type StatsWindow string
+
+const (
+	DefaultStatsWindow StatsWindow = "24h"
+
+	// MaxStatsWindow is the longest window statistics can be computed over
+	MaxStatsWindow = 90 * 24 * time.Hour
+
+	// MaxStatsWindows is the maximum number of windows of a single request
+	MaxStatsWindows = 5
+)
+
+// Duration returns the length of the window.
+func (w StatsWindow) Duration() (time.Duration, error) {
+	var (
+		duration time.Duration
+		err      error
+	)
+
+	if days, ok := strings.CutSuffix(string(w), "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		duration, err = time.ParseDuration(string(w))
+	}
+
+	if err != nil || duration <= 0 || duration > MaxStatsWindow {
+		return 0, error2.ErrInvalidStatsWindow
+	}
+
+	return duration, nil
+}
+
+// ValidateStatsWindows validates the windows of a statistics request.
+func ValidateStatsWindows(windows []StatsWindow) error {
+	if len(windows) == 0 || len(windows) > MaxStatsWindows {
+		return error2.ErrInvalidStatsWindow
+	}
+
+	for _, window := range windows {
+		if _, err := window.Duration(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExecutionStats summarizes the executions started within a window. Durations are in milliseconds; statistics that
+// can't be computed without executions are null.
+type ExecutionStats struct {
+	Window StatsWindow `json:"window"`
+	From   time.Time   `json:"from"`
+	To     time.Time   `json:"to"`
+
+	Executions int64 `json:"executions"`
+	Successful int64 `json:"successful"`
+	Failed     int64 `json:"failed"`
+
+	// SuccessRate is the share of successful executions, between 0 and 1
+	SuccessRate null.Float `json:"success_rate" swaggertype:"number"`
+
+	DurationP50 null.Float `json:"duration_p50_ms" swaggertype:"number"`
+	DurationP95 null.Float `json:"duration_p95_ms" swaggertype:"number"`
+	DurationP99 null.Float `json:"duration_p99_ms" swaggertype:"number"`
+
+	// SchedulingLagAvg is the average time between the planned and the actual start of the executions. Executions
+	// recorded before planned starts were tracked are not counted.
+	SchedulingLagAvg null.Float `json:"scheduling_lag_avg_ms" swaggertype:"number"`
+
+	// LongestFailureStreak is the highest number of consecutive failed executions of a job
+	LongestFailureStreak int64 `json:"longest_failure_streak"`
+}
+
+// JobStats holds the execution statistics of a job.
+type JobStats struct {
+	JobID uuid.UUID `json:"job_id"`
+
+	// CurrentFailureStreak is the number of executions that failed since the job last succeeded
+	CurrentFailureStreak int64 `json:"current_failure_streak"`
+
+	Windows []ExecutionStats `json:"windows"`
+}
+
+// NamespaceStats holds the execution statistics of all jobs of a namespace.
+type NamespaceStats struct {
+	Namespace string `json:"namespace"`
+
+	// FailingJobs is the number of jobs whose last execution failed
+	FailingJobs int64 `json:"failing_jobs"`
+
+	Windows []ExecutionStats `json:"windows"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+func TestStatsWindow_Duration(t *testing.T) {
+	tests := []struct {
+		window StatsWindow
+		want   time.Duration
+	}{
+		{window: "15m", want: 15 * time.Minute},
+		{window: DefaultStatsWindow, want: 24 * time.Hour},
+		{window: "7d", want: 7 * 24 * time.Hour},
+		{window: "90d", want: MaxStatsWindow},
+	}
+
+	for _, tt := range tests {
+		duration, err := tt.window.Duration()
+		assert.NoError(t, err, tt.window)
+		assert.Equal(t, tt.want, duration, tt.window)
+	}
+
+	for _, window := range []StatsWindow{"", "0s", "-1h", "91d", "d", "1w", "1.5d"} {
+		_, err := window.Duration()
+		assert.ErrorIs(t, err, error2.ErrInvalidStatsWindow, window)
+	}
+}
+
+func TestValidateStatsWindows(t *testing.T) {
+	assert.NoError(t, ValidateStatsWindows([]StatsWindow{"1h", "24h", "7d"}))
+	assert.ErrorIs(t, ValidateStatsWindows(nil), error2.ErrInvalidStatsWindow)
+	assert.ErrorIs(t, ValidateStatsWindows([]StatsWindow{"1h", "bogus"}), error2.ErrInvalidStatsWindow)
+	assert.ErrorIs(t, ValidateStatsWindows([]StatsWindow{"1h", "2h", "3h", "4h", "5h", "6h"}), error2.ErrInvalidStatsWindow)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
 	"gopkg.in/guregu/null.v4"
@@ -145,6 +146,44 @@ func TestJobValidate(t *testing.T) {
 			},
 			want: error2.ErrInvalidJobSchedule,
 		},
+		{
+			name: "invalid job: empty unique key",
+			job: Job{
+				ID:        uuid.New(),
+				Type:      JobTypeHTTP,
+				Status:    JobStatusRunning,
+				UniqueKey: null.StringFrom(""),
+				ExecuteAt: null.TimeFrom(time.Now().Add(time.Minute)),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				CreatedAt: time.Now(),
+			},
+			want: error2.ErrInvalidJobUniqueKey,
+		},
+		{
+			name: "no allowed failed runs",
+			job: Job{
+				ID:        uuid.New(),
+				Type:      JobTypeHTTP,
+				Status:    JobStatusRunning,
+				ExecuteAt: null.TimeFrom(time.Now().Add(time.Minute)),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				AllowedFailedRuns: lo.ToPtr(0),
+				CreatedAt:         time.Now(),
+			},
+			want: error2.ErrInvalidAllowedFailedRuns,
+		},
 	}
 
 	for _, tc := range tests {
@@ -154,3 +193,44 @@ func TestJobValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestJobApplyMergePatch(t *testing.T) {
+	job := newAuditedJob()
+	job.Version = 3
+	job.CreatedAt = time.Now().Add(-time.Hour)
+
+	patched, err := job.ApplyMergePatch([]byte(`{
+		"id": "00000000-0000-0000-0000-000000000000",
+		"version": 1,
+		"status": "STOPPED",
+		"tags": null,
+		"http_job": {"url": "https://example.com/v2", "headers": {"Content-Type": null}, "auth": {"type": "basic"}}
+	}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, job.ID, patched.ID)
+	assert.Equal(t, job.Namespace, patched.Namespace)
+	assert.Equal(t, int64(3), patched.Version)
+	assert.Equal(t, job.CreatedAt, patched.CreatedAt)
+	assert.Equal(t, JobStatusStopped, patched.Status)
+	assert.Nil(t, patched.Tags)
+	assert.Equal(t, "https://example.com/v2", patched.HTTPJob.URL)
+	assert.Equal(t, "POST", patched.HTTPJob.Method)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer s3cr3t-header"}, patched.HTTPJob.Headers)
+
+	// secrets omitted from the patch are kept
+	assert.Equal(t, "s3cr3t-password", patched.HTTPJob.Auth.Password.String)
+	assert.True(t, patched.NextRun.Valid)
+
+	// the patched job is a copy
+	assert.Equal(t, JobStatusRunning, job.Status)
+
+	_, err = job.ApplyMergePatch([]byte(`{"cron_schedul": "* * * * *"}`))
+	assert.ErrorIs(t, err, error2.ErrInvalidJobPatch)
+
+	_, err = job.ApplyMergePatch([]byte(`{"status": 1}`))
+	assert.ErrorIs(t, err, error2.ErrInvalidJobPatch)
+
+	_, err = job.ApplyMergePatch([]byte(`{`))
+	assert.ErrorIs(t, err, error2.ErrInvalidJobPatch)
+}
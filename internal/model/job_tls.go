@@ -0,0 +1,106 @@
+package model
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+type TLSVersion string
+
+const (
+	TLSVersion10 TLSVersion = "1.0"
+	TLSVersion11 TLSVersion = "1.1"
+	TLSVersion12 TLSVersion = "1.2"
+	TLSVersion13 TLSVersion = "1.3"
+)
+
+func (tv TLSVersion) Valid() bool {
+	switch tv {
+	case "", TLSVersion10, TLSVersion11, TLSVersion12, TLSVersion13:
+		return true
+	default:
+		return false
+	}
+}
+
+// Uint16 returns the crypto/tls version constant. An empty version defaults to TLS 1.2.
+func (tv TLSVersion) Uint16() uint16 {
+	switch tv {
+	case TLSVersion10:
+		return tls.VersionTLS10
+	case TLSVersion11:
+		return tls.VersionTLS11
+	case TLSVersion13:
+		return tls.VersionTLS13
+	default:
+		return tls.VersionTLS12
+	}
+}
+
+// TLSConfig holds per-job TLS settings used when calling the job's URL.
+// Jobs with the same TLSConfig share a transport on the runner.
+type TLSConfig struct {
+	ClientCert null.String `json:"client_cert,omitempty" swaggertype:"string"` // PEM encoded client certificate, stored encrypted
+	ClientKey  null.String `json:"client_key,omitempty" swaggertype:"string"`  // PEM encoded client private key, stored encrypted
+	CABundle   string      `json:"ca_bundle,omitempty"`                        // PEM encoded CA certificates used to verify the server
+	ServerName string      `json:"server_name,omitempty"`                      // overrides the server name used for verification and SNI
+	MinVersion TLSVersion  `json:"min_version,omitempty"`                      // e.g., "1.2", "1.3"
+}
+
+// Validate validates a TLSConfig struct.
+func (t *TLSConfig) Validate() error {
+	if !t.MinVersion.Valid() {
+		return error2.ErrInvalidTLSMinVersion
+	}
+
+	hasCert := t.ClientCert.Valid && t.ClientCert.String != ""
+	hasKey := t.ClientKey.Valid && t.ClientKey.String != ""
+	if hasCert != hasKey {
+		return error2.ErrIncompleteTLSClientKeyPair
+	}
+
+	if hasCert {
+		if _, err := tls.X509KeyPair([]byte(t.ClientCert.String), []byte(t.ClientKey.String)); err != nil {
+			return error2.ErrInvalidTLSClientKeyPair
+		}
+	}
+
+	if t.CABundle != "" {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(t.CABundle)) {
+			return error2.ErrInvalidTLSCABundle
+		}
+	}
+
+	return nil
+}
+
+// ToTLS builds a crypto/tls configuration from the job's TLS settings.
+func (t *TLSConfig) ToTLS() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName: t.ServerName,
+		MinVersion: t.MinVersion.Uint16(),
+	}
+
+	if t.ClientCert.Valid && t.ClientKey.Valid {
+		certificate, err := tls.X509KeyPair([]byte(t.ClientCert.String), []byte(t.ClientKey.String))
+		if err != nil {
+			return nil, error2.ErrInvalidTLSClientKeyPair
+		}
+
+		cfg.Certificates = []tls.Certificate{certificate}
+	}
+
+	if t.CABundle != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(t.CABundle)) {
+			return nil, error2.ErrInvalidTLSCABundle
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
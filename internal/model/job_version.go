@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// JobVersion is a revision of a job definition. A version is stored whenever a job is created, updated or rolled back.
+//
+// swagger:model JobVersion
+type JobVersion struct {
+	JobID   uuid.UUID `json:"job_id"`
+	Version int64     `json:"version"`
+	Type    JobType   `json:"type"`
+
+	ExecuteAt    null.Time   `json:"execute_at" swaggertype:"string"`
+	CronSchedule null.String `json:"cron_schedule" swaggertype:"string"`
+
+	HTTPJob *HTTPJob `json:"http_job,omitempty"`
+	AMQPJob *AMQPJob `json:"amqp_job,omitempty"`
+
+	Tags []string `json:"tags"`
+
+	// when the version was stored
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RemoveCredentials removes sensitive information from the version, when returning it to the user.
+func (v *JobVersion) RemoveCredentials() {
+	if v.HTTPJob != nil {
+		v.HTTPJob.RemoveCredentials()
+	}
+
+	if v.AMQPJob != nil {
+		v.AMQPJob.RemoveCredentials()
+	}
+}
+
+// ApplyVersion restores the definition of the job to the given version.
+func (j *Job) ApplyVersion(version *JobVersion) {
+	j.Type = version.Type
+	j.ExecuteAt = version.ExecuteAt
+	j.CronSchedule = version.CronSchedule
+	j.HTTPJob = version.HTTPJob
+	j.AMQPJob = version.AMQPJob
+	j.Tags = version.Tags
+
+	j.UpdatedAt = time.Now()
+
+	j.SetInitialRunTime()
+}
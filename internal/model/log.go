@@ -0,0 +1,300 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces secrets in log output.
+const Redacted = "[REDACTED]"
+
+// DefaultSensitiveHeaders are the HTTP and AMQP headers whose values are redacted from logs by default.
+var DefaultSensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+var (
+	sensitiveHeadersMu sync.RWMutex
+	sensitiveHeaders   = toHeaderSet(DefaultSensitiveHeaders)
+)
+
+// SetSensitiveHeaders replaces the list of headers whose values are redacted from logs. Header names are case-insensitive.
+func SetSensitiveHeaders(headers []string) {
+	sensitiveHeadersMu.Lock()
+	defer sensitiveHeadersMu.Unlock()
+
+	sensitiveHeaders = toHeaderSet(headers)
+}
+
+func toHeaderSet(headers []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(headers))
+	for _, header := range headers {
+		set[strings.ToLower(header)] = struct{}{}
+	}
+
+	return set
+}
+
+func isSensitiveHeader(header string) bool {
+	sensitiveHeadersMu.RLock()
+	defer sensitiveHeadersMu.RUnlock()
+
+	_, ok := sensitiveHeaders[strings.ToLower(header)]
+	return ok
+}
+
+// redactURL removes the password from the URL. Unparseable URLs are redacted entirely.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Redacted
+	}
+
+	return parsed.Redacted()
+}
+
+// MarshalLogObject logs the job without any secrets.
+func (j *Job) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", j.ID.String())
+	enc.AddString("type", string(j.Type))
+	enc.AddString("status", string(j.Status))
+
+	if j.ExecuteAt.Valid {
+		enc.AddTime("execute_at", j.ExecuteAt.Time)
+	}
+
+	if j.CronSchedule.Valid {
+		enc.AddString("cron_schedule", j.CronSchedule.String)
+	}
+
+	if j.HTTPJob != nil {
+		if err := enc.AddObject("http_job", j.HTTPJob); err != nil {
+			return err
+		}
+	}
+
+	if j.AMQPJob != nil {
+		if err := enc.AddObject("amqp_job", j.AMQPJob); err != nil {
+			return err
+		}
+	}
+
+	if j.NextRun.Valid {
+		enc.AddTime("next_run", j.NextRun.Time)
+	}
+
+	return enc.AddArray("tags", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, tag := range j.Tags {
+			arr.AppendString(tag)
+		}
+		return nil
+	}))
+}
+
+// MarshalLogObject logs the job create request without any secrets.
+func (j *JobCreate) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("type", string(j.Type))
+
+	if j.ExecuteAt.Valid {
+		enc.AddTime("execute_at", j.ExecuteAt.Time)
+	}
+
+	if j.CronSchedule.Valid {
+		enc.AddString("cron_schedule", j.CronSchedule.String)
+	}
+
+	if j.HTTPJob != nil {
+		if err := enc.AddObject("http_job", j.HTTPJob); err != nil {
+			return err
+		}
+	}
+
+	if j.AMQPJob != nil {
+		if err := enc.AddObject("amqp_job", j.AMQPJob); err != nil {
+			return err
+		}
+	}
+
+	return enc.AddArray("tags", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, tag := range j.Tags {
+			arr.AppendString(tag)
+		}
+		return nil
+	}))
+}
+
+// MarshalLogObject logs the job update request without any secrets.
+func (u *JobUpdate) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if u.Type != nil {
+		enc.AddString("type", string(*u.Type))
+	}
+
+	if u.CronSchedule != nil {
+		enc.AddString("cron_schedule", *u.CronSchedule)
+	}
+
+	if u.ExecuteAt != nil {
+		enc.AddTime("execute_at", *u.ExecuteAt)
+	}
+
+	if u.HTTP != nil {
+		if err := enc.AddObject("http", u.HTTP); err != nil {
+			return err
+		}
+	}
+
+	if u.AMQP != nil {
+		if err := enc.AddObject("amqp", u.AMQP); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarshalLogObject logs the HTTP job without credentials. The body is omitted, as it may contain secrets.
+func (httpJob *HTTPJob) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("url", redactURL(httpJob.URL))
+	enc.AddString("method", httpJob.Method)
+
+	err := enc.AddObject("headers", zapcore.ObjectMarshalerFunc(func(headers zapcore.ObjectEncoder) error {
+		for name, value := range httpJob.Headers {
+			if isSensitiveHeader(name) {
+				value = Redacted
+			}
+			headers.AddString(name, value)
+		}
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	enc.AddInt("body_length", len(httpJob.Body.ValueOrZero()))
+
+	err = enc.AddArray("valid_response_codes", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, code := range httpJob.ValidResponseCodes {
+			arr.AppendInt(code)
+		}
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	if httpJob.TLS != nil {
+		enc.AddBool("tls_client_cert", httpJob.TLS.ClientCert.Valid)
+		enc.AddBool("tls_ca_bundle", httpJob.TLS.CABundle != "")
+	}
+
+	return enc.AddObject("auth", &httpJob.Auth)
+}
+
+// MarshalLogObject logs the auth type and non-secret settings. Secrets are replaced with a placeholder if they are set.
+func (auth *Auth) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("type", string(auth.Type))
+
+	if auth.Credential.Valid {
+		enc.AddString("credential", auth.Credential.String)
+	}
+
+	if auth.Username.Valid {
+		enc.AddString("username", auth.Username.String)
+	}
+
+	if auth.Password.Valid {
+		enc.AddString("password", Redacted)
+	}
+
+	if auth.BearerToken.Valid {
+		enc.AddString("bearer_token", Redacted)
+	}
+
+	if auth.HMACSecret.Valid {
+		enc.AddString("hmac_secret", Redacted)
+	}
+
+	if auth.HMACScheme != "" {
+		enc.AddString("hmac_scheme", string(auth.HMACScheme))
+	}
+
+	if auth.OAuth2 != nil {
+		enc.AddString("oauth2_token_url", auth.OAuth2.TokenURL)
+		enc.AddString("oauth2_client_id", auth.OAuth2.ClientID)
+		if auth.OAuth2.ClientSecret.Valid {
+			enc.AddString("oauth2_client_secret", Redacted)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLogObject logs the AMQP job without the connection credentials. The body is omitted, as it may contain secrets.
+func (amqpJob *AMQPJob) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if amqpJob.ConnectionCredential != nil {
+		enc.AddString("connection_credential", *amqpJob.ConnectionCredential)
+	} else {
+		enc.AddString("connection", redactURL(amqpJob.Connection))
+	}
+
+	enc.AddString("exchange", amqpJob.Exchange)
+	enc.AddString("routing_key", amqpJob.RoutingKey)
+
+	err := enc.AddObject("headers", zapcore.ObjectMarshalerFunc(func(headers zapcore.ObjectEncoder) error {
+		for name, value := range amqpJob.Headers {
+			if isSensitiveHeader(name) {
+				headers.AddString(name, Redacted)
+				continue
+			}
+
+			if err := headers.AddReflected(name, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	enc.AddInt("body_length", len(amqpJob.Body))
+	enc.AddString("content_type", amqpJob.ContentType)
+
+	return nil
+}
+
+// MarshalLogObject logs the credential without its secrets.
+func (c *Credential) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", c.Name)
+	enc.AddString("type", string(c.Type))
+
+	if c.Username.Valid {
+		enc.AddString("username", c.Username.String)
+	}
+
+	if c.Password.Valid {
+		enc.AddString("password", Redacted)
+	}
+
+	if c.BearerToken.Valid {
+		enc.AddString("bearer_token", Redacted)
+	}
+
+	if c.HMACSecret.Valid {
+		enc.AddString("hmac_secret", Redacted)
+	}
+
+	if c.Connection.Valid {
+		enc.AddString("connection", redactURL(c.Connection.String))
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"regexp"
+	"time"
+
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// DefaultNamespace is the namespace of clients that are not assigned to a namespace.
+const DefaultNamespace = "default"
+
+// namespaceRegex allows DNS labels, e.g. "billing" or "team-a".
+var namespaceRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ValidateNamespace checks that the name is a valid namespace name.
+func ValidateNamespace(name string) error {
+	if !namespaceRegex.MatchString(name) {
+		return error2.ErrInvalidNamespace
+	}
+
+	return nil
+}
+
+// Namespace isolates the jobs, credentials and API keys of a tenant. Namespaces without quotas are unlimited.
+//
+// swagger:model Namespace
+type Namespace struct {
+	Name string `json:"name"`
+
+	NamespaceQuota
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NamespaceQuota limits the resources a namespace can use. Null limits are unlimited.
+//
+// swagger:model NamespaceQuota
+type NamespaceQuota struct {
+	// MaxJobs limits the number of jobs in the namespace
+	MaxJobs null.Int `json:"max_jobs" swaggertype:"integer"`
+	// MaxExecutionsPerMinute limits how many jobs of the namespace are started per minute
+	MaxExecutionsPerMinute null.Int `json:"max_executions_per_minute" swaggertype:"integer"`
+}
+
+func (q *NamespaceQuota) Validate() error {
+	if (q.MaxJobs.Valid && q.MaxJobs.Int64 < 0) || (q.MaxExecutionsPerMinute.Valid && q.MaxExecutionsPerMinute.Int64 < 0) {
+		return error2.ErrInvalidNamespaceQuota
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestValidateNamespace(t *testing.T) {
+	for _, name := range []string{DefaultNamespace, "team-a", "a", "billing2", strings.Repeat("a", 63)} {
+		assert.NoError(t, ValidateNamespace(name), name)
+	}
+
+	for _, name := range []string{"", "Team-A", "-team", "team-", "team_a", "team.a", strings.Repeat("a", 64)} {
+		assert.ErrorIs(t, ValidateNamespace(name), error2.ErrInvalidNamespace, name)
+	}
+}
+
+func TestNamespaceQuota_Validate(t *testing.T) {
+	assert.NoError(t, (&NamespaceQuota{}).Validate())
+	assert.NoError(t, (&NamespaceQuota{MaxJobs: null.IntFrom(0), MaxExecutionsPerMinute: null.IntFrom(10)}).Validate())
+	assert.ErrorIs(t, (&NamespaceQuota{MaxJobs: null.IntFrom(-1)}).Validate(), error2.ErrInvalidNamespaceQuota)
+	assert.ErrorIs(t, (&NamespaceQuota{MaxExecutionsPerMinute: null.IntFrom(-1)}).Validate(), error2.ErrInvalidNamespaceQuota)
+}
@@ -0,0 +1,47 @@
+package model
+
+// Role grants access to the API. Each role includes the permissions of the roles below it.
+type Role string
+
+const (
+	// RoleViewer can read jobs and their executions, without error messages.
+	RoleViewer Role = "viewer"
+	// RoleOperator can additionally create and update jobs and read credentials.
+	RoleOperator Role = "operator"
+	// RoleAdmin can do everything, including deleting jobs and managing credentials and API keys.
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func (r Role) Valid() bool {
+	return r.rank() > 0
+}
+
+// Includes reports whether the role has at least the permissions of the other role.
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && r.rank() >= other.rank()
+}
+
+// HighestRole returns the role with the most permissions, or an empty role if none of the roles are valid.
+func HighestRole(roles ...Role) Role {
+	var highest Role
+	for _, role := range roles {
+		if role.rank() > highest.rank() {
+			highest = role
+		}
+	}
+
+	return highest
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRole_Includes(t *testing.T) {
+	tests := []struct {
+		role     Role
+		other    Role
+		includes bool
+	}{
+		{role: RoleAdmin, other: RoleAdmin, includes: true},
+		{role: RoleAdmin, other: RoleViewer, includes: true},
+		{role: RoleOperator, other: RoleViewer, includes: true},
+		{role: RoleOperator, other: RoleAdmin, includes: false},
+		{role: RoleViewer, other: RoleOperator, includes: false},
+		{role: "", other: RoleViewer, includes: false},
+		{role: "superuser", other: RoleViewer, includes: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.includes, tt.role.Includes(tt.other), "%s includes %s", tt.role, tt.other)
+	}
+}
+
+func TestHighestRole(t *testing.T) {
+	assert.Equal(t, RoleAdmin, HighestRole(RoleViewer, RoleAdmin, RoleOperator))
+	assert.Equal(t, RoleViewer, HighestRole("superuser", RoleViewer))
+	assert.Equal(t, Role(""), HighestRole())
+}
@@ -0,0 +1,213 @@
+package model
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// WebhookEventType is the kind of event delivered to webhook subscriptions.
+type WebhookEventType string
+
+const (
+	WebhookEventJobCreated      WebhookEventType = "job.created"
+	WebhookEventJobUpdated      WebhookEventType = "job.updated"
+	WebhookEventJobDeleted      WebhookEventType = "job.deleted"
+	WebhookEventExecutionFailed WebhookEventType = "execution.failed"
+
+	// WebhookEventJobAutoStopped is emitted when the scheduler stops a job that failed more times in a row than it
+	// allows
+	WebhookEventJobAutoStopped WebhookEventType = "job.auto_stopped"
+)
+
+func (t WebhookEventType) Valid() bool {
+	switch t {
+	case WebhookEventJobCreated, WebhookEventJobUpdated, WebhookEventJobDeleted, WebhookEventExecutionFailed,
+		WebhookEventJobAutoStopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// WebhookSubscription delivers the events of its namespace with one of its event types to its URL. Deliveries are
+// signed with the subscription's secret, which is only returned when the subscription is created or its secret is
+// rotated.
+//
+// swagger:model WebhookSubscription
+type WebhookSubscription struct {
+	ID        uuid.UUID          `json:"id"`
+	Namespace string             `json:"namespace"`
+	URL       string             `json:"url"`
+	Events    []WebhookEventType `json:"events"`
+	Enabled   bool               `json:"enabled"`
+	Secret    null.String        `json:"secret,omitempty" swaggertype:"string"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// swagger:model WebhookSubscriptionCreate
+type WebhookSubscriptionCreate struct {
+	URL    string             `json:"url"`
+	Events []WebhookEventType `json:"events"`
+
+	// Enabled defaults to true
+	Enabled null.Bool `json:"enabled" swaggertype:"boolean"`
+}
+
+// swagger:model WebhookSubscriptionUpdate
+type WebhookSubscriptionUpdate struct {
+	URL     null.String        `json:"url" swaggertype:"string"`
+	Events  []WebhookEventType `json:"events,omitempty"`
+	Enabled null.Bool          `json:"enabled" swaggertype:"boolean"`
+
+	// RotateSecret replaces the signing secret; the new secret is returned in the response
+	RotateSecret bool `json:"rotate_secret"`
+}
+
+// ToSubscription converts the create request to a new subscription of the namespace.
+func (c *WebhookSubscriptionCreate) ToSubscription(namespace string) *WebhookSubscription {
+	now := time.Now()
+
+	return &WebhookSubscription{
+		ID:        uuid.New(),
+		Namespace: namespace,
+		URL:       c.URL,
+		Events:    c.Events,
+		Enabled:   c.Enabled.ValueOrZero() || !c.Enabled.Valid,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// ApplyUpdate applies the set fields of the update to the subscription.
+func (s *WebhookSubscription) ApplyUpdate(update WebhookSubscriptionUpdate) {
+	if update.URL.Valid {
+		s.URL = update.URL.String
+	}
+
+	if update.Events != nil {
+		s.Events = update.Events
+	}
+
+	if update.Enabled.Valid {
+		s.Enabled = update.Enabled.Bool
+	}
+
+	s.UpdatedAt = time.Now()
+}
+
+// Validate validates a WebhookSubscription struct.
+func (s *WebhookSubscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return error2.ErrInvalidWebhookURL
+	}
+
+	if len(s.Events) == 0 {
+		return error2.ErrInvalidWebhookEvents
+	}
+
+	seen := map[WebhookEventType]struct{}{}
+	for _, event := range s.Events {
+		if _, ok := seen[event]; ok || !event.Valid() {
+			return error2.ErrInvalidWebhookEvents
+		}
+		seen[event] = struct{}{}
+	}
+
+	return nil
+}
+
+// RemoveSecret removes the signing secret from the subscription, when returning it to the user.
+func (s *WebhookSubscription) RemoveSecret() {
+	s.Secret = null.String{}
+}
+
+// WebhookEvent is written to the outbox in the transaction of the change it announces, and delivered to the
+// subscriptions of its namespace afterwards. It is the body of every delivery.
+//
+// swagger:model WebhookEvent
+type WebhookEvent struct {
+	ID        int64            `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	Namespace string           `json:"namespace"`
+	JobID     uuid.UUID        `json:"job_id"`
+
+	// Data is a WebhookJobData for job events and a JobExecution for execution events
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookJobData describes the job of a job event. It never contains the job's secrets; the changes are redacted
+// like in the audit log.
+type WebhookJobData struct {
+	ID      uuid.UUID `json:"id"`
+	Type    JobType   `json:"type"`
+	Status  JobStatus `json:"status"`
+	Version int64     `json:"version"`
+	Tags    []string  `json:"tags"`
+
+	Actor     string                 `json:"actor,omitempty"`
+	RequestID null.String            `json:"request_id,omitempty" swaggertype:"string"`
+	Changes   map[string]AuditChange `json:"changes,omitempty"`
+}
+
+// NewWebhookJobData returns the data of a job event, with the actor and changes of the audit entry, if set.
+func NewWebhookJobData(job *Job, audit *JobAudit) WebhookJobData {
+	data := WebhookJobData{
+		ID:      job.ID,
+		Type:    job.Type,
+		Status:  job.Status,
+		Version: job.Version,
+		Tags:    job.Tags,
+	}
+
+	if audit != nil {
+		data.Actor = audit.Actor
+		data.RequestID = audit.RequestID
+		data.Changes = audit.Changes
+	}
+
+	return data
+}
+
+// WebhookDeliveryStatus is the state of a delivery of an event to a subscription.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is the delivery of an event to a subscription. Failed attempts are retried with a backoff until
+// the delivery succeeds or runs out of attempts.
+//
+// swagger:model WebhookDelivery
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	SubscriptionID uuid.UUID             `json:"subscription_id"`
+	Event          WebhookEvent          `json:"event"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+
+	// NextAttemptAt is when a pending delivery is attempted next
+	NextAttemptAt  null.Time   `json:"next_attempt_at" swaggertype:"string"`
+	LastStatusCode null.Int    `json:"last_status_code" swaggertype:"integer"`
+	LastError      null.String `json:"last_error" swaggertype:"string"`
+	DeliveredAt    null.Time   `json:"delivered_at" swaggertype:"string"`
+
+	// URL and secret of the subscription, used to send and sign the delivery
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+// Package audit carries the request metadata recorded in the audit log through the request context.
+package audit
+
+import "context"
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+)
+
+// SystemActor is the actor of changes that are not made through the API.
+const SystemActor = "system"
+
+// WithActor returns a context carrying the actor making the request, e.g. "api_key:dsk_k3j9x2mq".
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns the actor of the request, or SystemActor if the context carries none.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+
+	return SystemActor
+}
+
+// WithRequestID returns a context carrying the ID of the request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the ID of the request, or an empty string if the context carries none.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnknownSigningKey = errors.New("token is signed with an unknown key")
+
+// KeySet provides the public keys tokens are verified with.
+type KeySet interface {
+	// Key returns the key with the given key ID. If the key ID is empty and the set holds a single key, that key is returned.
+	Key(ctx context.Context, keyID string) (crypto.PublicKey, error)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// parseJWKS parses the signing keys of a JSON Web Key Set. Keys that are not RSA or EC signing keys are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	set := jsonWebKeySet{}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrap(err, "failed to parse JWKS")
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		var (
+			publicKey crypto.PublicKey
+			err       error
+		)
+
+		switch key.Kty {
+		case "RSA":
+			publicKey, err = key.rsaPublicKey()
+		case "EC":
+			publicKey, err = key.ecdsaPublicKey()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JWK %q", key.Kid)
+		}
+
+		keys[key.Kid] = publicKey
+	}
+
+	return keys, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(decoded), nil
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Sign() <= 0 || !e.IsInt64() || e.Int64() <= 1 {
+		return nil, errors.New("invalid RSA key")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("invalid EC key")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func lookupKey(keys map[string]crypto.PublicKey, keyID string) (crypto.PublicKey, bool) {
+	if keyID == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+
+	key, ok := keys[keyID]
+	return key, ok
+}
+
+// StaticKeySet is a key set read once, e.g. from a local JWKS file for tests.
+type StaticKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// NewStaticKeySetFromFile reads a JWKS file.
+func NewStaticKeySetFromFile(path string) (*StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read JWKS file")
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StaticKeySet{keys: keys}, nil
+}
+
+func (s *StaticKeySet) Key(_ context.Context, keyID string) (crypto.PublicKey, error) {
+	key, ok := lookupKey(s.keys, keyID)
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+
+	return key, nil
+}
+
+const (
+	// jwksCacheTTL is how long fetched keys are used before they are fetched again.
+	jwksCacheTTL = time.Hour
+
+	// jwksMinRefreshInterval limits how often tokens with unknown key IDs can trigger a fetch.
+	jwksMinRefreshInterval = time.Minute
+)
+
+// RemoteKeySet fetches keys from the JWKS endpoint of the identity provider and caches them.
+// Keys are fetched again when a token is signed with an unknown key, so key rotation is picked up.
+type RemoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func NewRemoteKeySet(url string, client *http.Client) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:    url,
+		client: client,
+	}
+}
+
+func (s *RemoteKeySet) Key(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.fetchedAt) < jwksCacheTTL {
+		if key, ok := lookupKey(s.keys, keyID); ok {
+			return key, nil
+		}
+	}
+
+	if time.Since(s.attemptedAt) < jwksMinRefreshInterval {
+		return nil, ErrUnknownSigningKey
+	}
+
+	s.attemptedAt = time.Now()
+	if err := s.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	key, ok := lookupKey(s.keys, keyID)
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+
+	return key, nil
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch JWKS")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return errors.Wrap(err, "failed to read JWKS")
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	s.keys = keys
+	s.fetchedAt = time.Now()
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// Package auth validates the identities of API clients.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+	MethodNone   = "none"
+)
+
+// Identity is the authenticated client of a request.
+type Identity struct {
+	Subject   string
+	Namespace string
+	Role      model.Role
+	Method    string
+}
+
+// Actor identifies the client in the audit log, e.g. "api_key:dsk_k3j9x2mq" or "jwt:alice".
+func (i *Identity) Actor() string {
+	return i.Method + ":" + i.Subject
+}
+
+// JWTConfig configures the validation of JWT bearer tokens issued by an OIDC provider.
+type JWTConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+
+	// JWKSURL is the JWKS endpoint of the identity provider, e.g. https://sso.example.com/.well-known/jwks.json
+	JWKSURL string `mapstructure:"jwksUrl" yaml:"jwksUrl" json:"jwksUrl,omitempty"`
+	// JWKSFile is a local JWKS file used instead of JWKSURL, e.g. for tests
+	JWKSFile string `mapstructure:"jwksFile" yaml:"jwksFile" json:"jwksFile,omitempty"`
+
+	Issuer   string `mapstructure:"issuer" yaml:"issuer" json:"issuer,omitempty"`
+	Audience string `mapstructure:"audience" yaml:"audience" json:"audience,omitempty"`
+
+	// RolesClaim is the (dot separated) path of the claim holding the roles, e.g. "realm_access.roles". Defaults to "roles".
+	RolesClaim string `mapstructure:"rolesClaim" yaml:"rolesClaim" json:"rolesClaim,omitempty"`
+	// RoleMapping maps claim values (e.g. SSO groups) to roles, case-insensitively. Claim values named after a role map to that role.
+	RoleMapping map[string]model.Role `mapstructure:"roleMapping" yaml:"roleMapping" json:"roleMapping,omitempty"`
+
+	// NamespaceClaim is the (dot separated) path of the claim holding the namespace of the subject. Tokens without
+	// the claim are assigned to the default namespace. Defaults to "namespace".
+	NamespaceClaim string `mapstructure:"namespaceClaim" yaml:"namespaceClaim" json:"namespaceClaim,omitempty"`
+}
+
+var validSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// clockSkew is the tolerated clock difference between the scheduler and the identity provider.
+const clockSkew = 30 * time.Second
+
+// JWTValidator validates JWT bearer tokens and maps their claims to roles.
+type JWTValidator struct {
+	keys           KeySet
+	issuer         string
+	audience       string
+	rolesClaim     []string
+	roleMapping    map[string]model.Role
+	namespaceClaim []string
+}
+
+// NewJWTValidator creates a validator from the configuration.
+func NewJWTValidator(cfg JWTConfig) (*JWTValidator, error) {
+	var keys KeySet
+	switch {
+	case cfg.JWKSFile != "":
+		staticKeys, err := NewStaticKeySetFromFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = staticKeys
+	case cfg.JWKSURL != "":
+		keys = NewRemoteKeySet(cfg.JWKSURL, &http.Client{Timeout: 10 * time.Second})
+	default:
+		return nil, errors.New("either auth.jwt.jwksUrl or auth.jwt.jwksFile must be set")
+	}
+
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, errors.New("auth.jwt.issuer and auth.jwt.audience must be set")
+	}
+
+	// Viper lower-cases map keys, so claim values are matched case-insensitively
+	roleMapping := make(map[string]model.Role, len(cfg.RoleMapping))
+	for value, role := range cfg.RoleMapping {
+		if !role.Valid() {
+			return nil, fmt.Errorf("role mapping %q: %w", value, error2.ErrInvalidRole)
+		}
+
+		roleMapping[strings.ToLower(value)] = role
+	}
+
+	rolesClaim := cfg.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = "roles"
+	}
+
+	namespaceClaim := cfg.NamespaceClaim
+	if namespaceClaim == "" {
+		namespaceClaim = "namespace"
+	}
+
+	return &JWTValidator{
+		keys:           keys,
+		issuer:         cfg.Issuer,
+		audience:       cfg.Audience,
+		rolesClaim:     strings.Split(rolesClaim, "."),
+		roleMapping:    roleMapping,
+		namespaceClaim: strings.Split(namespaceClaim, "."),
+	}, nil
+}
+
+// Validate verifies the token signature, issuer, audience and expiry, and returns the identity of the token subject.
+func (v *JWTValidator) Validate(ctx context.Context, token string) (*Identity, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, keyID)
+	},
+		jwt.WithValidMethods(validSigningMethods),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", error2.ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", error2.ErrInvalidToken)
+	}
+
+	namespace := model.DefaultNamespace
+	if claim, ok := lookupClaim(claims, v.namespaceClaim).(string); ok && claim != "" {
+		namespace = claim
+	}
+
+	if err := model.ValidateNamespace(namespace); err != nil {
+		return nil, fmt.Errorf("%w: %w", error2.ErrInvalidToken, err)
+	}
+
+	return &Identity{
+		Subject:   subject,
+		Namespace: namespace,
+		Role:      v.role(claims),
+		Method:    MethodJWT,
+	}, nil
+}
+
+// lookupClaim returns the value of the claim at the given path, or nil if the claim doesn't exist.
+func lookupClaim(claims jwt.MapClaims, path []string) interface{} {
+	var value interface{} = map[string]interface{}(claims)
+	for _, key := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+
+	return value
+}
+
+// role returns the highest role granted by the roles claim.
+func (v *JWTValidator) role(claims jwt.MapClaims) model.Role {
+	var values []string
+	switch claim := lookupClaim(claims, v.rolesClaim).(type) {
+	case string:
+		values = strings.Fields(claim)
+	case []interface{}:
+		for _, item := range claim {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+
+	roles := make([]model.Role, 0, len(values))
+	for _, value := range values {
+		if role, ok := v.roleMapping[strings.ToLower(value)]; ok {
+			roles = append(roles, role)
+			continue
+		}
+
+		roles = append(roles, model.Role(value))
+	}
+
+	return model.HighestRole(roles...)
+}
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "scheduler"
+)
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+// writeJWKS writes a JWKS file with the public keys of the given RSA and EC keys.
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	set := jsonWebKeySet{Keys: []jsonWebKey{
+		{Kty: "RSA", Kid: "rsa-1", Use: "sig", N: encodeBigInt(rsaKey.N), E: encodeBigInt(big.NewInt(int64(rsaKey.E)))},
+		{Kty: "EC", Kid: "ec-1", Crv: "P-256", X: encodeBigInt(ecKey.X), Y: encodeBigInt(ecKey.Y)},
+		{Kty: "RSA", Kid: "enc-1", Use: "enc", N: encodeBigInt(rsaKey.N), E: "AQAB"},
+	}}
+
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, keyID string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = keyID
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func validClaims(roles interface{}) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "alice",
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	}
+}
+
+func TestJWTValidator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	validator, err := NewJWTValidator(JWTConfig{
+		Enabled:     true,
+		JWKSFile:    writeJWKS(t, rsaKey, ecKey),
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		RoleMapping: map[string]model.Role{"Scheduler-Admins": model.RoleAdmin},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	t.Run("roles", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			roles interface{}
+			role  model.Role
+		}{
+			{name: "single role", roles: "operator", role: model.RoleOperator},
+			{name: "highest role wins", roles: []interface{}{"viewer", "operator"}, role: model.RoleOperator},
+			{name: "mapped role", roles: []interface{}{"viewer", "scheduler-admins"}, role: model.RoleAdmin},
+			{name: "unknown role", roles: []interface{}{"superuser"}, role: ""},
+			{name: "no roles", roles: nil, role: ""},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				token := signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims(tt.roles))
+
+				identity, err := validator.Validate(ctx, token)
+				require.NoError(t, err)
+				assert.Equal(t, "alice", identity.Subject)
+				assert.Equal(t, MethodJWT, identity.Method)
+				assert.Equal(t, model.DefaultNamespace, identity.Namespace)
+				assert.Equal(t, tt.role, identity.Role)
+			})
+		}
+	})
+
+	t.Run("EC key", func(t *testing.T) {
+		token := signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, validClaims("viewer"))
+
+		identity, err := validator.Validate(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, model.RoleViewer, identity.Role)
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		claims := validClaims("viewer")
+		claims["namespace"] = "team-a"
+
+		identity, err := validator.Validate(ctx, signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims))
+		require.NoError(t, err)
+		assert.Equal(t, "team-a", identity.Namespace)
+
+		claims["namespace"] = "Team A"
+		_, err = validator.Validate(ctx, signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims))
+		assert.ErrorIs(t, err, error2.ErrInvalidToken)
+	})
+
+	t.Run("invalid tokens", func(t *testing.T) {
+		expired := validClaims("admin")
+		expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+		wrongIssuer := validClaims("admin")
+		wrongIssuer["iss"] = "https://evil.example.com"
+
+		wrongAudience := validClaims("admin")
+		wrongAudience["aud"] = "other"
+
+		noExpiry := validClaims("admin")
+		delete(noExpiry, "exp")
+
+		noSubject := validClaims("admin")
+		delete(noSubject, "sub")
+
+		tests := []struct {
+			name  string
+			token string
+		}{
+			{name: "expired", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, expired)},
+			{name: "wrong issuer", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, wrongIssuer)},
+			{name: "wrong audience", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, wrongAudience)},
+			{name: "no expiry", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, noExpiry)},
+			{name: "no subject", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, noSubject)},
+			{name: "unknown key", token: signToken(t, jwt.SigningMethodRS256, "rsa-2", rsaKey, validClaims("admin"))},
+			{name: "encryption key", token: signToken(t, jwt.SigningMethodRS256, "enc-1", rsaKey, validClaims("admin"))},
+			{name: "wrong signature", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", otherKey, validClaims("admin"))},
+			{name: "symmetric algorithm", token: signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), validClaims("admin"))},
+			{name: "malformed", token: "not-a-token"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := validator.Validate(ctx, tt.token)
+				assert.ErrorIs(t, err, error2.ErrInvalidToken)
+			})
+		}
+	})
+}
+
+func TestJWTValidatorRolesClaim(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	validator, err := NewJWTValidator(JWTConfig{
+		JWKSFile:   writeJWKS(t, rsaKey, ecKey),
+		Issuer:     testIssuer,
+		Audience:   testAudience,
+		RolesClaim: "realm_access.roles",
+	})
+	require.NoError(t, err)
+
+	claims := validClaims(nil)
+	claims["realm_access"] = map[string]interface{}{"roles": []interface{}{"admin"}}
+
+	identity, err := validator.Validate(context.Background(), signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims))
+	require.NoError(t, err)
+	assert.Equal(t, model.RoleAdmin, identity.Role)
+}
+
+func TestNewJWTValidator(t *testing.T) {
+	_, err := NewJWTValidator(JWTConfig{Issuer: testIssuer, Audience: testAudience})
+	assert.Error(t, err)
+
+	_, err = NewJWTValidator(JWTConfig{JWKSURL: "https://sso.example.com/jwks", Audience: testAudience})
+	assert.Error(t, err)
+
+	_, err = NewJWTValidator(JWTConfig{
+		JWKSURL:     "https://sso.example.com/jwks",
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		RoleMapping: map[string]model.Role{"group": "superuser"},
+	})
+	assert.ErrorIs(t, err, error2.ErrInvalidRole)
+}
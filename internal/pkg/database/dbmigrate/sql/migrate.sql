@@ -70,4 +70,278 @@ CREATE INDEX job_executions_start_time_index ON job_executions (start_time);
 -- Version: 1.02
 -- Description: Add tags column to jobs table
 
-ALTER TABLE jobs ADD tags TEXT[];
\ No newline at end of file
+ALTER TABLE jobs ADD tags TEXT[];
+
+-- Version: 1.03
+-- Description: Add credentials table for named credentials referenced by jobs
+CREATE TYPE credential_type_enum AS ENUM (
+    'basic',
+    'bearer',
+    'hmac',
+    'amqp_connection'
+);
+
+CREATE TABLE credentials (
+    name VARCHAR(255) PRIMARY KEY,
+    type credential_type_enum NOT NULL,
+
+    -- encrypted secrets
+    data JSONB NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Version: 1.04
+-- Description: Add encryption canary used to detect encryption key mismatches between instances
+CREATE TABLE encryption_canary (
+    id INT PRIMARY KEY CHECK (id = 1),
+    ciphertext TEXT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Version: 1.05
+-- Description: Add API keys table
+CREATE TABLE api_keys (
+    id uuid PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+
+    -- identifies the key, e.g. dsk_k3j9x2mq
+    prefix VARCHAR(32) NOT NULL UNIQUE,
+    -- SHA-256 of the key
+    key_hash CHAR(64) NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+-- Version: 1.06
+-- Description: Add roles to API keys. Existing keys keep full access.
+ALTER TABLE api_keys ADD role VARCHAR(16) NOT NULL DEFAULT 'admin';
+
+-- Version: 1.07
+-- Description: Add namespaces to jobs, executions, credentials and API keys, and per-namespace quotas
+ALTER TABLE jobs ADD namespace VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE job_executions ADD namespace VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ADD namespace VARCHAR(63) NOT NULL DEFAULT 'default';
+
+ALTER TABLE credentials ADD namespace VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE credentials DROP CONSTRAINT credentials_pkey;
+ALTER TABLE credentials ADD PRIMARY KEY (namespace, name);
+
+CREATE INDEX jobs_namespace_index ON jobs (namespace, id);
+
+CREATE INDEX job_executions_namespace_start_time_index ON job_executions (namespace, start_time);
+
+CREATE INDEX api_keys_namespace_index ON api_keys (namespace);
+
+CREATE TABLE namespaces (
+    name VARCHAR(63) PRIMARY KEY,
+
+    -- quotas, NULL is unlimited
+    max_jobs INT,
+    max_executions_per_minute INT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Version: 1.08
+-- Description: Add the append-only audit log of job changes
+CREATE TABLE job_audit (
+    id BIGSERIAL PRIMARY KEY,
+    -- not a foreign key, the entries of deleted jobs are kept
+    job_id UUID NOT NULL,
+    namespace VARCHAR(63) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(128),
+    -- changed fields with their redacted values before and after the change
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX job_audit_job_id_index ON job_audit (job_id, id);
+
+CREATE INDEX job_audit_namespace_created_at_index ON job_audit (namespace, created_at);
+
+CREATE FUNCTION job_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'job_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER job_audit_append_only
+    BEFORE UPDATE OR DELETE ON job_audit
+    FOR EACH ROW EXECUTE FUNCTION job_audit_append_only();
+
+-- Version: 1.09
+-- Description: Keep every version of a job definition and link executions to the version they ran with
+ALTER TABLE jobs ADD version BIGINT NOT NULL DEFAULT 1;
+
+-- NULL for executions that ran before versions were recorded
+ALTER TABLE job_executions ADD job_version BIGINT;
+
+CREATE TABLE job_versions (
+    job_id uuid NOT NULL,
+    version BIGINT NOT NULL,
+    type job_type_enum NOT NULL,
+
+    execute_at TIMESTAMPTZ,
+    cron_schedule VARCHAR(255),
+
+    -- secrets are encrypted the same way as in the jobs table
+    http_job JSONB,
+    amqp_job JSONB,
+
+    tags TEXT[],
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (job_id, version),
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+INSERT INTO job_versions (job_id, version, type, execute_at, cron_schedule, http_job, amqp_job, tags, created_at)
+SELECT id, version, type, execute_at, cron_schedule, http_job, amqp_job, tags, updated_at FROM jobs;
+
+-- Version: 1.10
+-- Description: Add unique job keys and idempotency keys of job creation requests
+ALTER TABLE jobs ADD unique_key VARCHAR(255);
+
+CREATE UNIQUE INDEX jobs_namespace_unique_key_index ON jobs (namespace, unique_key) WHERE unique_key IS NOT NULL;
+
+CREATE TABLE idempotency_keys (
+    namespace VARCHAR(63) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    -- hash of the request, a key can't be reused for a different request
+    request_hash VARCHAR(64) NOT NULL,
+    -- NULL while the request is in progress
+    status_code INT,
+    response BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (namespace, key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_index ON idempotency_keys (expires_at);
+
+-- Version: 1.11
+-- Description: Add indexes for filtering, sorting and paginating jobs
+CREATE INDEX jobs_namespace_created_at_index ON jobs (namespace, created_at, id);
+
+CREATE INDEX jobs_namespace_updated_at_index ON jobs (namespace, updated_at, id);
+
+-- jobs that won't run again are sorted last
+CREATE INDEX jobs_namespace_next_run_sort_index ON jobs (namespace, COALESCE(next_run, 'infinity'::timestamptz), id);
+
+CREATE INDEX jobs_tags_index ON jobs USING GIN (tags);
+
+-- Version: 1.12
+-- Description: Record the runner instance of job executions and add indexes for searching executions
+-- NULL for executions that ran before instances were recorded
+ALTER TABLE job_executions ADD instance_id VARCHAR(255);
+
+CREATE INDEX job_executions_namespace_start_time_id_index ON job_executions (namespace, start_time DESC, id DESC);
+
+CREATE INDEX job_executions_namespace_status_start_time_index ON job_executions (namespace, status, start_time DESC);
+
+-- Version: 1.13
+-- Description: Record the planned start of job executions and add an index for job execution statistics
+-- NULL for executions that ran before planned starts were recorded
+ALTER TABLE job_executions ADD scheduled_at TIMESTAMPTZ;
+
+CREATE INDEX job_executions_job_id_start_time_index ON job_executions (job_id, start_time);
+
+-- Version: 1.14
+-- Description: Add execution events, streamed to clients and kept for a while so streams can be resumed
+CREATE TABLE execution_events (
+    id BIGSERIAL PRIMARY KEY,
+    namespace VARCHAR(63) NOT NULL,
+    job_id uuid NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    instance_id VARCHAR(255),
+    scheduled_at TIMESTAMPTZ,
+    -- the recorded execution of finished events
+    execution_id INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE,
+    FOREIGN KEY (execution_id) REFERENCES job_executions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX execution_events_namespace_id_index ON execution_events (namespace, id);
+
+CREATE INDEX execution_events_created_at_index ON execution_events (created_at);
+
+-- Version: 1.15
+-- Description: Add webhook subscriptions, the outbox of webhook events and the log of their deliveries
+CREATE TABLE webhook_subscriptions (
+    id uuid PRIMARY KEY,
+    namespace VARCHAR(63) NOT NULL,
+    url TEXT NOT NULL,
+    events VARCHAR(32)[] NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    -- encrypted secret signing the deliveries
+    secret TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX webhook_subscriptions_namespace_index ON webhook_subscriptions (namespace, created_at);
+
+-- Events are written in the transaction of the change they announce, and fanned out to the deliveries of the
+-- matching subscriptions afterwards. Events of deleted jobs are kept, so there is no foreign key to the job.
+CREATE TABLE webhook_events (
+    id BIGSERIAL PRIMARY KEY,
+    namespace VARCHAR(63) NOT NULL,
+    job_id uuid NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- NULL until the event was fanned out to deliveries
+    processed_at TIMESTAMPTZ
+);
+
+CREATE INDEX webhook_events_unprocessed_index ON webhook_events (id) WHERE processed_at IS NULL;
+
+CREATE INDEX webhook_events_created_at_index ON webhook_events (created_at);
+
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id uuid NOT NULL,
+    event_id BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    -- when a pending delivery is attempted next, or until when an attempt in progress is leased
+    next_attempt_at TIMESTAMPTZ,
+    last_status_code INT,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (subscription_id, event_id),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES webhook_events (id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_deliveries_pending_index ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE INDEX webhook_deliveries_subscription_id_index ON webhook_deliveries (subscription_id, id DESC);
+
+CREATE INDEX webhook_deliveries_event_id_index ON webhook_deliveries (event_id);
+
+-- Version: 1.16
+-- Description: Stop jobs after a number of consecutive failed executions
+ALTER TABLE jobs ADD allowed_failed_runs INT;
+
+-- Version: 1.17
+-- Description: Keep the ETag of responses of requests with an idempotency key
+ALTER TABLE idempotency_keys ADD etag TEXT;
+
+-- Version: 1.18
+-- Description: Record the transactions of execution events, so resumed streams get the events committed late
+-- the transaction emitting the event, and the transactions in progress when it was emitted
+ALTER TABLE execution_events ADD xid xid8 NOT NULL DEFAULT pg_current_xact_id();
+ALTER TABLE execution_events ADD snapshot pg_snapshot NOT NULL DEFAULT pg_current_snapshot();
@@ -47,6 +47,7 @@ func StopDB(c *docker.Container) {
 // Test owns state for running and shutting down tests.
 type Test struct {
 	DB       *sqlx.DB
+	Config   database.Config
 	Log      *otelzap.Logger
 	Teardown func()
 	t        *testing.T
@@ -92,13 +93,15 @@ func NewTest(t *testing.T, c *docker.Container) *Test {
 
 	// -------------------------------------------------------------------------
 
-	db, err := database.Open(database.Config{
+	cfg := database.Config{
 		User:       "postgres",
 		Password:   "postgres",
 		Host:       c.Host,
 		Name:       dbName,
 		DisableTLS: true,
-	})
+	}
+
+	db, err := database.Open(cfg)
 	if err != nil {
 		t.Fatalf("Opening database connection: %v", err)
 	}
@@ -140,6 +143,7 @@ func NewTest(t *testing.T, c *docker.Container) *Test {
 
 	test := Test{
 		DB:       db,
+		Config:   cfg,
 		Log:      log,
 		Teardown: teardown,
 		t:        t,
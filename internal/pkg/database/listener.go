@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Listener receives notifications sent with NOTIFY. Each Listen call uses a dedicated connection, as a listening
+// connection can't be shared and the pool may be limited to a single connection.
+type Listener struct {
+	cfg Config
+}
+
+// NewListener creates a listener connecting to the configured database.
+func NewListener(cfg Config) *Listener {
+	return &Listener{cfg: cfg}
+}
+
+// Listen connects to the database and listens on the channel, calling listening once notifications are received and
+// notify with the payload of every notification. It blocks until the context is done or the connection fails, and
+// returns the reason. Notifications sent while no connection is listening are lost.
+func (l *Listener) Listen(ctx context.Context, channel string, listening func(), notify func(payload string)) error {
+	conn, err := pgx.Connect(ctx, l.cfg.connString())
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+
+	defer func() {
+		_ = conn.Close(context.Background())
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	listening()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notifications: %w", err)
+		}
+
+		notify(notification.Payload)
+	}
+}
@@ -12,7 +12,7 @@ import (
 // Config is the required properties to use the database.
 type Config struct {
 	User         string `mapstructure:"user" yaml:"user" json:"user,omitempty"`
-	Password     string `mapstructure:"password" yaml:"password" json:"password,omitempty"`
+	Password     string `mapstructure:"password" yaml:"password" json:"-"` // never logged
 	Host         string `mapstructure:"host" yaml:"host" json:"host,omitempty"`
 	Name         string `mapstructure:"name" yaml:"name" json:"name,omitempty"`
 	MaxIdleConns int    `mapstructure:"maxIdleConns" yaml:"maxIdleConns" json:"maxIdleConns,omitempty"`
@@ -22,6 +22,18 @@ type Config struct {
 
 // Open knows how to open a database connection based on the configuration.
 func Open(cfg Config) (*sqlx.DB, error) {
+	db, err := sqlx.Open("pgx", cfg.connString())
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+
+	return db, nil
+}
+
+// connString returns the URL of the database.
+func (cfg Config) connString() string {
 	sslMode := "require"
 	if cfg.DisableTLS {
 		sslMode = "disable"
@@ -39,14 +51,7 @@ func Open(cfg Config) (*sqlx.DB, error) {
 		RawQuery: q.Encode(),
 	}
 
-	db, err := sqlx.Open("pgx", u.String())
-	if err != nil {
-		return nil, err
-	}
-	db.SetMaxIdleConns(cfg.MaxIdleConns)
-	db.SetMaxOpenConns(cfg.MaxOpenConns)
-
-	return db, nil
+	return u.String()
 }
 
 // StatusCheck returns nil if it can successfully talk to the database. It
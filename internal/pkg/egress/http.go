@@ -0,0 +1,73 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+const maxRedirects = 10
+
+// HTTPClient returns a copy of the client whose transport enforces the policy when dialing
+// and which refuses to follow redirects to destinations denied by the policy. Proxies are not used, as the
+// transport would dial the proxy instead of the destination, which could then not be checked.
+func (p *Policy) HTTPClient(client *http.Client) *http.Client {
+	if p == nil {
+		return client
+	}
+
+	base, ok := client.Transport.(*http.Transport)
+	if !ok || base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+
+	transport := base.Clone()
+	transport.Proxy = nil
+	transport.DialContext = p.DialContext(&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	})
+
+	guarded := *client
+	guarded.Transport = transport
+	guarded.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := p.CheckURL(req.URL.String()); err != nil {
+			return err
+		}
+
+		if client.CheckRedirect != nil {
+			return client.CheckRedirect(req, via)
+		}
+
+		if len(via) >= maxRedirects {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		return nil
+	}
+
+	return &guarded
+}
+
+// AMQPDial returns a dial function for AMQP connections enforcing the policy. Like the default dial function of
+// the AMQP client, it sets a deadline for the TLS and AMQP handshakes, so an unresponsive broker doesn't block forever;
+// the client clears it once the connection is open.
+func (p *Policy) AMQPDial(timeout time.Duration) func(network, address string) (net.Conn, error) {
+	dial := p.DialContext(&net.Dialer{Timeout: timeout})
+
+	return func(network, address string) (net.Conn, error) {
+		conn, err := dial(context.Background(), network, address)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		return conn, nil
+	}
+}
@@ -0,0 +1,316 @@
+// Package egress restricts the destinations jobs are allowed to call, protecting internal
+// services and cloud metadata endpoints from server-side request forgery (SSRF).
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+
+	error2 "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+// DefaultDeniedCIDRs block loopback, private, shared (carrier-grade NAT) and link-local addresses, which include internal
+// services and the cloud metadata endpoints (e.g. 169.254.169.254). Jobs calling internal services require the policy to be configured.
+var DefaultDeniedCIDRs = []string{
+	"0.0.0.0/8",
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"169.254.0.0/16",
+	"100.64.0.0/10", // shared address space (RFC 6598), includes the Alibaba Cloud metadata endpoint 100.100.100.200
+	"::/128",
+	"::1/128",
+	"fc00::/7", // includes the AWS metadata endpoint fd00:ec2::254
+	"fe80::/10",
+}
+
+// Config is the egress policy configuration.
+//
+// Denied entries always take precedence. If an allow list is not empty, destinations must match it.
+// Hosts can be exact host names or wildcards matching all subdomains, e.g. "*.example.com".
+type Config struct {
+	AllowedCIDRs   []string `mapstructure:"allowedCidrs" yaml:"allowedCidrs" json:"allowedCidrs,omitempty"`
+	DeniedCIDRs    []string `mapstructure:"deniedCidrs" yaml:"deniedCidrs" json:"deniedCidrs,omitempty"`
+	AllowedHosts   []string `mapstructure:"allowedHosts" yaml:"allowedHosts" json:"allowedHosts,omitempty"`
+	DeniedHosts    []string `mapstructure:"deniedHosts" yaml:"deniedHosts" json:"deniedHosts,omitempty"`
+	BlockedSchemes []string `mapstructure:"blockedSchemes" yaml:"blockedSchemes" json:"blockedSchemes,omitempty"`
+	BlockedPorts   []int    `mapstructure:"blockedPorts" yaml:"blockedPorts" json:"blockedPorts,omitempty"`
+}
+
+// Policy decides whether a destination may be called. A nil policy allows everything.
+type Policy struct {
+	allowedPrefixes []netip.Prefix
+	deniedPrefixes  []netip.Prefix
+	allowedHosts    []string
+	deniedHosts     []string
+	blockedSchemes  map[string]struct{}
+	blockedPorts    map[int]struct{}
+
+	resolver *net.Resolver
+}
+
+// NewPolicy creates a policy from the configuration.
+func NewPolicy(cfg Config) (*Policy, error) {
+	policy := &Policy{
+		allowedHosts:   normalizeHosts(cfg.AllowedHosts),
+		deniedHosts:    normalizeHosts(cfg.DeniedHosts),
+		blockedSchemes: make(map[string]struct{}, len(cfg.BlockedSchemes)),
+		blockedPorts:   make(map[int]struct{}, len(cfg.BlockedPorts)),
+		resolver:       net.DefaultResolver,
+	}
+
+	var err error
+	policy.allowedPrefixes, err = parsePrefixes(cfg.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.deniedPrefixes, err = parsePrefixes(cfg.DeniedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scheme := range cfg.BlockedSchemes {
+		policy.blockedSchemes[strings.ToLower(scheme)] = struct{}{}
+	}
+
+	for _, port := range cfg.BlockedPorts {
+		policy.blockedPorts[port] = struct{}{}
+	}
+
+	return policy, nil
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			// Allow single addresses
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid egress CIDR %q: %w", cidr, err)
+			}
+
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+func normalizeHosts(hosts []string) []string {
+	normalized := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		normalized = append(normalized, strings.TrimSuffix(strings.ToLower(host), "."))
+	}
+
+	return normalized
+}
+
+func denied(reason string, args ...any) error {
+	return fmt.Errorf("%w: %s", error2.ErrEgressDenied, fmt.Sprintf(reason, args...))
+}
+
+// CheckURL checks the scheme, host and port of the URL. If the host is an IP address, it is checked against the CIDR lists.
+// Host names are checked against the CIDR lists when they are resolved, see Validate and DialContext.
+func (p *Policy) CheckURL(rawURL string) error {
+	if p == nil {
+		return nil
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return denied("invalid URL")
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if _, ok := p.blockedSchemes[scheme]; ok {
+		return denied("scheme %q is blocked", scheme)
+	}
+
+	port := parsed.Port()
+	if port == "" {
+		port = defaultPort(scheme)
+	}
+
+	return p.checkHostPort(parsed.Hostname(), port)
+}
+
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	case "amqp":
+		return "5672"
+	case "amqps":
+		return "5671"
+	default:
+		return ""
+	}
+}
+
+func (p *Policy) checkHostPort(host, port string) error {
+	if port != "" {
+		portNumber, err := strconv.Atoi(port)
+		if err != nil {
+			return denied("invalid port %q", port)
+		}
+
+		if _, ok := p.blockedPorts[portNumber]; ok {
+			return denied("port %d is blocked", portNumber)
+		}
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.CheckIP(addr)
+	}
+
+	return p.checkHostName(host)
+}
+
+func (p *Policy) checkHostName(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return denied("host is empty")
+	}
+
+	if matchHost(p.deniedHosts, host) {
+		return denied("host %q is denied", host)
+	}
+
+	if len(p.allowedHosts) > 0 && !matchHost(p.allowedHosts, host) {
+		return denied("host %q is not allowed", host)
+	}
+
+	return nil
+}
+
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if suffix, isWildcard := strings.CutPrefix(pattern, "*"); isWildcard {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+			continue
+		}
+
+		if host == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckIP checks the IP address against the CIDR lists.
+func (p *Policy) CheckIP(addr netip.Addr) error {
+	if p == nil {
+		return nil
+	}
+
+	addr = addr.Unmap()
+
+	for _, prefix := range p.deniedPrefixes {
+		if prefix.Contains(addr) {
+			return denied("address %s is denied", addr)
+		}
+	}
+
+	if len(p.allowedPrefixes) == 0 {
+		return nil
+	}
+
+	for _, prefix := range p.allowedPrefixes {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	return denied("address %s is not allowed", addr)
+}
+
+// Validate checks the URL and the addresses its host currently resolves to. If the host can't be resolved,
+// only the URL is checked, as the destination is checked again when it is dialed.
+func (p *Policy) Validate(ctx context.Context, rawURL string) error {
+	if p == nil {
+		return nil
+	}
+
+	if err := p.CheckURL(rawURL); err != nil {
+		return err
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return denied("invalid URL")
+	}
+
+	host := parsed.Hostname()
+	if _, err := netip.ParseAddr(host); err == nil {
+		return nil
+	}
+
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+
+	for _, addr := range addrs {
+		if err := p.CheckIP(addr); err != nil {
+			return fmt.Errorf("%w (resolved from %q)", err, host)
+		}
+	}
+
+	return nil
+}
+
+// control checks the address a connection is about to be made to. It runs after the host name is resolved, so
+// a host name resolving to a different address at dial time than at validation time (DNS rebinding) is caught.
+func (p *Policy) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return denied("invalid address %q", address)
+	}
+
+	if _, ok := p.blockedPorts[int(addrPort.Port())]; ok {
+		return denied("port %d is blocked", addrPort.Port())
+	}
+
+	return p.CheckIP(addrPort.Addr())
+}
+
+// DialContext returns a dial function enforcing the policy on the host name before it is resolved
+// and on every resolved address before connecting to it.
+func (p *Policy) DialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if p == nil {
+		return dialer.DialContext
+	}
+
+	guarded := *dialer
+	guarded.Control = p.control
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, denied("invalid address %q", address)
+		}
+
+		if err := p.checkHostPort(host, port); err != nil {
+			return nil, err
+		}
+
+		return guarded.DialContext(ctx, network, address)
+	}
+}
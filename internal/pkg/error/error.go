@@ -5,28 +5,133 @@ import (
 )
 
 var (
-	ErrInvalidJobType        = errors.New("job type must be either HTTP or AMQP")
-	ErrInvalidJobID          = errors.New("job ID must be a valid UUID")
-	ErrInvalidJobStatus      = errors.New("job status must be either PENDING, SCHEDULED, SUCCESSFUL, or FAILED")
-	ErrInvalidJobFields      = errors.New("job cannot have both HTTP and AMQP fields defined")
-	ErrInvalidJobSchedule    = errors.New("job must have only one of execute_at and cron_schedule defined")
-	ErrInvalidCronSchedule   = errors.New("invalid cron schedule")
-	ErrInvalidExecuteAt      = errors.New("execute_at must be in the future")
-	ErrEmptyHTTPJobURL       = errors.New("HTTP job URL cannot be empty")
-	ErrHTTPJobNotDefined     = errors.New("HTTP job must be defined")
-	ErrEmptyHTTPJobMethod    = errors.New("HTTP job method cannot be empty")
-	ErrAMQPJobNotDefined     = errors.New("AMQP job must be defined")
-	ErrAMQPConnectionInvalid = errors.New("AMQP connection string is invalid")
-	ErrEmptyExchange         = errors.New("exchange must be defined for AMQP jobs")
-	ErrEmptyRoutingKey       = errors.New("routing key must be defined for AMQP jobs")
-	ErrInvalidAuthType       = errors.New("auth type must be either none, basic, or bearer")
-	ErrEmptyUsername         = errors.New("username must be defined for basic auth")
-	ErrEmptyPassword         = errors.New("password must be defined for basic auth")
-	ErrEmptyBearerToken      = errors.New("bearer token must be defined for bearer auth")
-	ErrAuthMethodNotDefined  = errors.New("auth method must be defined")
-	ErrJobNotFound           = errors.New("job not found")
-	ErrInvalidResponseCode   = errors.New("invalid response code")
-	ErrInvalidBodyEncoding   = errors.New("invalid body encoding")
+	ErrInvalidJobType          = errors.New("job type must be either HTTP or AMQP")
+	ErrInvalidJobID            = errors.New("job ID must be a valid UUID")
+	ErrInvalidJobVersion       = errors.New("job version must be a positive integer")
+	ErrJobVersionNotFound      = errors.New("job version not found")
+	ErrJobPreconditionRequired = errors.New("If-Match header with the job's ETag is required")
+	ErrJobVersionMismatch      = errors.New("job has been modified: If-Match does not match the job's ETag")
+	ErrJobConflict             = errors.New("job was modified concurrently, retry the request")
+	ErrInvalidJobStatus        = errors.New("job status must be either RUNNING or STOPPED")
+	ErrInvalidJobPatch         = errors.New("job patch must be a JSON merge patch of the job")
+	ErrInvalidJobFields        = errors.New("job cannot have both HTTP and AMQP fields defined")
+	ErrInvalidJobSchedule      = errors.New("job must have only one of execute_at and cron_schedule defined")
+	ErrInvalidCronSchedule     = errors.New("invalid cron schedule")
+	ErrInvalidExecuteAt        = errors.New("execute_at must be in the future")
+	ErrEmptyHTTPJobURL         = errors.New("HTTP job URL cannot be empty")
+	ErrHTTPJobNotDefined       = errors.New("HTTP job must be defined")
+	ErrEmptyHTTPJobMethod      = errors.New("HTTP job method cannot be empty")
+	ErrAMQPJobNotDefined       = errors.New("AMQP job must be defined")
+	ErrAMQPConnectionInvalid   = errors.New("AMQP connection string is invalid")
+	ErrEmptyExchange           = errors.New("exchange must be defined for AMQP jobs")
+	ErrEmptyRoutingKey         = errors.New("routing key must be defined for AMQP jobs")
+	ErrInvalidAuthType         = errors.New("auth type must be either none, basic, bearer, hmac, or oauth2_client_credentials")
+	ErrEmptyUsername           = errors.New("username must be defined for basic auth")
+	ErrEmptyPassword           = errors.New("password must be defined for basic auth")
+	ErrEmptyBearerToken        = errors.New("bearer token must be defined for bearer auth")
+	ErrEmptyHMACSecret         = errors.New("secret must be defined for hmac auth")
+	ErrInvalidHMACScheme       = errors.New("hmac scheme must be either default, stripe, or github")
+	ErrOAuth2NotDefined        = errors.New("oauth2 settings must be defined for oauth2_client_credentials auth")
+	ErrEmptyOAuth2TokenURL     = errors.New("token URL must be defined for oauth2_client_credentials auth")
+	ErrInvalidOAuth2TokenURL   = errors.New("token URL must be a valid absolute URL")
+	ErrEmptyOAuth2ClientID     = errors.New("client ID must be defined for oauth2_client_credentials auth")
+	ErrEmptyOAuth2ClientSecret = errors.New("client secret must be defined for oauth2_client_credentials auth")
+	ErrOAuth2TokenFetchFailed  = errors.New("failed to fetch oauth2 token")
+	ErrAuthMethodNotDefined    = errors.New("auth method must be defined")
+	ErrJobNotFound             = errors.New("job not found")
+	ErrInvalidResponseCode     = errors.New("invalid response code")
+	ErrInvalidBodyEncoding     = errors.New("invalid body encoding")
+)
+
+var (
+	ErrInvalidTLSMinVersion       = errors.New("TLS min version must be either 1.0, 1.1, 1.2, or 1.3")
+	ErrIncompleteTLSClientKeyPair = errors.New("TLS client certificate and key must be defined together")
+	ErrInvalidTLSClientKeyPair    = errors.New("TLS client certificate and key must be a valid PEM encoded key pair")
+	ErrInvalidTLSCABundle         = errors.New("TLS CA bundle must contain at least one PEM encoded certificate")
+)
+
+var (
+	ErrInvalidCredentialName   = errors.New("credential name must be 1-255 characters long and contain only letters, digits, '.', '_' or '-'")
+	ErrInvalidCredentialType   = errors.New("credential type must be either basic, bearer, hmac, or amqp_connection")
+	ErrCredentialTypeMismatch  = errors.New("credential type does not match the auth type it is used for")
+	ErrCredentialNotFound      = errors.New("credential not found")
+	ErrUnknownCredential       = errors.New("job references a credential that does not exist")
+	ErrCredentialAlreadyExists = errors.New("credential already exists")
+	ErrCredentialInUse         = errors.New("credential is referenced by one or more jobs")
+)
+
+var (
+	ErrEgressDenied = errors.New("destination is not allowed by the egress policy")
+)
+
+var (
+	ErrInvalidAPIKeyName = errors.New("API key name must be 1-255 characters long")
+	ErrAPIKeyNotFound    = errors.New("API key not found")
+	ErrMissingAPIKey     = errors.New("API key is missing: pass it in the X-API-Key header or as a bearer token")
+	ErrInvalidAPIKey     = errors.New("API key is invalid or revoked")
+)
+
+var (
+	ErrInvalidNamespace      = errors.New("namespace must be a lowercase DNS label, e.g. team-a")
+	ErrInvalidNamespaceQuota = errors.New("namespace quotas must not be negative")
+	ErrJobQuotaExceeded      = errors.New("namespace has reached its maximum number of jobs")
+)
+
+var (
+	ErrInvalidToken = errors.New("bearer token is invalid")
+	ErrForbidden    = errors.New("insufficient permissions")
+	ErrInvalidRole  = errors.New("role must be either viewer, operator, or admin")
+)
+
+var (
+	ErrInvalidAuditAction    = errors.New("audit action must be either create, update, delete, or rollback")
+	ErrInvalidAuditTimeRange = errors.New("audit time range must be RFC 3339 timestamps with from before to")
+)
+
+var (
+	ErrInvalidJobUniqueKey      = errors.New("job unique key must be 1-255 characters long")
+	ErrInvalidAllowedFailedRuns = errors.New("allowed_failed_runs must be a positive integer")
+	ErrJobUniqueKeyExists       = errors.New("a job with the unique key already exists in the namespace")
+	ErrInvalidIdempotencyKey    = errors.New("Idempotency-Key must be 1-255 printable ASCII characters")
+	ErrIdempotencyKeyReused     = errors.New("Idempotency-Key was already used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with the same Idempotency-Key is still in progress")
+)
+
+var (
+	ErrEmptyJobBatch      = errors.New("job batch must contain at least one job")
+	ErrJobBatchTooLarge   = errors.New("job batch must not contain more than 1000 jobs")
+	ErrInvalidJobSelector = errors.New("jobs must be selected either by ids or by tags")
+	ErrInvalidJobBatch    = errors.New("job batch contains invalid items, no job was changed")
+
+	ErrInvalidExpectedVersions = errors.New("expected versions must map ids of the selected jobs to positive versions")
+	ErrJobBatchVersionMismatch = errors.New("job has been modified: its version does not match the expected version")
+)
+
+var (
+	ErrInvalidJobSort      = errors.New("job sort must be created_at, updated_at or next_run, optionally prefixed with - for descending order")
+	ErrInvalidTagMatch     = errors.New("tag match must be either all or any")
+	ErrInvalidJobTimeRange = errors.New("job time ranges must be RFC 3339 timestamps with from before to")
+	ErrInvalidJobCursor    = errors.New("cursor is invalid or was created for a different sort order, and can't be combined with an offset")
+)
+
+var (
+	ErrInvalidExecutionStatus    = errors.New("execution status must be either SUCCESSFUL or FAILED")
+	ErrInvalidExecutionTimeRange = errors.New("execution time range must be RFC 3339 timestamps with from before to")
+	ErrInvalidExecutionCursor    = errors.New("cursor is invalid, and can't be combined with an offset")
+	ErrInvalidStatsWindow        = errors.New("stats windows must be between one and five durations like 15m, 24h or 7d, of at most 90 days")
+)
+
+var (
+	ErrInvalidLastEventID     = errors.New("last event ID must be a non-negative integer")
+	ErrEventStreamUnavailable = errors.New("execution events are temporarily unavailable, retry later")
+	ErrEventStreamClosed      = errors.New("execution event stream closed, resume with the ID of the last event")
+)
+
+var (
+	ErrInvalidWebhookURL            = errors.New("webhook URL must be an absolute http or https URL without credentials")
+	ErrInvalidWebhookEvents         = errors.New("webhook events must be a non-empty list of distinct events: job.created, job.updated, job.deleted, job.auto_stopped, or execution.failed")
+	ErrInvalidWebhookSubscriptionID = errors.New("webhook subscription ID must be a valid UUID")
+	ErrWebhookSubscriptionNotFound  = errors.New("webhook subscription not found")
 )
 
 type CustomError struct {
@@ -42,7 +147,9 @@ func ToCustomJobError(err error) *CustomError {
 	switch {
 	case errors.Is(err, ErrInvalidJobType),
 		errors.Is(err, ErrInvalidJobID),
+		errors.Is(err, ErrInvalidJobVersion),
 		errors.Is(err, ErrInvalidJobStatus),
+		errors.Is(err, ErrInvalidJobPatch),
 		errors.Is(err, ErrInvalidJobFields),
 		errors.Is(err, ErrInvalidJobSchedule),
 		errors.Is(err, ErrInvalidCronSchedule),
@@ -57,10 +164,79 @@ func ToCustomJobError(err error) *CustomError {
 		errors.Is(err, ErrEmptyUsername),
 		errors.Is(err, ErrEmptyPassword),
 		errors.Is(err, ErrEmptyBearerToken),
+		errors.Is(err, ErrEmptyHMACSecret),
+		errors.Is(err, ErrInvalidHMACScheme),
+		errors.Is(err, ErrOAuth2NotDefined),
+		errors.Is(err, ErrEmptyOAuth2TokenURL),
+		errors.Is(err, ErrInvalidOAuth2TokenURL),
+		errors.Is(err, ErrEmptyOAuth2ClientID),
+		errors.Is(err, ErrEmptyOAuth2ClientSecret),
+		errors.Is(err, ErrInvalidTLSMinVersion),
+		errors.Is(err, ErrIncompleteTLSClientKeyPair),
+		errors.Is(err, ErrInvalidTLSClientKeyPair),
+		errors.Is(err, ErrInvalidTLSCABundle),
+		errors.Is(err, ErrInvalidCredentialName),
+		errors.Is(err, ErrInvalidCredentialType),
+		errors.Is(err, ErrCredentialTypeMismatch),
+		errors.Is(err, ErrUnknownCredential),
+		errors.Is(err, ErrEgressDenied),
+		errors.Is(err, ErrInvalidAPIKeyName),
+		errors.Is(err, ErrInvalidRole),
+		errors.Is(err, ErrInvalidNamespace),
+		errors.Is(err, ErrInvalidNamespaceQuota),
+		errors.Is(err, ErrInvalidAuditAction),
+		errors.Is(err, ErrInvalidAuditTimeRange),
+		errors.Is(err, ErrInvalidJobUniqueKey),
+		errors.Is(err, ErrInvalidAllowedFailedRuns),
+		errors.Is(err, ErrInvalidIdempotencyKey),
+		errors.Is(err, ErrEmptyJobBatch),
+		errors.Is(err, ErrJobBatchTooLarge),
+		errors.Is(err, ErrInvalidJobSelector),
+		errors.Is(err, ErrInvalidExpectedVersions),
+		errors.Is(err, ErrInvalidJobBatch),
+		errors.Is(err, ErrInvalidJobSort),
+		errors.Is(err, ErrInvalidTagMatch),
+		errors.Is(err, ErrInvalidJobTimeRange),
+		errors.Is(err, ErrInvalidJobCursor),
+		errors.Is(err, ErrInvalidExecutionStatus),
+		errors.Is(err, ErrInvalidExecutionTimeRange),
+		errors.Is(err, ErrInvalidExecutionCursor),
+		errors.Is(err, ErrInvalidStatsWindow),
+		errors.Is(err, ErrInvalidLastEventID),
+		errors.Is(err, ErrInvalidWebhookURL),
+		errors.Is(err, ErrInvalidWebhookEvents),
+		errors.Is(err, ErrInvalidWebhookSubscriptionID),
 		errors.Is(err, ErrAuthMethodNotDefined):
 		return &CustomError{err, 400}
-	case errors.Is(err, ErrJobNotFound):
+	case errors.Is(err, ErrMissingAPIKey),
+		errors.Is(err, ErrInvalidAPIKey),
+		errors.Is(err, ErrInvalidToken):
+		return &CustomError{err, 401}
+	case errors.Is(err, ErrForbidden):
+		return &CustomError{err, 403}
+	case errors.Is(err, ErrJobQuotaExceeded):
+		return &CustomError{err, 429}
+	case errors.Is(err, ErrJobNotFound),
+		errors.Is(err, ErrJobVersionNotFound),
+		errors.Is(err, ErrCredentialNotFound),
+		errors.Is(err, ErrAPIKeyNotFound),
+		errors.Is(err, ErrWebhookSubscriptionNotFound):
 		return &CustomError{err, 404}
+	case errors.Is(err, ErrCredentialAlreadyExists),
+		errors.Is(err, ErrCredentialInUse),
+		errors.Is(err, ErrJobConflict),
+		errors.Is(err, ErrJobUniqueKeyExists),
+		errors.Is(err, ErrIdempotencyKeyInProgress):
+		return &CustomError{err, 409}
+	case errors.Is(err, ErrJobVersionMismatch),
+		errors.Is(err, ErrJobBatchVersionMismatch):
+		return &CustomError{err, 412}
+	case errors.Is(err, ErrIdempotencyKeyReused):
+		return &CustomError{err, 422}
+	case errors.Is(err, ErrJobPreconditionRequired):
+		return &CustomError{err, 428}
+	case errors.Is(err, ErrEventStreamUnavailable):
+		return &CustomError{err, 503}
 	default:
 		return &CustomError{err, 500}
 	}
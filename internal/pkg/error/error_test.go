@@ -16,6 +16,8 @@ func TestToCustomJobError(t *testing.T) {
 		{"ErrEmptyPassword", ErrEmptyPassword, 400},
 		{"ErrEmptyBearerToken", ErrEmptyBearerToken, 400},
 		{"ErrAuthMethodNotDefined", ErrAuthMethodNotDefined, 400},
+		{"ErrEventStreamUnavailable", ErrEventStreamUnavailable, 503},
+		{"ErrWebhookSubscriptionNotFound", ErrWebhookSubscriptionNotFound, 404},
 		{"Other error", errors.New("other error"), 500},
 	}
 
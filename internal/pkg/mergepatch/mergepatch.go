@@ -0,0 +1,51 @@
+// Package mergepatch applies JSON merge patches (RFC 7386).
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPatch is returned if the document or the patch is not valid JSON.
+var ErrInvalidPatch = errors.New("invalid JSON merge patch")
+
+// Apply applies the merge patch to the JSON document and returns the patched document. Members of patch objects are
+// merged into the document recursively, members with a null value are removed, and any other value replaces the value
+// in the document.
+func Apply(document, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	return json.Marshal(merge(target, patchValue))
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+
+		targetObject[name] = merge(targetObject[name], value)
+	}
+
+	return targetObject
+}
@@ -0,0 +1,49 @@
+package mergepatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Examples from RFC 7386, appendix A
+func TestApply(t *testing.T) {
+	tests := []struct {
+		document string
+		patch    string
+		result   string
+	}{
+		{document: `{"a":"b"}`, patch: `{"a":"c"}`, result: `{"a":"c"}`},
+		{document: `{"a":"b"}`, patch: `{"b":"c"}`, result: `{"a":"b","b":"c"}`},
+		{document: `{"a":"b"}`, patch: `{"a":null}`, result: `{}`},
+		{document: `{"a":"b","b":"c"}`, patch: `{"a":null}`, result: `{"b":"c"}`},
+		{document: `{"a":["b"]}`, patch: `{"a":"c"}`, result: `{"a":"c"}`},
+		{document: `{"a":"c"}`, patch: `{"a":["b"]}`, result: `{"a":["b"]}`},
+		{document: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, result: `{"a":{"b":"d"}}`},
+		{document: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, result: `{"a":[1]}`},
+		{document: `["a","b"]`, patch: `["c","d"]`, result: `["c","d"]`},
+		{document: `{"a":"b"}`, patch: `["c"]`, result: `["c"]`},
+		{document: `{"a":"foo"}`, patch: `null`, result: `null`},
+		{document: `{"a":"foo"}`, patch: `"bar"`, result: `"bar"`},
+		{document: `{"e":null}`, patch: `{"a":1}`, result: `{"a":1,"e":null}`},
+		{document: `[1,2]`, patch: `{"a":"b","c":null}`, result: `{"a":"b"}`},
+		{document: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, result: `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			result, err := Apply([]byte(tt.document), []byte(tt.patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.result, string(result))
+		})
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	_, err := Apply([]byte(`{"a":"b"}`), []byte(`{"a":`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+
+	_, err = Apply([]byte(`{"a":`), []byte(`{}`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
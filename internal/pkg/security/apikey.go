@@ -0,0 +1,61 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognize (e.g. by secret scanners).
+const APIKeyPrefix = "dsk_"
+
+var apiKeyIDEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// GenerateAPIKey generates a new API key in the form "dsk_<id>_<secret>" and returns it together with its
+// prefix ("dsk_<id>"), which identifies the key without revealing it.
+func GenerateAPIKey() (key string, prefix string, err error) {
+	id := make([]byte, 5)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix = APIKeyPrefix + apiKeyIDEncoding.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	return key, prefix, nil
+}
+
+// ParseAPIKeyPrefix returns the prefix of the API key, or false if the key is malformed.
+func ParseAPIKeyPrefix(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || id == "" || secret == "" {
+		return "", false
+	}
+
+	return APIKeyPrefix + id, true
+}
+
+// HashAPIKey hashes the API key for storage. API keys are long random strings, so a fast hash is sufficient.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// VerifyAPIKey checks the API key against its stored hash in constant time.
+func VerifyAPIKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
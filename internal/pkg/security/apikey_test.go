@@ -0,0 +1,37 @@
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, prefix+"_"))
+	assert.True(t, strings.HasPrefix(prefix, APIKeyPrefix))
+
+	parsedPrefix, ok := ParseAPIKeyPrefix(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsedPrefix)
+
+	hash := HashAPIKey(key)
+	assert.NotContains(t, hash, key)
+	assert.True(t, VerifyAPIKey(key, hash))
+	assert.False(t, VerifyAPIKey(key+"x", hash))
+
+	other, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestParseAPIKeyPrefix(t *testing.T) {
+	for _, key := range []string{"", "dsk_", "dsk_abc", "dsk__secret", "dsk_abc_", "sk_abc_secret"} {
+		_, ok := ParseAPIKeyPrefix(key)
+		assert.False(t, ok, key)
+	}
+}
@@ -11,52 +11,110 @@ import (
 	"github.com/spf13/viper"
 )
 
+// DefaultKeyID is the ID of the key configured with storage.encryption.key.
+const DefaultKeyID = "default"
+
 type Encryptor interface {
 	Encrypt(plaintext string) (*string, error)
 	Decrypt(ciphertext string) (*string, error)
 }
 
+// RotationChecker is implemented by encryptors that can tell whether ciphertext was encrypted with their active key,
+// so secrets that are already rotated aren't re-encrypted.
+type RotationChecker interface {
+	NeedsRotation(ciphertext string) bool
+}
+
 type encryptor struct {
 	cipherBlock cipher.Block
 	aead        cipher.AEAD
 }
 
-func NewEncryptor(secretKey string) Encryptor {
-	// Load the secret key from a secure location.
+func newEncryptor(secretKey string) (*encryptor, error) {
 	aes, err := aes.NewCipher([]byte(secretKey))
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	gcm, err := cipher.NewGCM(aes)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	return &encryptor{
 		cipherBlock: aes,
 		aead:        gcm,
-	}
+	}, nil
 }
 
-func NewEncryptorFromEnv() Encryptor {
-	// Load the secret key from a secure location.
-	secretKey := viper.GetString("storage.encryption.key")
-
-	aes, err := aes.NewCipher([]byte(secretKey))
+// NewEncryptor creates an encryptor with a single key, which is used as the active key. The key must be 16, 24 or 32
+// bytes long.
+func NewEncryptor(secretKey string) (Encryptor, error) {
+	keyring, err := NewKeyring(map[string]string{DefaultKeyID: secretKey}, DefaultKeyID)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	gcm, err := cipher.NewGCM(aes)
-	if err != nil {
-		panic(err)
+	return keyring, nil
+}
+
+// NewEncryptorFromEnv creates an encryptor from the configuration.
+//
+// If storage.encryption.provider is set, secrets are envelope encrypted with data keys wrapped by the
+// KEK provider, configured under storage.encryption.<provider>. The keys in storage.encryption.keys or
+// storage.encryption.key are then only used to decrypt secrets written before the provider was configured.
+//
+// Otherwise, versioned keys are read from storage.encryption.keys (key ID -> key) with
+// storage.encryption.activeKey selecting the key used for encryption. If no versioned keys are configured,
+// storage.encryption.key is used as the only key.
+//
+// All keys are validated; default and low entropy keys are only accepted if storage.encryption.devMode is set.
+func NewEncryptorFromEnv() (Encryptor, error) {
+	providerName := viper.GetString("storage.encryption.provider")
+	if providerName != "" {
+		provider, err := NewKEKProvider(providerName, viper.Sub("storage.encryption."+providerName))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid KEK provider configuration")
+		}
+
+		var fallback Encryptor
+		if len(viper.GetStringMapString("storage.encryption.keys")) > 0 || viper.GetString("storage.encryption.key") != "" {
+			fallback, err = NewKeyringFromEnv()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return NewEnvelopeEncryptor(provider, fallback), nil
 	}
 
-	return &encryptor{
-		cipherBlock: aes,
-		aead:        gcm,
+	return NewKeyringFromEnv()
+}
+
+// NewKeyringFromEnv creates a keyring from the versioned keys in storage.encryption.keys, with
+// storage.encryption.activeKey selecting the key used for encryption, or from storage.encryption.key if no
+// versioned keys are configured. All keys are validated like by NewEncryptorFromEnv.
+func NewKeyringFromEnv() (*Keyring, error) {
+	// Load the secret keys from a secure location.
+	keys := viper.GetStringMapString("storage.encryption.keys")
+	activeKeyID := viper.GetString("storage.encryption.activeKey")
+	devMode := viper.GetBool("storage.encryption.devMode")
+
+	if len(keys) == 0 {
+		key := viper.GetString("storage.encryption.key")
+		if key == "" && devMode {
+			key = DevModeKey
+		}
+
+		keys = map[string]string{DefaultKeyID: key}
+		activeKeyID = DefaultKeyID
 	}
+
+	if err := ValidateKeys(keys, devMode); err != nil {
+		return nil, err
+	}
+
+	return NewKeyring(keys, activeKeyID)
 }
 
 func (e *encryptor) Encrypt(plaintext string) (*string, error) {
@@ -85,6 +143,10 @@ func (e *encryptor) Decrypt(ciphertext string) (*string, error) {
 	// Since we know the ciphertext is actually nonce+ciphertext
 	// And len(nonce) == NonceSize(). We can separate the two.
 	nonceSize := e.aead.NonceSize()
+	if len(decoded) < nonceSize {
+		return nil, errors.New("ciphertext is too short")
+	}
+
 	nonce := decoded[:nonceSize]
 	actualCiphertext := decoded[nonceSize:]
 
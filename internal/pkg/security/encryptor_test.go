@@ -9,7 +9,8 @@ import (
 func TestEncryptor(t *testing.T) {
 	secretKey := "N1PCdw3M2B1TfJhoaY2mL736p2vCUc47"
 
-	enc := NewEncryptor(secretKey)
+	enc, err := NewEncryptor(secretKey)
+	assert.NoError(t, err)
 
 	sampleText := "test123"
 
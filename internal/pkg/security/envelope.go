@@ -0,0 +1,221 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+)
+
+// envelopePrefix marks envelope encrypted ciphertext. '@' is neither part of the base64
+// alphabet nor allowed in key IDs, so it can't be confused with keyring ciphertext.
+const envelopePrefix = "@env:"
+
+const (
+	dataKeySize = 32
+
+	// maxCachedDataKeys bounds the number of unwrapped data keys kept in memory.
+	maxCachedDataKeys = 4096
+
+	kekProviderTimeout = 10 * time.Second
+)
+
+var (
+	ErrInvalidEnvelope    = errors.New("envelope ciphertext is malformed")
+	ErrUnknownKEKProvider = errors.New("unknown KEK provider")
+)
+
+// KEKProvider wraps and unwraps data keys with a key-encryption-key (KEK) the scheduler never sees,
+// e.g. a key held in a local file, HashiCorp Vault transit or a cloud KMS.
+type KEKProvider interface {
+	// WrapKey encrypts the data key with the active KEK and returns the ID of the KEK used.
+	WrapKey(ctx context.Context, dataKey []byte) (kekID string, wrappedKey []byte, err error)
+
+	// UnwrapKey decrypts a data key wrapped with the given KEK.
+	UnwrapKey(ctx context.Context, kekID string, wrappedKey []byte) ([]byte, error)
+}
+
+// EnvelopeEncryptor encrypts every record with a new random data key, which is wrapped by the KEK provider and
+// stored alongside the ciphertext as "@env:<kek ID>:<wrapped data key>:<ciphertext>".
+// Ciphertext that is not envelope encrypted is decrypted with the fallback keyring, if configured,
+// so existing secrets can be migrated with the rotate-keys command.
+type EnvelopeEncryptor struct {
+	provider KEKProvider
+	fallback Encryptor
+
+	// unwrapped data keys, so the KEK provider is not called every time a record is decrypted
+	mu       sync.Mutex
+	dataKeys map[[sha256.Size]byte][]byte
+}
+
+// NewEnvelopeEncryptor creates an envelope encryptor. The fallback encryptor is optional.
+func NewEnvelopeEncryptor(provider KEKProvider, fallback Encryptor) *EnvelopeEncryptor {
+	return &EnvelopeEncryptor{
+		provider: provider,
+		fallback: fallback,
+		dataKeys: make(map[[sha256.Size]byte][]byte),
+	}
+}
+
+func (e *EnvelopeEncryptor) Encrypt(plaintext string) (*string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kekProviderTimeout)
+	defer cancel()
+
+	kekID, wrappedKey, err := e.provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap data key")
+	}
+
+	dataEncryptor, err := newEncryptor(string(dataKey))
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := dataEncryptor.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := envelopePrefix + kekID + ":" + base64.StdEncoding.EncodeToString(wrappedKey) + ":" + *ciphertext
+	return lo.ToPtr(envelope), nil
+}
+
+func (e *EnvelopeEncryptor) Decrypt(ciphertext string) (*string, error) {
+	envelope, isEnvelope := strings.CutPrefix(ciphertext, envelopePrefix)
+	if !isEnvelope {
+		if e.fallback == nil {
+			return nil, ErrInvalidEnvelope
+		}
+
+		return e.fallback.Decrypt(ciphertext)
+	}
+
+	parts := strings.SplitN(envelope, ":", 3)
+	if len(parts) != 3 {
+		return nil, ErrInvalidEnvelope
+	}
+
+	kekID, encodedKey, encrypted := parts[0], parts[1], parts[2]
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+
+	dataKey, err := e.unwrapKey(kekID, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	dataEncryptor, err := newEncryptor(string(dataKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return dataEncryptor.Decrypt(encrypted)
+}
+
+func (e *EnvelopeEncryptor) unwrapKey(kekID string, wrappedKey []byte) ([]byte, error) {
+	cacheKey := sha256.Sum256([]byte(kekID + ":" + string(wrappedKey)))
+
+	e.mu.Lock()
+	dataKey, ok := e.dataKeys[cacheKey]
+	e.mu.Unlock()
+	if ok {
+		return dataKey, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kekProviderTimeout)
+	defer cancel()
+
+	dataKey, err := e.provider.UnwrapKey(ctx, kekID, wrappedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap data key")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.dataKeys) >= maxCachedDataKeys {
+		e.dataKeys = make(map[[sha256.Size]byte][]byte)
+	}
+	e.dataKeys[cacheKey] = dataKey
+
+	return dataKey, nil
+}
+
+// NeedsRotation reports whether the ciphertext was not envelope encrypted, or its data key was wrapped by a KEK
+// that is no longer the active KEK of the provider.
+func (e *EnvelopeEncryptor) NeedsRotation(ciphertext string) bool {
+	envelope, isEnvelope := strings.CutPrefix(ciphertext, envelopePrefix)
+	if !isEnvelope {
+		return true
+	}
+
+	provider, ok := e.provider.(activeKEKProvider)
+	if !ok {
+		// The provider rotates its KEKs itself, e.g. Vault transit
+		return false
+	}
+
+	kekID, _, _ := strings.Cut(envelope, ":")
+	return kekID != provider.ActiveKEKID()
+}
+
+// activeKEKProvider is implemented by KEK providers that wrap data keys with one of several KEKs.
+type activeKEKProvider interface {
+	ActiveKEKID() string
+}
+
+// IsEnvelope reports whether the ciphertext was envelope encrypted.
+func IsEnvelope(ciphertext string) bool {
+	return strings.HasPrefix(ciphertext, envelopePrefix)
+}
+
+// KEKProviderFactory creates a KEK provider from its configuration section. The configuration may be nil.
+type KEKProviderFactory func(cfg *viper.Viper) (KEKProvider, error)
+
+var (
+	kekProvidersMu sync.RWMutex
+	kekProviders   = map[string]KEKProviderFactory{
+		"file":  newFileKEKProviderFromConfig,
+		"vault": newVaultTransitKEKProviderFromConfig,
+	}
+)
+
+// RegisterKEKProvider makes a KEK provider (e.g. a cloud KMS) available under the given name.
+func RegisterKEKProvider(name string, factory KEKProviderFactory) {
+	kekProvidersMu.Lock()
+	defer kekProvidersMu.Unlock()
+
+	kekProviders[name] = factory
+}
+
+// NewKEKProvider creates the registered KEK provider with the given name.
+func NewKEKProvider(name string, cfg *viper.Viper) (KEKProvider, error) {
+	kekProvidersMu.RLock()
+	factory, ok := kekProviders[name]
+	kekProvidersMu.RUnlock()
+
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownKEKProvider, "provider %q", name)
+	}
+
+	if cfg == nil {
+		cfg = viper.New()
+	}
+
+	return factory(cfg)
+}
@@ -0,0 +1,205 @@
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKEKFile(t *testing.T, active string, keys map[string]string) string {
+	t.Helper()
+
+	encoded := make(map[string]string, len(keys))
+	for keyID, key := range keys {
+		encoded[keyID] = base64.StdEncoding.EncodeToString([]byte(key))
+	}
+
+	data, err := json.Marshal(kekFile{Active: active, Keys: encoded})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func TestEnvelopeEncryptor_FileProvider(t *testing.T) {
+	provider, err := NewFileKEKProvider(writeKEKFile(t, "kek-1", map[string]string{"kek-1": oldKey}))
+	require.NoError(t, err)
+
+	encryptor := NewEnvelopeEncryptor(provider, nil)
+
+	ciphertext, err := encryptor.Encrypt("secret")
+	require.NoError(t, err)
+	assert.True(t, IsEnvelope(*ciphertext))
+	assert.True(t, strings.HasPrefix(*ciphertext, "@env:kek-1:"))
+	assert.NotContains(t, *ciphertext, "secret")
+
+	// Every record gets its own data key
+	other, err := encryptor.Encrypt("secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, strings.Split(*ciphertext, ":")[2], strings.Split(*other, ":")[2])
+
+	plaintext, err := encryptor.Decrypt(*ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", *plaintext)
+
+	// After the KEK is rotated, data keys wrapped by the old KEK can still be unwrapped
+	rotatedProvider, err := NewFileKEKProvider(writeKEKFile(t, "kek-2", map[string]string{"kek-1": oldKey, "kek-2": newKey}))
+	require.NoError(t, err)
+
+	rotatedEncryptor := NewEnvelopeEncryptor(rotatedProvider, nil)
+	plaintext, err = rotatedEncryptor.Decrypt(*ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", *plaintext)
+
+	rotated, err := rotatedEncryptor.Encrypt("secret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(*rotated, "@env:kek-2:"))
+
+	// Data keys wrapped by the old KEK are re-wrapped by rotate-keys
+	assert.True(t, rotatedEncryptor.NeedsRotation(*ciphertext))
+	assert.False(t, rotatedEncryptor.NeedsRotation(*rotated))
+
+	// Unknown KEK
+	newProvider, err := NewFileKEKProvider(writeKEKFile(t, "kek-2", map[string]string{"kek-2": newKey}))
+	require.NoError(t, err)
+
+	_, err = NewEnvelopeEncryptor(newProvider, nil).Decrypt(*ciphertext)
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+}
+
+func TestEnvelopeEncryptor_Fallback(t *testing.T) {
+	provider, err := NewFileKEKProvider(writeKEKFile(t, "kek-1", map[string]string{"kek-1": oldKey}))
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(map[string]string{"v1": newKey}, "v1")
+	require.NoError(t, err)
+
+	legacy, err := keyring.Encrypt("secret")
+	require.NoError(t, err)
+
+	// Without a fallback, non-envelope ciphertext is rejected
+	_, err = NewEnvelopeEncryptor(provider, nil).Decrypt(*legacy)
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	plaintext, err := NewEnvelopeEncryptor(provider, keyring).Decrypt(*legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", *plaintext)
+
+	// Secrets encrypted by the keyring are migrated to envelope encryption by rotate-keys
+	assert.True(t, NewEnvelopeEncryptor(provider, keyring).NeedsRotation(*legacy))
+}
+
+func TestNewEncryptorFromEnv_Provider(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("storage.encryption.provider", "file")
+	viper.Set("storage.encryption.file.path", writeKEKFile(t, "kek-1", map[string]string{"kek-1": oldKey}))
+	viper.Set("storage.encryption.key", newKey)
+
+	keyring, err := NewEncryptor(newKey)
+	require.NoError(t, err)
+
+	legacy, err := keyring.Encrypt("secret")
+	require.NoError(t, err)
+
+	encryptor, err := NewEncryptorFromEnv()
+	require.NoError(t, err)
+
+	// Secrets written with the single key before the provider was configured can still be decrypted
+	plaintext, err := encryptor.Decrypt(*legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", *plaintext)
+
+	ciphertext, err := encryptor.Encrypt("secret")
+	require.NoError(t, err)
+	assert.True(t, IsEnvelope(*ciphertext))
+}
+
+func TestEnvelopeEncryptor_Malformed(t *testing.T) {
+	provider, err := NewFileKEKProvider(writeKEKFile(t, "kek-1", map[string]string{"kek-1": oldKey}))
+	require.NoError(t, err)
+
+	encryptor := NewEnvelopeEncryptor(provider, nil)
+
+	_, err = encryptor.Decrypt("@env:kek-1")
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	_, err = encryptor.Decrypt("@env:kek-1:not base64:abc")
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+}
+
+func TestNewKEKProvider(t *testing.T) {
+	_, err := NewKEKProvider("unknown", nil)
+	assert.ErrorIs(t, err, ErrUnknownKEKProvider)
+
+	_, err = NewKEKProvider("file", nil)
+	assert.Error(t, err)
+}
+
+// fakeVault implements the encrypt and decrypt endpoints of the Vault transit engine by base64 encoding the plaintext.
+func fakeVault(t *testing.T, token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		body := map[string]string{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/scheduler":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]}})
+		case "/v1/transit/decrypt/scheduler":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+}
+
+func TestVaultTransitKEKProvider(t *testing.T) {
+	server := fakeVault(t, "token")
+	defer server.Close()
+
+	provider, err := NewVaultTransitKEKProvider(server.URL, "token", "", "scheduler")
+	require.NoError(t, err)
+
+	kekID, wrapped, err := provider.WrapKey(context.Background(), []byte("datakey"))
+	require.NoError(t, err)
+	assert.Equal(t, "scheduler", kekID)
+	assert.True(t, strings.HasPrefix(string(wrapped), "vault:v1:"))
+
+	dataKey, err := provider.UnwrapKey(context.Background(), kekID, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("datakey"), dataKey)
+
+	encryptor := NewEnvelopeEncryptor(provider, nil)
+	ciphertext, err := encryptor.Encrypt("secret")
+	require.NoError(t, err)
+
+	plaintext, err := encryptor.Decrypt(*ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", *plaintext)
+
+	// Invalid token
+	provider, err = NewVaultTransitKEKProvider(server.URL, "invalid", "transit", "scheduler")
+	require.NoError(t, err)
+
+	_, _, err = provider.WrapKey(context.Background(), []byte("datakey"))
+	assert.ErrorContains(t, err, "permission denied")
+}
@@ -0,0 +1,16 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignHMAC returns the hex encoded HMAC-SHA256 of the fields joined with "." with the secret. It signs both the
+// requests of HTTP jobs and webhook deliveries.
+func SignHMAC(secret string, fields ...string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(fields, ".")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,14 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignHMAC(t *testing.T) {
+	assert.Equal(t, "6c4a54ce257887111c46048d39048111ee80ecd0dbae79aa72b6c0a80864b742", SignHMAC("secret", "1700000000", "POST", "/hooks", "payload"))
+
+	// webhook deliveries are signed with the same helper
+	assert.Equal(t, SignHMAC("whsec_test", "1700000000", "{}"), SignWebhook("whsec_test", "1700000000", []byte("{}")))
+}
@@ -0,0 +1,93 @@
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// FileKEKProvider wraps data keys with KEKs read from a local JSON file. It is meant for local
+// development and testing; in production the KEKs should be kept in Vault or a cloud KMS.
+//
+// The file has the following format, where keys are base64 encoded 16, 24 or 32 byte AES keys:
+//
+//	{"active": "kek-2", "keys": {"kek-1": "...", "kek-2": "..."}}
+type FileKEKProvider struct {
+	keyring *Keyring
+}
+
+type kekFile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"`
+}
+
+// NewFileKEKProvider reads the KEKs from the file at the given path.
+func NewFileKEKProvider(path string) (*FileKEKProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read KEK file")
+	}
+
+	file := kekFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, errors.Wrap(err, "failed to parse KEK file")
+	}
+
+	keys := make(map[string]string, len(file.Keys))
+	for keyID, encodedKey := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "KEK %q is not base64 encoded", keyID)
+		}
+
+		keys[keyID] = string(key)
+	}
+
+	keyring, err := NewKeyring(keys, file.Active)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileKEKProvider{keyring: keyring}, nil
+}
+
+func newFileKEKProviderFromConfig(cfg *viper.Viper) (KEKProvider, error) {
+	path := cfg.GetString("path")
+	if path == "" {
+		return nil, errors.New("storage.encryption.file.path must be set")
+	}
+
+	return NewFileKEKProvider(path)
+}
+
+// ActiveKEKID returns the ID of the KEK used to wrap data keys.
+func (p *FileKEKProvider) ActiveKEKID() string {
+	return p.keyring.activeKeyID
+}
+
+func (p *FileKEKProvider) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := p.keyring.keys[p.keyring.activeKeyID].Encrypt(string(dataKey))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return p.keyring.activeKeyID, []byte(*wrapped), nil
+}
+
+func (p *FileKEKProvider) UnwrapKey(_ context.Context, kekID string, wrappedKey []byte) ([]byte, error) {
+	kek, ok := p.keyring.keys[kekID]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownKeyID, "KEK %q", kekID)
+	}
+
+	dataKey, err := kek.Decrypt(string(wrappedKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(*dataKey), nil
+}
@@ -0,0 +1,124 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// VaultTransitKEKProvider wraps data keys using the HashiCorp Vault transit secrets engine.
+// The KEK never leaves Vault; Vault handles versioning of the key itself.
+type VaultTransitKEKProvider struct {
+	address string
+	token   string
+	mount   string
+	keyName string
+	client  *http.Client
+}
+
+// NewVaultTransitKEKProvider creates a provider using the transit key keyName mounted at mount (usually "transit").
+func NewVaultTransitKEKProvider(address, token, mount, keyName string) (*VaultTransitKEKProvider, error) {
+	if address == "" || token == "" || keyName == "" {
+		return nil, errors.New("vault address, token and key name must be set")
+	}
+
+	if mount == "" {
+		mount = "transit"
+	}
+
+	return &VaultTransitKEKProvider{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		mount:   strings.Trim(mount, "/"),
+		keyName: keyName,
+		client:  &http.Client{Timeout: kekProviderTimeout},
+	}, nil
+}
+
+// newVaultTransitKEKProviderFromConfig falls back to the standard VAULT_ADDR and VAULT_TOKEN environment variables,
+// so the token doesn't have to be stored in the configuration file.
+func newVaultTransitKEKProviderFromConfig(cfg *viper.Viper) (KEKProvider, error) {
+	address := cfg.GetString("address")
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+
+	token := cfg.GetString("token")
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+
+	return NewVaultTransitKEKProvider(address, token, cfg.GetString("mount"), cfg.GetString("key"))
+}
+
+type vaultResponse struct {
+	Data struct {
+		Ciphertext string `json:"ciphertext"`
+		Plaintext  string `json:"plaintext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+func (p *VaultTransitKEKProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	response, err := p.call(ctx, "encrypt", p.keyName, map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return p.keyName, []byte(response.Data.Ciphertext), nil
+}
+
+func (p *VaultTransitKEKProvider) UnwrapKey(ctx context.Context, kekID string, wrappedKey []byte) ([]byte, error) {
+	response, err := p.call(ctx, "decrypt", kekID, map[string]string{
+		"ciphertext": string(wrappedKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(response.Data.Plaintext)
+}
+
+func (p *VaultTransitKEKProvider) call(ctx context.Context, operation, keyName string, body map[string]string) (*vaultResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s/%s/%s", p.address, p.mount, operation, url.PathEscape(keyName))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", p.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "vault transit %s failed", operation)
+	}
+	defer resp.Body.Close()
+
+	response := &vaultResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode vault transit %s response", operation)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault transit %s failed with status %d: %s", operation, resp.StatusCode, strings.Join(response.Errors, ", "))
+	}
+
+	return response, nil
+}
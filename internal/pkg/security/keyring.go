@@ -0,0 +1,120 @@
+package security
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// keyIDSeparator separates the key ID from the base64 encoded ciphertext.
+// It is not part of the base64 alphabet, so it can't appear in legacy ciphertext.
+const keyIDSeparator = ":"
+
+var keyIDRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+var (
+	ErrUnknownKeyID     = errors.New("ciphertext was encrypted with an unknown key")
+	ErrNoActiveKey      = errors.New("active encryption key is not part of the keyring")
+	ErrInvalidKeyID     = errors.New("key ID must be 1-64 characters long and contain only letters, digits, '_' or '-'")
+	ErrUndecryptableKey = errors.New("ciphertext could not be decrypted with any known key")
+)
+
+// Keyring is an Encryptor holding multiple versioned keys. It always encrypts with the active key and
+// prefixes the ciphertext with the key ID, so the ciphertext can be decrypted after the active key changes.
+// Ciphertext without a key ID (written before key IDs were introduced) is decrypted by trying every key.
+type Keyring struct {
+	activeKeyID string
+	keys        map[string]*encryptor
+
+	// sorted key IDs, so legacy decryption is deterministic
+	keyIDs []string
+}
+
+// NewKeyring creates a keyring from key ID -> secret key pairs.
+func NewKeyring(keys map[string]string, activeKeyID string) (*Keyring, error) {
+	keyring := &Keyring{
+		activeKeyID: activeKeyID,
+		keys:        make(map[string]*encryptor, len(keys)),
+	}
+
+	for keyID, secretKey := range keys {
+		if !keyIDRegex.MatchString(keyID) {
+			return nil, errors.Wrapf(ErrInvalidKeyID, "key %q", keyID)
+		}
+
+		e, err := newEncryptor(secretKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid encryption key %q", keyID)
+		}
+
+		keyring.keys[keyID] = e
+		keyring.keyIDs = append(keyring.keyIDs, keyID)
+	}
+
+	if _, ok := keyring.keys[activeKeyID]; !ok {
+		return nil, errors.Wrapf(ErrNoActiveKey, "key %q", activeKeyID)
+	}
+
+	sort.Strings(keyring.keyIDs)
+
+	return keyring, nil
+}
+
+// ActiveKeyID returns the ID of the key used for encryption.
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeKeyID
+}
+
+func (k *Keyring) Encrypt(plaintext string) (*string, error) {
+	ciphertext, err := k.keys[k.activeKeyID].Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.ToPtr(k.activeKeyID + keyIDSeparator + *ciphertext), nil
+}
+
+func (k *Keyring) Decrypt(ciphertext string) (*string, error) {
+	keyID, encrypted, hasKeyID := strings.Cut(ciphertext, keyIDSeparator)
+	if !hasKeyID {
+		return k.decryptLegacy(ciphertext)
+	}
+
+	e, ok := k.keys[keyID]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownKeyID, "key %q", keyID)
+	}
+
+	return e.Decrypt(encrypted)
+}
+
+// decryptLegacy decrypts ciphertext without a key ID by trying each key.
+// GCM authenticates the ciphertext, so a wrong key never yields a plaintext.
+func (k *Keyring) decryptLegacy(ciphertext string) (*string, error) {
+	for _, keyID := range k.keyIDs {
+		plaintext, err := k.keys[keyID].Decrypt(ciphertext)
+		if err == nil {
+			return plaintext, nil
+		}
+	}
+
+	return nil, ErrUndecryptableKey
+}
+
+// KeyID returns the ID of the key the ciphertext was encrypted with, or an empty string for legacy ciphertext.
+func KeyID(ciphertext string) string {
+	keyID, _, hasKeyID := strings.Cut(ciphertext, keyIDSeparator)
+	if !hasKeyID {
+		return ""
+	}
+
+	return keyID
+}
+
+// NeedsRotation reports whether the ciphertext was not encrypted with the active key.
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	return KeyID(ciphertext) != k.activeKeyID
+}
@@ -0,0 +1,84 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	oldKey = "N1PCdw3M2B1TfJhoaY2mL736p2vCUc47"
+	newKey = "4x9V8mBqTz1LrP0wKc7YhD2sJf6NgE3u"
+)
+
+func TestKeyring_Rotation(t *testing.T) {
+	oldKeyring, err := NewKeyring(map[string]string{"v1": oldKey}, "v1")
+	require.NoError(t, err)
+
+	ciphertext, err := oldKeyring.Encrypt("secret")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", KeyID(*ciphertext))
+
+	// Add a new key and make it active
+	keyring, err := NewKeyring(map[string]string{"v1": oldKey, "v2": newKey}, "v2")
+	require.NoError(t, err)
+
+	// Old ciphertext can still be decrypted
+	plaintext, err := keyring.Decrypt(*ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", *plaintext)
+	assert.True(t, keyring.NeedsRotation(*ciphertext))
+
+	// New ciphertext uses the active key
+	rotated, err := keyring.Encrypt(*plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", KeyID(*rotated))
+	assert.False(t, keyring.NeedsRotation(*rotated))
+
+	// Once the old key is removed, old ciphertext can't be decrypted anymore
+	newKeyring, err := NewKeyring(map[string]string{"v2": newKey}, "v2")
+	require.NoError(t, err)
+
+	_, err = newKeyring.Decrypt(*ciphertext)
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+
+	plaintext, err = newKeyring.Decrypt(*rotated)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", *plaintext)
+}
+
+func TestKeyring_LegacyCiphertext(t *testing.T) {
+	legacy, err := newEncryptor(oldKey)
+	require.NoError(t, err)
+
+	// Ciphertext written before key IDs were introduced
+	ciphertext, err := legacy.Encrypt("secret")
+	require.NoError(t, err)
+	assert.Equal(t, "", KeyID(*ciphertext))
+
+	keyring, err := NewKeyring(map[string]string{"v1": oldKey, "v2": newKey}, "v2")
+	require.NoError(t, err)
+
+	plaintext, err := keyring.Decrypt(*ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", *plaintext)
+	assert.True(t, keyring.NeedsRotation(*ciphertext))
+
+	keyring, err = NewKeyring(map[string]string{"v2": newKey}, "v2")
+	require.NoError(t, err)
+
+	_, err = keyring.Decrypt(*ciphertext)
+	assert.ErrorIs(t, err, ErrUndecryptableKey)
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	_, err := NewKeyring(map[string]string{"v1": oldKey}, "v2")
+	assert.ErrorIs(t, err, ErrNoActiveKey)
+
+	_, err = NewKeyring(map[string]string{"v:1": oldKey}, "v:1")
+	assert.ErrorIs(t, err, ErrInvalidKeyID)
+
+	_, err = NewKeyring(map[string]string{"v1": "tooshort"}, "v1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package security
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// insecureDefaultKeys are keys that were shipped as defaults or in example configurations.
+var insecureDefaultKeys = []string{"ishouldreallybechanged", "ishouldbechanged", DevModeKey}
+
+// DevModeKey is used when no encryption key is configured in dev mode. It must never be used in production.
+const DevModeKey = "scheduler-insecure-dev-mode-key!"
+
+// minKeyEntropy is the minimum Shannon entropy (in bits per byte) of an encryption key.
+// Random printable keys score well above 3.5, while keys like "aaaaaaaaaaaaaaaa" or "1212121212121212" fall below.
+const minKeyEntropy = 3.0
+
+var (
+	ErrMissingEncryptionKey = errors.New("no encryption key is configured: set storage.encryption.key, storage.encryption.keys or storage.encryption.provider")
+	ErrInvalidKeyLength     = errors.New("encryption key must be exactly 16, 24 or 32 bytes long (AES-128, AES-192 or AES-256)")
+	ErrInsecureDefaultKey   = errors.New("encryption key is a well-known default and must be replaced with a randomly generated key (e.g. `openssl rand -base64 24`); set storage.encryption.devMode to allow it for local development")
+	ErrLowEntropyKey        = errors.New("encryption key is too predictable and must be replaced with a randomly generated key (e.g. `openssl rand -base64 24`); set storage.encryption.devMode to allow it for local development")
+)
+
+// ValidateKey checks that the key can be used for AES and is not a default or trivially guessable key.
+// Default and low entropy keys are accepted in dev mode.
+func ValidateKey(keyID, key string, devMode bool) error {
+	if key == "" {
+		return errors.Wrapf(ErrMissingEncryptionKey, "key %q is empty", keyID)
+	}
+
+	// Checked before the length, as some of the old default keys don't have a valid length either
+	if !devMode && lo.Contains(insecureDefaultKeys, key) {
+		return errors.Wrapf(ErrInsecureDefaultKey, "key %q", keyID)
+	}
+
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return errors.Wrapf(ErrInvalidKeyLength, "key %q is %d bytes long", keyID, len(key))
+	}
+
+	if devMode {
+		return nil
+	}
+
+	if entropy := keyEntropy(key); entropy < minKeyEntropy {
+		return errors.Wrapf(ErrLowEntropyKey, "key %q has %.2f bits of entropy per byte", keyID, entropy)
+	}
+
+	return nil
+}
+
+// ValidateKeys validates every key of a keyring.
+func ValidateKeys(keys map[string]string, devMode bool) error {
+	for keyID, key := range keys {
+		if err := ValidateKey(keyID, key, devMode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keyEntropy returns the Shannon entropy of the key in bits per byte.
+func keyEntropy(key string) float64 {
+	counts := make(map[byte]int)
+	for i := 0; i < len(key); i++ {
+		counts[key[i]]++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(len(key))
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
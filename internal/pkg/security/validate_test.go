@@ -0,0 +1,82 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		devMode bool
+		wantErr error
+	}{
+		{
+			name: "Valid 32 byte key",
+			key:  newKey,
+		},
+		{
+			name: "Valid 16 byte key",
+			key:  "h7Qz2LmX9pW4vKc1",
+		},
+		{
+			name:    "Empty key",
+			key:     "",
+			wantErr: ErrMissingEncryptionKey,
+		},
+		{
+			name:    "Invalid length",
+			key:     "tooshort",
+			wantErr: ErrInvalidKeyLength,
+		},
+		{
+			name:    "Old default key",
+			key:     "ishouldreallybechanged",
+			wantErr: ErrInsecureDefaultKey,
+		},
+		{
+			name:    "Old default key has an invalid length",
+			key:     "ishouldreallybechanged",
+			devMode: true,
+			wantErr: ErrInvalidKeyLength,
+		},
+		{
+			name:    "Default key",
+			key:     "ishouldbechanged",
+			wantErr: ErrInsecureDefaultKey,
+		},
+		{
+			name:    "Default key in dev mode",
+			key:     "ishouldbechanged",
+			devMode: true,
+		},
+		{
+			name:    "Dev mode key outside of dev mode",
+			key:     DevModeKey,
+			wantErr: ErrInsecureDefaultKey,
+		},
+		{
+			name:    "Low entropy key",
+			key:     "aaaaaaaabbbbbbbb",
+			wantErr: ErrLowEntropyKey,
+		},
+		{
+			name:    "Low entropy key in dev mode",
+			key:     "aaaaaaaabbbbbbbb",
+			devMode: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKey("default", tt.key, tt.devMode)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package security
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// WebhookSecretPrefix starts every webhook signing secret.
+const WebhookSecretPrefix = "whsec_"
+
+// GenerateWebhookSecret generates a new secret for signing webhook deliveries.
+func GenerateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// SignWebhook returns the hex encoded HMAC-SHA256 of "timestamp.body" with the secret. Receivers should recompute the
+// signature the same way, compare it in constant time and reject stale timestamps.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	return SignHMAC(secret, timestamp, string(body))
+}
@@ -0,0 +1,31 @@
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateWebhookSecret(t *testing.T) {
+	secret, err := GenerateWebhookSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, WebhookSecretPrefix))
+
+	other, err := GenerateWebhookSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"type":"job.created"}`)
+
+	signature := SignWebhook("whsec_test", "1700000000", body)
+	assert.Len(t, signature, 64)
+	assert.Equal(t, signature, SignWebhook("whsec_test", "1700000000", body))
+
+	assert.NotEqual(t, signature, SignWebhook("whsec_other", "1700000000", body))
+	assert.NotEqual(t, signature, SignWebhook("whsec_test", "1700000001", body))
+	assert.NotEqual(t, signature, SignWebhook("whsec_test", "1700000000", []byte(`{"type":"job.deleted"}`)))
+}
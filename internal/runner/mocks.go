@@ -33,7 +33,7 @@ func (m *mockJobService) GetJobsToRun(_ context.Context, _ time.Time, _ time.Tim
 	return jobs, nil
 }
 
-func (m *mockJobService) FinishJobExecution(ctx context.Context, job *model.Job, _, _ time.Time, _ error) error {
+func (m *mockJobService) FinishJobExecution(ctx context.Context, job *model.Job, _ string, _, _ time.Time, _ error) error {
 	m.Lock()
 	defer m.Unlock()
 	if m.FinErr != nil {
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -16,6 +17,9 @@ import (
 type Runner struct {
 	jobService JobService
 
+	// Resolves named credentials referenced by jobs, right before execution
+	credentialResolver CredentialResolver
+
 	// Runner metrics
 	metrics *metrics.RunnerMetrics
 
@@ -51,15 +55,21 @@ type Runner struct {
 
 type JobService interface {
 	GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error)
-	FinishJobExecution(ctx context.Context, job *model.Job, startTime, stopTime time.Time, err error) error
+	FinishJobExecution(ctx context.Context, job *model.Job, instanceID string, startTime, stopTime time.Time, err error) error
+}
+
+// CredentialResolver replaces the named credential references of a job with the actual secrets.
+type CredentialResolver interface {
+	ResolveJobCredentials(ctx context.Context, job *model.Job) error
 }
 
 type Config struct {
-	JobService      JobService
-	Metrics         *metrics.RunnerMetrics
-	ExecutorFactory executor.Factory
-	Log             *otelzap.Logger
-	InstanceId      string
+	JobService         JobService
+	CredentialResolver CredentialResolver
+	Metrics            *metrics.RunnerMetrics
+	ExecutorFactory    executor.Factory
+	Log                *otelzap.Logger
+	InstanceId         string
 
 	JobExecution JobExecutionSettings
 }
@@ -74,17 +84,18 @@ func New(cfg Config) *Runner {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	s := &Runner{
-		jobService:        cfg.JobService,
-		metrics:           cfg.Metrics,
-		instanceId:        cfg.InstanceId,
-		log:               cfg.Log,
-		ticker:            time.NewTicker(cfg.JobExecution.Interval),
-		ctx:               ctx,
-		executorFactory:   cfg.ExecutorFactory,
-		cancel:            cancel,
-		jobSemaphore:      make(chan struct{}, cfg.JobExecution.MaxConcurrentJobs),
-		maxConcurrentJobs: cfg.JobExecution.MaxConcurrentJobs,
-		jobLockDuration:   cfg.JobExecution.MaxJobLockTime,
+		jobService:         cfg.JobService,
+		credentialResolver: cfg.CredentialResolver,
+		metrics:            cfg.Metrics,
+		instanceId:         cfg.InstanceId,
+		log:                cfg.Log,
+		ticker:             time.NewTicker(cfg.JobExecution.Interval),
+		ctx:                ctx,
+		executorFactory:    cfg.ExecutorFactory,
+		cancel:             cancel,
+		jobSemaphore:       make(chan struct{}, cfg.JobExecution.MaxConcurrentJobs),
+		maxConcurrentJobs:  cfg.JobExecution.MaxConcurrentJobs,
+		jobLockDuration:    cfg.JobExecution.MaxJobLockTime,
 	}
 
 	s.stopWg.Add(1)
@@ -198,6 +209,20 @@ func (s *Runner) executeJob(job *model.Job) {
 
 		s.log.Debug("Executing job", zap.Any("jobID", job.ID))
 
+		// Resolve the credentials at execution time, so rotated secrets are picked up immediately
+		if s.credentialResolver != nil {
+			if err := s.credentialResolver.ResolveJobCredentials(s.ctx, job); err != nil {
+				s.log.Error("Failed to resolve job credentials", zap.Any("jobID", job.ID), zap.Error(err))
+
+				now := time.Now()
+				err = s.jobService.FinishJobExecution(s.ctx, job, s.instanceId, now, now, fmt.Errorf("failed to resolve credentials: %w", err))
+				if err != nil {
+					s.log.Error("Failed to report job as finished", zap.Any("jobID", job.ID), zap.Error(err))
+				}
+				return
+			}
+		}
+
 		// Create a new executor for the job with retry enabled
 		jobExecutor, err := s.executorFactory.NewExecutor(job, executor.WithRetry)
 		if err != nil {
@@ -229,7 +254,7 @@ func (s *Runner) executeJob(job *model.Job) {
 		}
 
 		// Report the job as finished
-		err = s.jobService.FinishJobExecution(s.ctx, job, startTime, stopTime, err)
+		err = s.jobService.FinishJobExecution(s.ctx, job, s.instanceId, startTime, stopTime, err)
 		if err != nil {
 			s.log.Error("Failed to report job as finished", zap.Any("jobID", job.ID), zap.Error(err))
 		}
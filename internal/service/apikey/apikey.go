@@ -0,0 +1,117 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+// lastUsedResolution limits how often the last used time of a key is written, so every request doesn't cause a write.
+const lastUsedResolution = time.Minute
+
+// Service issues, revokes and authenticates API keys.
+type Service struct {
+	store store.Storer
+	log   *otelzap.Logger
+}
+
+// NewService creates a new API key service with the given store and logger.
+func NewService(store store.Storer, log *otelzap.Logger) *Service {
+	return &Service{
+		store: store,
+		log:   log,
+	}
+}
+
+// CreateAPIKey issues a new API key for the namespace. The returned key is not stored and can't be retrieved again.
+func (s *Service) CreateAPIKey(ctx context.Context, namespace string, create model.APIKeyCreate) (*model.CreatedAPIKey, error) {
+	s.log.Info("Creating API key", zap.String("namespace", namespace), zap.String("name", create.Name), zap.String("role", string(create.Role)))
+
+	if err := model.ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	if err := create.Validate(); err != nil {
+		return nil, err
+	}
+
+	role := create.Role
+	if role == "" {
+		role = model.RoleViewer
+	}
+
+	key, prefix, err := security.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := model.APIKey{
+		ID:        uuid.New(),
+		Namespace: namespace,
+		Name:      create.Name,
+		Prefix:    prefix,
+		Hash:      security.HashAPIKey(key),
+		Role:      role,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.store.CreateAPIKey(ctx, &apiKey); err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// ListAPIKeys returns a list of API keys of the namespace with the given limit and offset.
+func (s *Service) ListAPIKeys(ctx context.Context, namespace string, limit, offset uint64) ([]model.APIKey, error) {
+	s.log.Info("Getting API keys", zap.String("namespace", namespace))
+	return s.store.ListAPIKeys(ctx, namespace, limit, offset)
+}
+
+// RevokeAPIKey revokes the API key of the namespace with the given ID. Revoked keys can't be used anymore.
+func (s *Service) RevokeAPIKey(ctx context.Context, namespace string, id uuid.UUID) error {
+	s.log.Info("Revoking API key", zap.String("namespace", namespace), zap.String("id", id.String()))
+	return s.store.RevokeAPIKey(ctx, namespace, id, time.Now())
+}
+
+// Authenticate returns the API key matching the given key. Unknown, malformed and revoked keys are rejected
+// with ErrInvalidAPIKey.
+func (s *Service) Authenticate(ctx context.Context, key string) (*model.APIKey, error) {
+	prefix, ok := security.ParseAPIKeyPrefix(key)
+	if !ok {
+		return nil, errs.ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.store.GetAPIKeyByPrefix(ctx, prefix)
+	switch {
+	case errors.Is(err, errs.ErrAPIKeyNotFound):
+		return nil, errs.ErrInvalidAPIKey
+	case err != nil:
+		return nil, err
+	}
+
+	if apiKey.Revoked() || !security.VerifyAPIKey(key, apiKey.Hash) {
+		return nil, errs.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !apiKey.LastUsedAt.Valid || now.Sub(apiKey.LastUsedAt.Time) > lastUsedResolution {
+		if err := s.store.UpdateAPIKeyLastUsed(ctx, apiKey.ID, now); err != nil {
+			// Not being able to track usage shouldn't lock clients out
+			s.log.Warn("Failed to update API key last used time", zap.String("prefix", apiKey.Prefix), zap.Error(err))
+		} else {
+			apiKey.LastUsedAt = null.TimeFrom(now)
+		}
+	}
+
+	return apiKey, nil
+}
@@ -0,0 +1,100 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database/dbtest"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/tests/docker"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = dbtest.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer dbtest.StopDB(c)
+
+	m.Run()
+}
+
+func Test_APIKey(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	service := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create
+	// -------------------------------------------------------------------------
+
+	_, err := service.CreateAPIKey(ctx, model.DefaultNamespace, model.APIKeyCreate{})
+	if !errors.Is(err, errs.ErrInvalidAPIKeyName) {
+		t.Fatalf("Should not be able to create an API key without a name: %v", err)
+	}
+
+	created, err := service.CreateAPIKey(ctx, model.DefaultNamespace, model.APIKeyCreate{Name: "ci"})
+	if err != nil {
+		t.Fatalf("Should be able to create an API key: %s", err)
+	}
+
+	// Authenticate
+	// -------------------------------------------------------------------------
+
+	apiKey, err := service.Authenticate(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("Should be able to authenticate with the API key: %s", err)
+	}
+
+	if apiKey.ID != created.ID || !apiKey.LastUsedAt.Valid {
+		t.Fatalf("Should return the API key with its last used time: %+v", apiKey)
+	}
+
+	for _, key := range []string{"", "invalid", created.Prefix + "_wrongsecret", "dsk_unknown_secret"} {
+		if _, err := service.Authenticate(ctx, key); !errors.Is(err, errs.ErrInvalidAPIKey) {
+			t.Fatalf("Should not be able to authenticate with %q: %v", key, err)
+		}
+	}
+
+	// List
+	// -------------------------------------------------------------------------
+
+	apiKeys, err := service.ListAPIKeys(ctx, model.DefaultNamespace, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to list API keys: %s", err)
+	}
+
+	if len(apiKeys) != 1 || apiKeys[0].Prefix != created.Prefix || apiKeys[0].Hash == created.Key {
+		t.Fatalf("Should list the API key by its prefix: %+v", apiKeys)
+	}
+
+	// Revoke
+	// -------------------------------------------------------------------------
+
+	if err := service.RevokeAPIKey(ctx, model.DefaultNamespace, created.ID); err != nil {
+		t.Fatalf("Should be able to revoke the API key: %s", err)
+	}
+
+	if _, err := service.Authenticate(ctx, created.Key); !errors.Is(err, errs.ErrInvalidAPIKey) {
+		t.Fatalf("Should not be able to authenticate with a revoked API key: %v", err)
+	}
+}
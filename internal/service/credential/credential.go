@@ -0,0 +1,112 @@
+package credential
+
+import (
+	"context"
+	"time"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store"
+	"go.uber.org/zap"
+)
+
+// Service manages named credentials and resolves them for jobs.
+type Service struct {
+	store store.Storer
+	log   *otelzap.Logger
+}
+
+// NewService creates a new credential service with the given store and logger.
+func NewService(store store.Storer, log *otelzap.Logger) *Service {
+	return &Service{
+		store: store,
+		log:   log,
+	}
+}
+
+// CreateCredential validates and stores a new credential in the namespace.
+func (s *Service) CreateCredential(ctx context.Context, namespace string, credential *model.Credential) (*model.Credential, error) {
+	s.log.Info("Creating credential", zap.String("namespace", namespace), zap.String("name", credential.Name), zap.String("type", string(credential.Type)))
+
+	credential.Namespace = namespace
+	credential.CreatedAt = time.Now()
+	credential.UpdatedAt = credential.CreatedAt
+
+	if err := credential.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.CreateCredential(ctx, credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// GetCredential returns the credential with the given name from the namespace.
+func (s *Service) GetCredential(ctx context.Context, namespace, name string) (*model.Credential, error) {
+	s.log.Info("Getting a credential", zap.String("namespace", namespace), zap.String("name", name))
+	return s.store.GetCredential(ctx, namespace, name)
+}
+
+// ListCredentials returns a list of credentials of the namespace with the given limit and offset.
+func (s *Service) ListCredentials(ctx context.Context, namespace string, limit, offset uint64) ([]model.Credential, error) {
+	s.log.Info("Getting credentials", zap.String("namespace", namespace))
+	return s.store.ListCredentials(ctx, namespace, limit, offset)
+}
+
+// UpdateCredential replaces the secrets of the credential with the given name.
+// Jobs referencing the credential pick up the new secrets on their next execution.
+func (s *Service) UpdateCredential(ctx context.Context, namespace, name string, update model.CredentialUpdate) (*model.Credential, error) {
+	s.log.Info("Updating a credential", zap.String("namespace", namespace), zap.String("name", name))
+
+	credential, err := s.store.GetCredential(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	credential.ApplyUpdate(update)
+
+	if err := credential.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateCredential(ctx, credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// DeleteCredential deletes the credential with the given name from the namespace, if no job references it.
+func (s *Service) DeleteCredential(ctx context.Context, namespace, name string) error {
+	s.log.Info("Deleting a credential", zap.String("namespace", namespace), zap.String("name", name))
+	return s.store.DeleteCredential(ctx, namespace, name)
+}
+
+// ResolveJobCredentials replaces the job's credential references with the referenced secrets of the job's namespace.
+func (s *Service) ResolveJobCredentials(ctx context.Context, job *model.Job) error {
+	if job.HTTPJob != nil && job.HTTPJob.Auth.Credential.Valid {
+		credential, err := s.store.GetCredential(ctx, job.Namespace, job.HTTPJob.Auth.Credential.String)
+		if err != nil {
+			return err
+		}
+
+		if err := credential.ApplyToAuth(&job.HTTPJob.Auth); err != nil {
+			return err
+		}
+	}
+
+	if job.AMQPJob != nil && job.AMQPJob.ConnectionCredential != nil {
+		credential, err := s.store.GetCredential(ctx, job.Namespace, *job.AMQPJob.ConnectionCredential)
+		if err != nil {
+			return err
+		}
+
+		if err := credential.ApplyToAMQPJob(job.AMQPJob); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
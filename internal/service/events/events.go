@@ -0,0 +1,285 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRetention is how long events are kept for resuming streams.
+	DefaultRetention = 24 * time.Hour
+
+	// subscriptionBuffer is how many events a subscriber can fall behind before its subscription is closed. The
+	// subscriber can resume after the last event it received.
+	subscriptionBuffer = 100
+
+	// replayPageSize is the number of events read at once when a subscription resumes
+	replayPageSize = 500
+
+	cleanupInterval = 10 * time.Minute
+)
+
+// Listener calls notify with the payload of every notification sent on the channel, until the context is done or
+// the connection fails. listening is called once notifications are received.
+type Listener interface {
+	Listen(ctx context.Context, channel string, listening func(), notify func(payload string)) error
+}
+
+// Service streams execution events to subscribers. The store notifies the listener of every event it creates, and
+// the service fans the events out to the subscribers of their namespace.
+type Service struct {
+	store     store.Storer
+	listener  Listener
+	log       *otelzap.Logger
+	retention time.Duration
+
+	mu            sync.Mutex
+	listening     bool
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewService creates a new event service with the given store, listener and logger.
+func NewService(store store.Storer, listener Listener, log *otelzap.Logger) *Service {
+	return &Service{
+		store:         store,
+		listener:      listener,
+		log:           log,
+		retention:     DefaultRetention,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// WithRetention sets how long events are kept for resuming streams. A non-positive retention keeps the default.
+func (s *Service) WithRetention(retention time.Duration) *Service {
+	if retention > 0 {
+		s.retention = retention
+	}
+
+	return s
+}
+
+// Run listens for events and deletes expired events until the context is done. If the connection of the listener
+// fails, all subscriptions are closed, as they could miss events, and the listener reconnects.
+func (s *Service) Run(ctx context.Context) {
+	go s.cleanup(ctx)
+
+	retry := backoff.NewExponentialBackOff()
+	retry.MaxElapsedTime = 0
+
+	for {
+		err := s.listener.Listen(ctx, store.ExecutionEventsChannel, func() {
+			retry.Reset()
+			s.setListening(true)
+			s.log.Info("Listening for execution events")
+		}, func(payload string) {
+			s.dispatch(ctx, payload)
+		})
+
+		s.setListening(false)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := retry.NextBackOff()
+		s.log.Warn("Stopped listening for execution events, reconnecting", zap.Error(err), zap.Duration("wait", wait))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Subscribe subscribes to the events of the namespace, or of a job of the namespace if the job ID is set. If the last
+// event ID is set, the events emitted after it are replayed first. ErrEventStreamUnavailable is returned while the
+// service isn't listening for events.
+func (s *Service) Subscribe(ctx context.Context, namespace string, jobID uuid.NullUUID, lastEventID int64) (*Subscription, error) {
+	s.log.Info("Subscribing to execution events", zap.String("namespace", namespace), zap.Any("jobID", jobID), zap.Int64("lastEventID", lastEventID))
+
+	subscription := &Subscription{
+		namespace: namespace,
+		jobID:     jobID,
+		events:    make(chan model.ExecutionEvent, subscriptionBuffer),
+		replayed:  make(map[int64]struct{}),
+	}
+
+	s.mu.Lock()
+	if !s.listening {
+		s.mu.Unlock()
+		return nil, errs.ErrEventStreamUnavailable
+	}
+	s.subscriptions[subscription] = struct{}{}
+	s.mu.Unlock()
+
+	if lastEventID > 0 {
+		if err := s.replay(ctx, subscription, lastEventID); err != nil {
+			s.Unsubscribe(subscription)
+			return nil, err
+		}
+	}
+
+	return subscription, nil
+}
+
+// replay reads the stored events emitted after the last event ID, and the events emitted before it that were committed
+// after it. The subscription already receives new events while they are read; events received twice are skipped.
+func (s *Service) replay(ctx context.Context, subscription *Subscription, lastEventID int64) error {
+	afterID := lastEventID
+	for {
+		events, err := s.store.ListExecutionEvents(ctx, subscription.namespace, subscription.jobID, afterID, replayPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			afterID = max(afterID, event.ID)
+
+			// every page can return late events already returned by the previous pages
+			if _, ok := subscription.replayed[event.ID]; ok {
+				continue
+			}
+
+			subscription.replay = append(subscription.replay, event)
+			subscription.replayed[event.ID] = struct{}{}
+		}
+
+		if len(events) < replayPageSize {
+			return nil
+		}
+	}
+}
+
+// Unsubscribe closes the subscription.
+func (s *Service) Unsubscribe(subscription *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.close(subscription)
+}
+
+// close closes the subscription, if it isn't closed yet. The lock must be held.
+func (s *Service) close(subscription *Subscription) {
+	if _, ok := s.subscriptions[subscription]; ok {
+		delete(s.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+func (s *Service) setListening(listening bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listening = listening
+	if !listening {
+		for subscription := range s.subscriptions {
+			s.close(subscription)
+		}
+	}
+}
+
+// dispatch sends the event with the ID of the notification payload to the subscriptions of its namespace and job.
+// Subscriptions that fell too far behind are closed.
+func (s *Service) dispatch(ctx context.Context, payload string) {
+	s.mu.Lock()
+	subscribed := len(s.subscriptions) > 0
+	s.mu.Unlock()
+
+	if !subscribed {
+		return
+	}
+
+	id, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		s.log.Warn("Received an invalid execution event notification", zap.String("payload", payload))
+		return
+	}
+
+	event, err := s.store.GetExecutionEvent(ctx, id)
+	if err != nil || event == nil {
+		s.log.Warn("Failed to get execution event", zap.Int64("id", id), zap.Error(err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for subscription := range s.subscriptions {
+		if !event.Matches(subscription.namespace, subscription.jobID) {
+			continue
+		}
+
+		select {
+		case subscription.events <- *event:
+		default:
+			s.log.Warn("Closing a subscription that fell behind", zap.String("namespace", subscription.namespace))
+			s.close(subscription)
+		}
+	}
+}
+
+// cleanup periodically deletes the events older than the retention period.
+func (s *Service) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.store.DeleteExecutionEvents(ctx, time.Now().Add(-s.retention)); err != nil {
+				s.log.Warn("Failed to delete expired execution events", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Subscription receives the execution events of a namespace or job.
+type Subscription struct {
+	namespace string
+	jobID     uuid.NullUUID
+	events    chan model.ExecutionEvent
+
+	// Stored events emitted after the last event ID of the subscriber, sent before new events
+	replay   []model.ExecutionEvent
+	replayed map[int64]struct{}
+}
+
+// Next returns the next event. ErrEventStreamClosed is returned once the subscription is closed; the subscriber can
+// subscribe again with the ID of the last event it received.
+func (s *Subscription) Next(ctx context.Context) (*model.ExecutionEvent, error) {
+	if len(s.replay) > 0 {
+		event := s.replay[0]
+		s.replay = s.replay[1:]
+		return &event, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-s.events:
+			if !ok {
+				return nil, errs.ErrEventStreamClosed
+			}
+
+			if _, ok := s.replayed[event.ID]; ok {
+				continue
+			}
+
+			return &event, nil
+		}
+	}
+}
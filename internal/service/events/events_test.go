@@ -0,0 +1,190 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database/dbtest"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/tests/docker"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
+	"gopkg.in/guregu/null.v4"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = dbtest.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer dbtest.StopDB(c)
+
+	m.Run()
+}
+
+func Test_Events(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := postgres.New(test.DB, test.Log)
+	jobService := job.NewService(store, test.Log)
+	eventService := NewService(store, database.NewListener(test.Config), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Subscriptions are refused until the service listens
+	// -------------------------------------------------------------------------
+
+	if _, err := eventService.Subscribe(ctx, model.DefaultNamespace, uuid.NullUUID{}, 0); !errors.Is(err, errs.ErrEventStreamUnavailable) {
+		t.Fatalf("Should not subscribe before listening: %v", err)
+	}
+
+	runCtx, stop := context.WithCancel(ctx)
+	go eventService.Run(runCtx)
+
+	var subscription *Subscription
+	for subscription == nil {
+		var err error
+		subscription, err = eventService.Subscribe(ctx, model.DefaultNamespace, uuid.NullUUID{}, 0)
+		if err != nil && !errors.Is(err, errs.ErrEventStreamUnavailable) {
+			t.Fatalf("Should be able to subscribe: %s", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Run a job
+	// -------------------------------------------------------------------------
+
+	now := time.Now()
+	created, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
+		Type:      model.JobTypeHTTP,
+		ExecuteAt: null.TimeFrom(now.Add(time.Second)),
+		HTTPJob:   &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(time.Minute), "instance1", 10)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("Should get back the job to run: %v", err)
+	}
+
+	if err := jobService.FinishJobExecution(ctx, jobs[0], "instance1", now, now.Add(time.Second), errors.New("timeout")); err != nil {
+		t.Fatalf("Should be able to finish the job execution: %s", err)
+	}
+
+	// Live events
+	// -------------------------------------------------------------------------
+
+	started, err := subscription.Next(ctx)
+	if err != nil || started.Type != model.ExecutionEventStarted || started.JobID != created.ID || started.InstanceID.String != "instance1" {
+		t.Fatalf("Should receive the started event: %+v, %v", started, err)
+	}
+
+	finished, err := subscription.Next(ctx)
+	if err != nil || finished.Type != model.ExecutionEventFinished || finished.Execution == nil || finished.Execution.Success {
+		t.Fatalf("Should receive the finished event with the failed execution: %+v, %v", finished, err)
+	}
+
+	if finished.Execution.ErrorMessage.String != "timeout" {
+		t.Fatalf("Should receive the error of the execution: %s", finished.Execution.ErrorMessage.String)
+	}
+
+	// Resume after the started event
+	// -------------------------------------------------------------------------
+
+	resumed, err := eventService.Subscribe(ctx, model.DefaultNamespace, uuid.NullUUID{UUID: created.ID, Valid: true}, started.ID)
+	if err != nil {
+		t.Fatalf("Should be able to resume: %s", err)
+	}
+
+	event, err := resumed.Next(ctx)
+	if err != nil || event.ID != finished.ID {
+		t.Fatalf("Should replay the events after the last event: %+v, %v", event, err)
+	}
+
+	// Resume after an event committed before an event emitted earlier
+	// -------------------------------------------------------------------------
+
+	insertEvent := `
+		INSERT INTO execution_events (namespace, job_id, type, instance_id, created_at)
+		VALUES ($1, $2, 'execution.started', 'instance2', now())
+		RETURNING id
+	`
+
+	tx, err := test.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Should be able to begin a transaction: %s", err)
+	}
+
+	var lateID, lastID int64
+	if err := tx.GetContext(ctx, &lateID, insertEvent, model.DefaultNamespace, created.ID); err != nil {
+		t.Fatalf("Should be able to insert an event: %s", err)
+	}
+
+	if err := test.DB.GetContext(ctx, &lastID, insertEvent, model.DefaultNamespace, created.ID); err != nil {
+		t.Fatalf("Should be able to insert an event: %s", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Should be able to commit the transaction: %s", err)
+	}
+
+	resumed, err = eventService.Subscribe(ctx, model.DefaultNamespace, uuid.NullUUID{UUID: created.ID, Valid: true}, lastID)
+	if err != nil {
+		t.Fatalf("Should be able to resume: %s", err)
+	}
+
+	event, err = resumed.Next(ctx)
+	if err != nil || event.ID != lateID {
+		t.Fatalf("Should replay the event committed after the last event: %+v, %v", event, err)
+	}
+
+	lateCtx, cancelLate := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelLate()
+	if event, err := resumed.Next(lateCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Should not replay the events committed before the last event: %+v", event)
+	}
+
+	// Other namespaces don't receive the events
+	// -------------------------------------------------------------------------
+
+	other, err := eventService.Subscribe(ctx, "team-a", uuid.NullUUID{}, started.ID-1)
+	if err != nil {
+		t.Fatalf("Should be able to subscribe: %s", err)
+	}
+
+	nextCtx, cancelNext := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelNext()
+	if event, err := other.Next(nextCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Should not receive events of other namespaces: %+v", event)
+	}
+
+	// Subscriptions are closed when the service stops listening
+	// -------------------------------------------------------------------------
+
+	stop()
+
+	if _, err := subscription.Next(ctx); !errors.Is(err, errs.ErrEventStreamClosed) {
+		t.Fatalf("Should close subscriptions when the service stops: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	// DefaultTTL is how long the response of a completed request is kept for retries.
+	DefaultTTL = 24 * time.Hour
+
+	// reservationTTL is how long a key is reserved for a request in progress. A key of a request that never completed,
+	// e.g. because the instance handling it crashed, can be reused after it.
+	reservationTTL = time.Minute
+
+	cleanupInterval = 10 * time.Minute
+)
+
+// Service makes requests with an idempotency key safe to retry: the first request with a key is processed and its
+// response stored, and retries with the same key get the stored response until the key expires.
+type Service struct {
+	store store.Storer
+	log   *otelzap.Logger
+	ttl   time.Duration
+}
+
+// NewService creates a new idempotency service with the given store and logger.
+func NewService(store store.Storer, log *otelzap.Logger) *Service {
+	return &Service{
+		store: store,
+		log:   log,
+		ttl:   DefaultTTL,
+	}
+}
+
+// WithTTL sets how long the responses of completed requests are kept. A non-positive TTL keeps the default.
+func (s *Service) WithTTL(ttl time.Duration) *Service {
+	if ttl > 0 {
+		s.ttl = ttl
+	}
+
+	return s
+}
+
+// Run periodically deletes the expired keys until the context is done.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.store.DeleteExpiredIdempotencyKeys(ctx, time.Now()); err != nil {
+				s.log.Warn("Failed to delete expired idempotency keys", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reserve reserves the key of the namespace for the request with the given hash. If the key was already used for the
+// same request, the stored key is returned and the request must not be processed again; its response is returned
+// instead. ErrIdempotencyKeyReused is returned if the key was used for a different request, and
+// ErrIdempotencyKeyInProgress if the request with the key hasn't completed yet.
+func (s *Service) Reserve(ctx context.Context, namespace, key, requestHash string) (*model.IdempotencyKey, error) {
+	if err := model.ValidateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	reserved, existing, err := s.store.ReserveIdempotencyKey(ctx, &model.IdempotencyKey{
+		Namespace:   namespace,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(reservationTTL),
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case reserved:
+		return nil, nil
+	case existing.RequestHash != requestHash:
+		return nil, errs.ErrIdempotencyKeyReused
+	case !existing.Completed():
+		return nil, errs.ErrIdempotencyKeyInProgress
+	}
+
+	s.log.Info("Replaying the response of an idempotent request", zap.String("namespace", namespace), zap.String("key", key))
+	return existing, nil
+}
+
+// Complete stores the response of the request the key was reserved for, with its ETag if it has one. The response was
+// already sent, so failures are only logged; the key is then released when its reservation expires.
+func (s *Service) Complete(ctx context.Context, namespace, key, requestHash string, statusCode int, etag string, response []byte) {
+	err := s.store.CompleteIdempotencyKey(ctx, &model.IdempotencyKey{
+		Namespace:   namespace,
+		Key:         key,
+		RequestHash: requestHash,
+		StatusCode:  null.IntFrom(int64(statusCode)),
+		ETag:        null.NewString(etag, etag != ""),
+		Response:    response,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		s.log.Warn("Failed to store the response of an idempotent request", zap.String("namespace", namespace), zap.Error(err))
+	}
+}
+
+// Release releases the key without storing a response, so the request can be retried with it. Failures are only
+// logged; the key is then released when its reservation expires.
+func (s *Service) Release(ctx context.Context, namespace, key string) {
+	if err := s.store.DeleteIdempotencyKey(ctx, namespace, key); err != nil {
+		s.log.Warn("Failed to release an idempotency key", zap.String("namespace", namespace), zap.Error(err))
+	}
+}
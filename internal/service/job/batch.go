@@ -0,0 +1,248 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"go.uber.org/zap"
+)
+
+// BatchResult is the outcome of one item of a batch operation: the job, or the reason the item failed.
+type BatchResult struct {
+	ID  uuid.UUID
+	Job *model.Job
+
+	// Created is false for items of a batch creation with the unique key of an existing job, which is returned instead
+	Created bool
+
+	Err error
+}
+
+// BatchCreateJobs creates the jobs of the batch in the namespace in a single transaction. If any of the jobs is
+// invalid, no job is created and ErrInvalidJobBatch is returned with the results, which hold the errors of the invalid
+// jobs. Jobs with the unique key of an existing job are not created; the existing job is returned instead.
+func (s *Service) BatchCreateJobs(ctx context.Context, namespace string, batch *model.JobBatchCreate) ([]BatchResult, error) {
+	s.log.Info("Creating a batch of jobs", zap.String("namespace", namespace), zap.Int("jobs", len(batch.Jobs)))
+
+	if err := batch.Validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(batch.Jobs))
+	writes := make([]model.JobWrite, 0, len(batch.Jobs))
+	uniqueKeys := map[string]struct{}{}
+
+	for i := range batch.Jobs {
+		job := batch.Jobs[i].ToJob()
+		job.Namespace = namespace
+		results[i] = BatchResult{ID: job.ID, Job: job, Created: true}
+
+		if err := job.Validate(); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if job.UniqueKey.Valid {
+			if _, ok := uniqueKeys[job.UniqueKey.String]; ok {
+				results[i].Err = errs.ErrJobUniqueKeyExists
+				continue
+			}
+			uniqueKeys[job.UniqueKey.String] = struct{}{}
+		}
+
+		existing, err := s.getJobByUniqueKey(ctx, namespace, job.UniqueKey)
+		switch {
+		case err != nil:
+			return nil, err
+		case existing != nil:
+			results[i] = BatchResult{ID: existing.ID, Job: existing}
+			continue
+		}
+
+		if err := s.validateCredentialReferences(ctx, job); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if err := s.validateEgress(ctx, job); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		// The audit log entry is created before storing the job, as the store encrypts the job's secrets
+		entry, err := newAudit(ctx, model.AuditActionCreate, job.ID, namespace, nil, job)
+		if err != nil {
+			return nil, err
+		}
+
+		writes = append(writes, model.JobWrite{Job: job, Audit: entry})
+	}
+
+	if batchInvalid(results) {
+		return results, errs.ErrInvalidJobBatch
+	}
+
+	if len(writes) > 0 {
+		if err := s.store.CreateJobs(ctx, writes); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// BatchUpdateJobs applies the update of the batch to the selected jobs of the namespace in a single transaction. If any
+// of the selected jobs doesn't exist, doesn't have its expected version or would be invalid after the update, no job is
+// updated and ErrInvalidJobBatch is returned with the results, which hold the errors of the invalid jobs.
+func (s *Service) BatchUpdateJobs(ctx context.Context, namespace string, batch *model.JobBatchUpdate) ([]BatchResult, error) {
+	s.log.Info("Updating a batch of jobs", zap.String("namespace", namespace))
+
+	results, err := s.selectJobs(ctx, namespace, batch.JobSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every job gets its own copy of the update, as the store encrypts the secrets of the jobs in place
+	update, err := json.Marshal(batch.Update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy job update: %w", err)
+	}
+
+	writes := make([]model.JobWrite, 0, len(results))
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+
+		var jobUpdate model.JobUpdate
+		if err := json.Unmarshal(update, &jobUpdate); err != nil {
+			return nil, fmt.Errorf("failed to copy job update: %w", err)
+		}
+
+		job := results[i].Job
+		before := *job
+
+		job.ApplyUpdate(jobUpdate)
+
+		if err := s.validateJob(ctx, job); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		entry, err := newAudit(ctx, model.UpdateAuditAction(&before, job), job.ID, namespace, &before, job)
+		if err != nil {
+			return nil, err
+		}
+
+		writes = append(writes, model.JobWrite{Job: job, Audit: entry})
+	}
+
+	if batchInvalid(results) {
+		return results, errs.ErrInvalidJobBatch
+	}
+
+	if len(writes) > 0 {
+		if err := s.store.UpdateJobs(ctx, writes); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// BatchDeleteJobs deletes the selected jobs of the namespace in a single transaction. Jobs selected by ID that don't
+// exist or don't have their expected version are reported with ErrJobNotFound or ErrJobBatchVersionMismatch, but don't
+// prevent the other jobs from being deleted.
+func (s *Service) BatchDeleteJobs(ctx context.Context, namespace string, batch *model.JobBatchDelete) ([]BatchResult, error) {
+	s.log.Info("Deleting a batch of jobs", zap.String("namespace", namespace))
+
+	results, err := s.selectJobs(ctx, namespace, batch.JobSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	writes := make([]model.JobWrite, 0, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		entry, err := newAudit(ctx, model.AuditActionDelete, result.ID, namespace, result.Job, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		writes = append(writes, model.JobWrite{Job: result.Job, Audit: entry})
+	}
+
+	if len(writes) > 0 {
+		if err := s.store.DeleteJobs(ctx, writes); err != nil {
+			return nil, err
+		}
+	}
+
+	// deleted jobs are not returned
+	for i := range results {
+		results[i].Job = nil
+	}
+
+	return results, nil
+}
+
+// selectJobs returns the jobs of the namespace selected by the selector. Jobs selected by ID that don't exist are
+// returned with ErrJobNotFound, and jobs that don't have their expected version with ErrJobBatchVersionMismatch.
+func (s *Service) selectJobs(ctx context.Context, namespace string, selector model.JobSelector) ([]BatchResult, error) {
+	if err := selector.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(selector.Tags) > 0 {
+		page, err := s.store.ListJobs(ctx, namespace, model.JobQuery{
+			JobFilter: model.JobFilter{Tags: selector.Tags},
+			Limit:     model.MaxJobBatchSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if page.Total > model.MaxJobBatchSize {
+			return nil, errs.ErrJobBatchTooLarge
+		}
+
+		return lo.Map(page.Jobs, func(job model.Job, _ int) BatchResult {
+			return BatchResult{ID: job.ID, Job: &job}
+		}), nil
+	}
+
+	ids := lo.Uniq(selector.IDs)
+
+	jobs, err := s.store.GetJobsByID(ctx, namespace, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	jobsByID := lo.KeyBy(jobs, func(job model.Job) uuid.UUID { return job.ID })
+
+	return lo.Map(ids, func(id uuid.UUID, _ int) BatchResult {
+		job, ok := jobsByID[id]
+		if !ok {
+			return BatchResult{ID: id, Err: errs.ErrJobNotFound}
+		}
+
+		if version, ok := selector.ExpectedVersions[id]; ok && version != job.Version {
+			return BatchResult{ID: id, Err: errs.ErrJobBatchVersionMismatch}
+		}
+
+		return BatchResult{ID: id, Job: &job}
+	}), nil
+}
+
+// batchInvalid reports whether any item of the batch failed.
+func batchInvalid(results []BatchResult) bool {
+	return lo.SomeBy(results, func(result BatchResult) bool { return result.Err != nil })
+}
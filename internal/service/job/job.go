@@ -2,11 +2,16 @@ package job
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/GLCharge/otelzap"
 	"github.com/google/uuid"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/audit"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/egress"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/store"
 	"go.uber.org/zap"
 	"gopkg.in/guregu/null.v4"
@@ -14,8 +19,9 @@ import (
 
 // Service is a struct that contains a store and a logger.
 type Service struct {
-	store store.Storer
-	log   *otelzap.Logger
+	store  store.Storer
+	log    *otelzap.Logger
+	egress *egress.Policy
 }
 
 // NewService creates a new job service with the given store and logger.
@@ -26,54 +32,119 @@ func NewService(store store.Storer, log *otelzap.Logger) *Service {
 	}
 }
 
-// CreateJob creates a new job using the given job create request and returns the created job.
-// If the job create request is invalid, an error is returned.
-func (s *Service) CreateJob(ctx context.Context, jobCreate *model.JobCreate) (*model.Job, error) {
-	s.log.Info("Creating job", zap.Any("job", jobCreate))
+// WithEgressPolicy makes the service reject jobs calling destinations denied by the policy.
+func (s *Service) WithEgressPolicy(policy *egress.Policy) *Service {
+	s.egress = policy
+	return s
+}
+
+// CreateJob creates a new job in the namespace using the given job create request and returns the created job.
+// If the job create request is invalid, an error is returned. If the request has a unique key and the namespace
+// already has a job with that key, the existing job is returned instead and created is false.
+func (s *Service) CreateJob(ctx context.Context, namespace string, jobCreate *model.JobCreate) (job *model.Job, created bool, err error) {
+	s.log.Info("Creating job", zap.String("namespace", namespace), zap.Any("job", jobCreate))
 
 	// Convert the job create request to a job
-	job := jobCreate.ToJob()
+	job = jobCreate.ToJob()
+	job.Namespace = namespace
 
 	// Validate the job
 	if err := job.Validate(); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	// Create the job using the store
-	err := s.store.CreateJob(ctx, job)
+	if existing, err := s.getJobByUniqueKey(ctx, namespace, job.UniqueKey); err != nil || existing != nil {
+		return existing, false, err
+	}
+
+	if err := s.validateCredentialReferences(ctx, job); err != nil {
+		return nil, false, err
+	}
+
+	if err := s.validateEgress(ctx, job); err != nil {
+		return nil, false, err
+	}
+
+	// The audit log entry is created before storing the job, as the store encrypts the job's secrets
+	entry, err := newAudit(ctx, model.AuditActionCreate, job.ID, namespace, nil, job)
 	if err != nil {
+		return nil, false, err
+	}
+
+	// Create the job using the store
+	err = s.store.CreateJob(ctx, job, entry)
+	switch {
+	case errors.Is(err, errs.ErrJobUniqueKeyExists):
+		// a job with the key was created concurrently
+		existing, err := s.getJobByUniqueKey(ctx, namespace, job.UniqueKey)
+		if err == nil && existing == nil {
+			err = errs.ErrJobUniqueKeyExists
+		}
+		return existing, false, err
+	case err != nil:
+		return nil, false, err
+	}
+
+	return job, true, nil
+}
+
+// getJobByUniqueKey returns the job with the unique key from the namespace, or nil if there is no such job or no key.
+func (s *Service) getJobByUniqueKey(ctx context.Context, namespace string, uniqueKey null.String) (*model.Job, error) {
+	if !uniqueKey.Valid {
+		return nil, nil
+	}
+
+	job, err := s.store.GetJobByUniqueKey(ctx, namespace, uniqueKey.String)
+	switch {
+	case errors.Is(err, errs.ErrJobNotFound):
+		return nil, nil
+	case err != nil:
 		return nil, err
 	}
 
+	s.log.Info("Job with the unique key already exists", zap.String("namespace", namespace), zap.Any("id", job.ID))
 	return job, nil
 }
 
-// GetJob returns the job with the given ID.
-func (s *Service) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
-	s.log.Info("Getting a job", zap.Any("id", id))
-	return s.store.GetJob(ctx, id)
+// GetJob returns the job with the given ID from the namespace.
+func (s *Service) GetJob(ctx context.Context, namespace string, id uuid.UUID) (*model.Job, error) {
+	s.log.Info("Getting a job", zap.String("namespace", namespace), zap.Any("id", id))
+	return s.store.GetJob(ctx, namespace, id)
 }
 
-// UpdateJob updates the given job.
-func (s *Service) UpdateJob(ctx context.Context, jobID uuid.UUID, jobUpdate model.JobUpdate) (*model.Job, error) {
-	s.log.Info("Updating a job", zap.Any("id", jobID))
+// UpdateJob updates the given job. If an expected version is given, the job is only updated if it is still at that
+// version.
+func (s *Service) UpdateJob(ctx context.Context, namespace string, jobID uuid.UUID, expectedVersion null.Int, jobUpdate model.JobUpdate) (*model.Job, error) {
+	s.log.Info("Updating a job", zap.String("namespace", namespace), zap.Any("id", jobID))
 
 	// get the job from the store
-	job, err := s.store.GetJob(ctx, jobID)
+	job, err := s.store.GetJob(ctx, namespace, jobID)
 	if err != nil {
 		return nil, err
 	}
 
+	if expectedVersion.Valid && job.Version != expectedVersion.Int64 {
+		return nil, errs.ErrJobVersionMismatch
+	}
+
+	// keep the job before the update for the audit log; updates replace fields rather than modifying them
+	before := *job
+
 	// update the job
 	job.ApplyUpdate(jobUpdate)
 
 	// validate the job
-	if err := job.Validate(); err != nil {
+	if err := s.validateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	entry, err := newAudit(ctx, model.UpdateAuditAction(&before, job), job.ID, namespace, &before, job)
+	if err != nil {
 		return nil, err
 	}
 
 	// update the job in the store
-	err = s.store.UpdateJob(ctx, job)
+	err = s.store.UpdateJob(ctx, job, entry)
 	if err != nil {
 		return nil, err
 	}
@@ -81,16 +152,139 @@ func (s *Service) UpdateJob(ctx context.Context, jobID uuid.UUID, jobUpdate mode
 	return job, nil
 }
 
-// DeleteJob deletes the job with the given ID.
-func (s *Service) DeleteJob(ctx context.Context, id uuid.UUID) error {
-	s.log.Info("Deleting a job", zap.Any("id", id))
-	return s.store.DeleteJob(ctx, id)
+// PatchJob applies the JSON merge patch (RFC 7386) to the job with the given ID. Fields omitted from the patch,
+// including secrets, keep their values. If an expected version is given, the job is only updated if it is still at
+// that version.
+func (s *Service) PatchJob(ctx context.Context, namespace string, jobID uuid.UUID, expectedVersion null.Int, patch []byte) (*model.Job, error) {
+	s.log.Info("Patching a job", zap.String("namespace", namespace), zap.Any("id", jobID))
+
+	before, err := s.store.GetJob(ctx, namespace, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedVersion.Valid && before.Version != expectedVersion.Int64 {
+		return nil, errs.ErrJobVersionMismatch
+	}
+
+	job, err := before.ApplyMergePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	entry, err := newAudit(ctx, model.UpdateAuditAction(before, job), job.ID, namespace, before, job)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateJob(ctx, job, entry); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// DeleteJob deletes the job with the given ID from the namespace. If an expected version is given, the job is only
+// deleted if it is still at that version.
+func (s *Service) DeleteJob(ctx context.Context, namespace string, id uuid.UUID, expectedVersion null.Int) error {
+	s.log.Info("Deleting a job", zap.String("namespace", namespace), zap.Any("id", id))
+
+	job, err := s.store.GetJob(ctx, namespace, id)
+	switch {
+	case errors.Is(err, errs.ErrJobNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	if expectedVersion.Valid && job.Version != expectedVersion.Int64 {
+		return errs.ErrJobVersionMismatch
+	}
+
+	entry, err := newAudit(ctx, model.AuditActionDelete, id, namespace, job, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.store.DeleteJob(ctx, job, entry)
 }
 
-// ListJobs returns a list of jobs with the given limit and offset.
-func (s *Service) ListJobs(ctx context.Context, limit, offset uint64, tags []string) ([]model.Job, error) {
-	s.log.Info("Getting jobs")
-	return s.store.ListJobs(ctx, limit, offset, tags)
+// GetJobVersions returns the versions of the job with the given ID from the namespace, newest first.
+func (s *Service) GetJobVersions(ctx context.Context, namespace string, id uuid.UUID, limit, offset uint64) ([]model.JobVersion, error) {
+	s.log.Info("Getting job versions", zap.String("namespace", namespace), zap.Any("id", id))
+	return s.store.ListJobVersions(ctx, namespace, id, limit, offset)
+}
+
+// RollbackJob restores the definition of the job with the given ID to the given version. The restored definition is
+// stored as a new version, so the rollback can be reverted as well.
+func (s *Service) RollbackJob(ctx context.Context, namespace string, id uuid.UUID, version int64) (*model.Job, error) {
+	s.log.Info("Rolling back a job", zap.String("namespace", namespace), zap.Any("id", id), zap.Int64("version", version))
+
+	if version < 1 {
+		return nil, errs.ErrInvalidJobVersion
+	}
+
+	job, err := s.store.GetJob(ctx, namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	jobVersion, err := s.store.GetJobVersion(ctx, namespace, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *job
+	job.ApplyVersion(jobVersion)
+
+	// The version is validated again, as the referenced credentials or the egress policy may have changed since
+	if err := s.validateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	entry, err := newAudit(ctx, model.AuditActionRollback, job.ID, namespace, &before, job)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateJob(ctx, job, entry); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetJobAudit returns the audit log entries of the job with the given ID from the namespace, newest first. The entries
+// of deleted jobs are kept.
+func (s *Service) GetJobAudit(ctx context.Context, namespace string, id uuid.UUID, limit, offset uint64) ([]model.JobAudit, error) {
+	s.log.Info("Getting job audit log", zap.String("namespace", namespace), zap.Any("id", id))
+	return s.store.ListJobAudit(ctx, namespace, model.AuditFilter{JobID: uuid.NullUUID{UUID: id, Valid: true}}, limit, offset)
+}
+
+// ListAudit returns the audit log entries of the namespace matching the filter, newest first.
+func (s *Service) ListAudit(ctx context.Context, namespace string, filter model.AuditFilter, limit, offset uint64) ([]model.JobAudit, error) {
+	s.log.Info("Getting audit log", zap.String("namespace", namespace))
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListJobAudit(ctx, namespace, filter, limit, offset)
+}
+
+// ListJobs returns a page of the jobs of the namespace matching the query, with the total number of matching jobs.
+func (s *Service) ListJobs(ctx context.Context, namespace string, query model.JobQuery) (*model.JobPage, error) {
+	s.log.Info("Getting jobs", zap.String("namespace", namespace))
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListJobs(ctx, namespace, query)
 }
 
 // GetJobsToRun returns a list of jobs that should be run at the given time.
@@ -100,16 +294,20 @@ func (s *Service) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 	return s.store.GetJobsToRun(ctx, at, lockedUntil, instanceID, limit)
 }
 
-func (s *Service) FinishJobExecution(ctx context.Context, job *model.Job, startTime, stopTime time.Time, err error) error {
-	s.log.Info("Finishing job execution", zap.Any("job", job.ID), zap.Any("startTime", startTime), zap.Any("stopTime", stopTime), zap.Any("err", err))
+// FinishJobExecution clears the lock of the job, schedules its next run and records the execution on the instance.
+func (s *Service) FinishJobExecution(ctx context.Context, job *model.Job, instanceID string, startTime, stopTime time.Time, err error) error {
+	s.log.Info("Finishing job execution", zap.Any("job", job.ID), zap.String("instanceID", instanceID), zap.Any("startTime", startTime), zap.Any("stopTime", stopTime), zap.Any("err", err))
+
+	// The run time the job was picked up for, before the next one is set
+	scheduledAt := job.NextRun
 
 	// Update the job execution
 	job.SetNextRunTime()
 
 	// finish the job in the store (update the next run time and clear lock)
-	err2 := s.store.FinishJob(ctx, job.ID, job.NextRun)
-	if err2 != nil {
-		return err
+	storeErr := s.store.FinishJob(ctx, job.ID, job.Version, job.NextRun)
+	if storeErr != nil {
+		return storeErr
 	}
 
 	jobExecutionStatus := model.JobExecutionStatusSuccessful
@@ -120,16 +318,127 @@ func (s *Service) FinishJobExecution(ctx context.Context, job *model.Job, startT
 	}
 
 	// Create the job execution
-	err2 = s.store.CreateJobExecution(ctx, job.ID, startTime, stopTime, jobExecutionStatus, errorMessage)
-	if err2 != nil {
-		return err2
+	storeErr = s.store.CreateJobExecution(ctx, job.ID, job.Version, instanceID, scheduledAt, startTime, stopTime, jobExecutionStatus, errorMessage)
+	if storeErr != nil {
+		return storeErr
+	}
+
+	return nil
+}
+
+func (s *Service) GetJobExecutions(ctx context.Context, namespace string, id uuid.UUID, failedOnly bool, limit uint64, offset uint64) ([]*model.JobExecution, error) {
+	s.log.Info("Getting job executions", zap.String("namespace", namespace), zap.Any("id", id), zap.Any("failedOnly", failedOnly), zap.Any("limit", limit), zap.Any("offset", offset))
+
+	return s.store.GetJobExecutions(ctx, namespace, id, failedOnly, limit, offset)
+}
+
+// SearchJobExecutions returns a page of the executions of all jobs of the namespace matching the query.
+func (s *Service) SearchJobExecutions(ctx context.Context, namespace string, query model.ExecutionQuery) (*model.ExecutionPage, error) {
+	s.log.Info("Searching job executions", zap.String("namespace", namespace), zap.Any("query", query))
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.store.SearchJobExecutions(ctx, namespace, query)
+}
+
+// validateJob validates the job, the credentials it references and its destinations.
+func (s *Service) validateJob(ctx context.Context, job *model.Job) error {
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.validateCredentialReferences(ctx, job); err != nil {
+		return err
+	}
+
+	return s.validateEgress(ctx, job)
+}
+
+// validateCredentialReferences checks that the named credentials the job references exist in the job's namespace
+// and are of a type that can be used by the job.
+func (s *Service) validateCredentialReferences(ctx context.Context, job *model.Job) error {
+	if job.HTTPJob != nil && job.HTTPJob.Auth.Credential.Valid {
+		credential, err := s.getReferencedCredential(ctx, job.Namespace, job.HTTPJob.Auth.Credential.String)
+		if err != nil {
+			return err
+		}
+
+		// Apply the credential to a copy, so the secrets are never stored on the job
+		auth := job.HTTPJob.Auth
+		if err := credential.ApplyToAuth(&auth); err != nil {
+			return err
+		}
+	}
+
+	if job.AMQPJob != nil && job.AMQPJob.ConnectionCredential != nil {
+		credential, err := s.getReferencedCredential(ctx, job.Namespace, *job.AMQPJob.ConnectionCredential)
+		if err != nil {
+			return err
+		}
+
+		amqpJob := *job.AMQPJob
+		if err := credential.ApplyToAMQPJob(&amqpJob); err != nil {
+			return err
+		}
+
+		if err := s.egress.Validate(ctx, amqpJob.Connection); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateEgress checks that the destinations the job calls are allowed by the egress policy.
+func (s *Service) validateEgress(ctx context.Context, job *model.Job) error {
+	if job.HTTPJob != nil {
+		if err := s.egress.Validate(ctx, job.HTTPJob.URL); err != nil {
+			return err
+		}
+
+		if job.HTTPJob.Auth.OAuth2 != nil {
+			if err := s.egress.Validate(ctx, job.HTTPJob.Auth.OAuth2.TokenURL); err != nil {
+				return err
+			}
+		}
+	}
+
+	if job.AMQPJob != nil && job.AMQPJob.ConnectionCredential == nil {
+		if err := s.egress.Validate(ctx, job.AMQPJob.Connection); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (s *Service) GetJobExecutions(ctx context.Context, id uuid.UUID, failedOnly bool, limit uint64, offset uint64) ([]*model.JobExecution, error) {
-	s.log.Info("Getting job executions", zap.Any("id", id), zap.Any("failedOnly", failedOnly), zap.Any("limit", limit), zap.Any("offset", offset))
+// newAudit creates the audit log entry of a change to a job, made by the actor of the request in the context.
+func newAudit(ctx context.Context, action model.AuditAction, jobID uuid.UUID, namespace string, before, after *model.Job) (*model.JobAudit, error) {
+	changes, err := model.DiffJobs(before, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff job: %w", err)
+	}
+
+	requestID := audit.RequestID(ctx)
+
+	return &model.JobAudit{
+		JobID:     jobID,
+		Namespace: namespace,
+		Action:    action,
+		Actor:     audit.Actor(ctx),
+		RequestID: null.NewString(requestID, requestID != ""),
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func (s *Service) getReferencedCredential(ctx context.Context, namespace, name string) (*model.Credential, error) {
+	credential, err := s.store.GetCredential(ctx, namespace, name)
+	if errors.Is(err, errs.ErrCredentialNotFound) {
+		return nil, errs.ErrUnknownCredential
+	}
 
-	return s.store.GetJobExecutions(ctx, id, failedOnly, limit, offset)
+	return credential, err
 }
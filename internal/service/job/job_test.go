@@ -2,6 +2,7 @@ package job
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"testing"
@@ -11,7 +12,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/samber/lo"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/audit"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database/dbtest"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/tests/docker"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
 	"gopkg.in/guregu/null.v4"
@@ -34,6 +37,14 @@ func TestMain(m *testing.M) {
 func Test_Job(t *testing.T) {
 	t.Run("crud", crud)
 	t.Run("job_execution", jobExecution)
+	t.Run("namespaces", namespaces)
+	t.Run("audit_log", auditLog)
+	t.Run("versions", versions)
+	t.Run("patch", patch)
+	t.Run("unique_key", uniqueKey)
+	t.Run("batch", batch)
+	t.Run("list", list)
+	t.Run("stats", stats)
 }
 
 func crud(t *testing.T) {
@@ -57,7 +68,7 @@ func crud(t *testing.T) {
 	// Create job 1
 	// -------------------------------------------------------------------------
 
-	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+	job, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
 		Type:         model.JobTypeHTTP,
 		CronSchedule: null.StringFrom("@every 1m"),
 		HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
@@ -77,7 +88,7 @@ func crud(t *testing.T) {
 
 	// Get job 1
 	// -------------------------------------------------------------------------
-	job1, err1 := jobService.GetJob(ctx, job.ID)
+	job1, err1 := jobService.GetJob(ctx, model.DefaultNamespace, job.ID)
 	if err1 != nil {
 		t.Fatalf("Should be able to get a job: %s", err1)
 	}
@@ -90,7 +101,7 @@ func crud(t *testing.T) {
 	// Create job 2
 	// -------------------------------------------------------------------------
 
-	job2, err := jobService.CreateJob(ctx, &model.JobCreate{
+	job2, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
 		Type:         model.JobTypeHTTP,
 		CronSchedule: null.StringFrom("@every 1m"),
 		HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
@@ -106,7 +117,7 @@ func crud(t *testing.T) {
 
 	// update job
 	// -------------------------------------------------------------------------
-	job, err = jobService.UpdateJob(ctx, job.ID, model.JobUpdate{
+	job, err = jobService.UpdateJob(ctx, model.DefaultNamespace, job.ID, null.IntFrom(job.Version), model.JobUpdate{
 		CronSchedule: lo.ToPtr("@every 2m"),
 	})
 
@@ -118,33 +129,49 @@ func crud(t *testing.T) {
 		t.Fatalf("Should get back an updated cron schedule: %s", job.CronSchedule.String)
 	}
 
+	// update and delete job with a stale version
+	// -------------------------------------------------------------------------
+	_, err = jobService.UpdateJob(ctx, model.DefaultNamespace, job.ID, null.IntFrom(job.Version-1), model.JobUpdate{
+		CronSchedule: lo.ToPtr("@every 5m"),
+	})
+
+	if !errors.Is(err, errs.ErrJobVersionMismatch) {
+		t.Fatalf("Should not be able to update a job with a stale version: %v", err)
+	}
+
+	err = jobService.DeleteJob(ctx, model.DefaultNamespace, job.ID, null.IntFrom(job.Version-1))
+
+	if !errors.Is(err, errs.ErrJobVersionMismatch) {
+		t.Fatalf("Should not be able to delete a job with a stale version: %v", err)
+	}
+
 	// Get jobs
 	// -------------------------------------------------------------------------
 
-	jobs, err := jobService.ListJobs(ctx, 10, 0, []string{})
+	page, err := jobService.ListJobs(ctx, model.DefaultNamespace, model.JobQuery{Limit: 10})
 	if err != nil {
 		t.Fatalf("Should be able to list jobs: %s", err)
 	}
 
-	if len(jobs) != 2 {
-		t.Fatalf("Should get back 2 jobs: %d", len(jobs))
+	if len(page.Jobs) != 2 {
+		t.Fatalf("Should get back 2 jobs: %d", len(page.Jobs))
 	}
 
 	// Get jobs with limit
 	// -------------------------------------------------------------------------
 
-	jobs, err = jobService.ListJobs(ctx, 1, 0, []string{})
+	page, err = jobService.ListJobs(ctx, model.DefaultNamespace, model.JobQuery{Limit: 1})
 	if err != nil {
 		t.Fatalf("Should be able to list jobs: %s", err)
 	}
 
-	if len(jobs) != 1 {
-		t.Fatalf("Should get back 1 job: %d", len(jobs))
+	if len(page.Jobs) != 1 {
+		t.Fatalf("Should get back 1 job: %d", len(page.Jobs))
 	}
 
 	// Delete job
 	// -------------------------------------------------------------------------
-	err = jobService.DeleteJob(ctx, job.ID)
+	err = jobService.DeleteJob(ctx, model.DefaultNamespace, job.ID, null.IntFrom(job.Version))
 
 	if err != nil {
 		t.Fatalf("Should be able to delete a job: %s", err)
@@ -152,7 +179,7 @@ func crud(t *testing.T) {
 
 	// Get job
 	// -------------------------------------------------------------------------
-	_, err = jobService.GetJob(ctx, job.ID)
+	_, err = jobService.GetJob(ctx, model.DefaultNamespace, job.ID)
 
 	if err == nil {
 		t.Fatalf("Should not be able to get a deleted job: %s", err)
@@ -182,7 +209,7 @@ func jobExecution(t *testing.T) {
 	// Create job
 	// -------------------------------------------------------------------------
 
-	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+	job, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
 		Type:      model.JobTypeHTTP,
 		ExecuteAt: null.TimeFrom(now.Add(1 * time.Second)),
 		HTTPJob:   &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
@@ -245,7 +272,16 @@ func jobExecution(t *testing.T) {
 	// complete job
 	// -------------------------------------------------------------------------
 
-	err = jobService.FinishJobExecution(ctx, jobs[0], now.Add(6*time.Second), now.Add(7*time.Second), nil)
+	canceledCtx, cancelFinish := context.WithCancel(ctx)
+	cancelFinish()
+
+	failing := *jobs[0]
+	err = jobService.FinishJobExecution(canceledCtx, &failing, "instance2", now.Add(6*time.Second), now.Add(7*time.Second), nil)
+	if err == nil {
+		t.Fatalf("Should get back the error of the store")
+	}
+
+	err = jobService.FinishJobExecution(ctx, jobs[0], "instance2", now.Add(6*time.Second), now.Add(7*time.Second), nil)
 	if err != nil {
 		t.Fatalf("Should be able to finish job execution: %s", err)
 	}
@@ -263,7 +299,7 @@ func jobExecution(t *testing.T) {
 	// get job execution
 	// -------------------------------------------------------------------------
 
-	jobExecutions, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	jobExecutions, err := jobService.GetJobExecutions(ctx, model.DefaultNamespace, job.ID, false, 10, 0)
 	if err != nil {
 		t.Fatalf("Should be able to get job executions: %s", err)
 	}
@@ -276,7 +312,11 @@ func jobExecution(t *testing.T) {
 		t.Fatalf("Should get back the correct job execution: %s", jobExecutions[0].JobID)
 	}
 
-	jobExecutions, err = jobService.GetJobExecutions(ctx, job.ID, true, 10, 0)
+	if jobExecutions[0].JobVersion.Int64 != 1 {
+		t.Fatalf("Should get back the job version the execution ran with: %d", jobExecutions[0].JobVersion.Int64)
+	}
+
+	jobExecutions, err = jobService.GetJobExecutions(ctx, model.DefaultNamespace, job.ID, true, 10, 0)
 	if err != nil {
 		t.Fatalf("Should be able to get job executions: %s", err)
 	}
@@ -284,4 +324,883 @@ func jobExecution(t *testing.T) {
 	if len(jobExecutions) != 0 {
 		t.Fatalf("Should get back 0 failed job executions: %d", len(jobExecutions))
 	}
+
+	// search job executions
+	// -------------------------------------------------------------------------
+
+	page, err := jobService.SearchJobExecutions(ctx, model.DefaultNamespace, model.ExecutionQuery{
+		ExecutionFilter: model.ExecutionFilter{InstanceID: "instance2", From: null.TimeFrom(now)},
+		Limit:           10,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to search job executions: %s", err)
+	}
+
+	if page.Total != 1 || len(page.Executions) != 1 || page.Executions[0].InstanceID.String != "instance2" {
+		t.Fatalf("Should find the execution of the runner instance: %+v", page)
+	}
+
+	page, err = jobService.SearchJobExecutions(ctx, model.DefaultNamespace, model.ExecutionQuery{
+		ExecutionFilter: model.ExecutionFilter{Status: model.JobExecutionStatusFailed},
+		Limit:           10,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to search job executions: %s", err)
+	}
+
+	if page.Total != 0 {
+		t.Fatalf("Should find no failed job executions: %d", page.Total)
+	}
+}
+
+func namespaces(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := postgres.New(test.DB, test.Log)
+	jobService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	newJob := func() *model.JobCreate {
+		return &model.JobCreate{
+			Type:      model.JobTypeHTTP,
+			ExecuteAt: null.TimeFrom(now.Add(1 * time.Second)),
+			HTTPJob:   &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		}
+	}
+
+	// Isolation
+	// -------------------------------------------------------------------------
+
+	job, _, err := jobService.CreateJob(ctx, "team-a", newJob())
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	if _, err := jobService.GetJob(ctx, "team-b", job.ID); !errors.Is(err, errs.ErrJobNotFound) {
+		t.Fatalf("Should not be able to get a job of another namespace: %v", err)
+	}
+
+	if _, err := jobService.UpdateJob(ctx, "team-b", job.ID, null.Int{}, model.JobUpdate{}); !errors.Is(err, errs.ErrJobNotFound) {
+		t.Fatalf("Should not be able to update a job of another namespace: %v", err)
+	}
+
+	if err := jobService.DeleteJob(ctx, "team-b", job.ID, null.Int{}); err != nil {
+		t.Fatalf("Should be able to delete a job of another namespace without effect: %s", err)
+	}
+
+	page, err := jobService.ListJobs(ctx, "team-b", model.JobQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("Should be able to list jobs: %s", err)
+	}
+
+	if len(page.Jobs) != 0 {
+		t.Fatalf("Should not list jobs of another namespace: %d", len(page.Jobs))
+	}
+
+	if _, err := jobService.GetJob(ctx, "team-a", job.ID); err != nil {
+		t.Fatalf("Should be able to get the job: %s", err)
+	}
+
+	// Job quota
+	// -------------------------------------------------------------------------
+
+	_, err = store.SetNamespaceQuota(ctx, "team-a", model.NamespaceQuota{MaxJobs: null.IntFrom(2), MaxExecutionsPerMinute: null.IntFrom(1)})
+	if err != nil {
+		t.Fatalf("Should be able to set the namespace quota: %s", err)
+	}
+
+	if _, _, err := jobService.CreateJob(ctx, "team-a", newJob()); err != nil {
+		t.Fatalf("Should be able to create a job within the quota: %s", err)
+	}
+
+	if _, _, err := jobService.CreateJob(ctx, "team-a", newJob()); !errors.Is(err, errs.ErrJobQuotaExceeded) {
+		t.Fatalf("Should not be able to exceed the job quota: %v", err)
+	}
+
+	// Fair scheduling
+	// -------------------------------------------------------------------------
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := jobService.CreateJob(ctx, "team-b", newJob()); err != nil {
+			t.Fatalf("Should be able to create a job: %s", err)
+		}
+	}
+
+	if _, _, err := jobService.CreateJob(ctx, "team-c", newJob()); err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	jobsToRun, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(5*time.Second), "instance1", 3)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	namespaceCount := lo.CountValuesBy(jobsToRun, func(job *model.Job) string { return job.Namespace })
+	if len(jobsToRun) != 3 || namespaceCount["team-a"] != 1 || namespaceCount["team-b"] != 1 || namespaceCount["team-c"] != 1 {
+		t.Fatalf("Should get back one job of every namespace: %v", namespaceCount)
+	}
+
+	// team-a already has a job running, which uses up its execution quota
+	jobsToRun, err = jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(5*time.Second), "instance1", 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	namespaceCount = lo.CountValuesBy(jobsToRun, func(job *model.Job) string { return job.Namespace })
+	if len(jobsToRun) != 2 || namespaceCount["team-b"] != 2 {
+		t.Fatalf("Should get back the remaining jobs of team-b only: %v", namespaceCount)
+	}
+}
+
+func auditLog(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ctx = audit.WithRequestID(audit.WithActor(ctx, "jwt:alice"), "req-1")
+
+	// Create, update and delete a job
+	// -------------------------------------------------------------------------
+
+	job, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("* * * * *"),
+		HTTPJob: &model.HTTPJob{
+			URL:    "https://www.ardanlabs.com",
+			Method: "GET",
+			Auth:   model.Auth{Type: model.AuthTypeBearer, BearerToken: null.StringFrom("s3cr3t")},
+		},
+		Tags: []string{"billing"},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	_, err = jobService.UpdateJob(ctx, model.DefaultNamespace, job.ID, null.Int{}, model.JobUpdate{Tags: lo.ToPtr([]string{"nightly"})})
+	if err != nil {
+		t.Fatalf("Should be able to update a job: %s", err)
+	}
+
+	_, err = jobService.PatchJob(ctx, model.DefaultNamespace, job.ID, null.Int{}, []byte(`{"status": "STOPPED"}`))
+	if err != nil {
+		t.Fatalf("Should be able to pause a job: %s", err)
+	}
+
+	if err := jobService.DeleteJob(ctx, model.DefaultNamespace, job.ID, null.Int{}); err != nil {
+		t.Fatalf("Should be able to delete a job: %s", err)
+	}
+
+	// Deleting a missing job is not recorded
+	if err := jobService.DeleteJob(ctx, model.DefaultNamespace, job.ID, null.Int{}); err != nil {
+		t.Fatalf("Should be able to delete a missing job: %s", err)
+	}
+
+	// Audit log
+	// -------------------------------------------------------------------------
+
+	entries, err := jobService.GetJobAudit(ctx, model.DefaultNamespace, job.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get the audit log of a deleted job: %s", err)
+	}
+
+	actions := lo.Map(entries, func(entry model.JobAudit, _ int) model.AuditAction { return entry.Action })
+	if diff := cmp.Diff([]model.AuditAction{model.AuditActionDelete, model.AuditActionPause, model.AuditActionUpdate, model.AuditActionCreate}, actions); diff != "" {
+		t.Fatalf("Should get back the changes newest first: %s", diff)
+	}
+
+	for _, entry := range entries {
+		if entry.Actor != "jwt:alice" || entry.RequestID.String != "req-1" {
+			t.Fatalf("Should record the actor and request ID: %s %s", entry.Actor, entry.RequestID.String)
+		}
+	}
+
+	if diff := cmp.Diff(map[string]model.AuditChange{"tags": {Before: []interface{}{"billing"}, After: []interface{}{"nightly"}}}, entries[2].Changes); diff != "" {
+		t.Fatalf("Should record the changed fields: %s", diff)
+	}
+
+	if token := entries[3].Changes["http_job.auth.bearer_token"].After; token != model.Redacted {
+		t.Fatalf("Should redact secrets: %v", token)
+	}
+
+	entries, err = jobService.ListAudit(ctx, model.DefaultNamespace, model.AuditFilter{Action: model.AuditActionDelete, From: null.TimeFrom(time.Now().Add(-time.Minute))}, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to list the audit log: %s", err)
+	}
+
+	if len(entries) != 1 || entries[0].JobID != job.ID {
+		t.Fatalf("Should get back the deletion: %d", len(entries))
+	}
+
+	entries, err = jobService.ListAudit(ctx, model.DefaultNamespace, model.AuditFilter{Action: model.AuditActionPause}, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to list the audit log: %s", err)
+	}
+
+	if len(entries) != 1 || entries[0].Changes["status"].After != string(model.JobStatusStopped) {
+		t.Fatalf("Should get back the pause: %d", len(entries))
+	}
+
+	entries, err = jobService.ListAudit(ctx, "team-a", model.AuditFilter{}, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to list the audit log: %s", err)
+	}
+
+	if len(entries) != 0 {
+		t.Fatalf("Should not list the audit log of another namespace: %d", len(entries))
+	}
+
+	// The audit log is append-only
+	if _, err := test.DB.ExecContext(ctx, `DELETE FROM job_audit`); err == nil {
+		t.Fatalf("Should not be able to delete audit log entries")
+	}
+}
+
+func versions(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create and update a job
+	// -------------------------------------------------------------------------
+
+	job, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("* * * * *"),
+		HTTPJob: &model.HTTPJob{
+			URL:    "https://www.ardanlabs.com",
+			Method: "GET",
+			Auth:   model.Auth{Type: model.AuthTypeBearer, BearerToken: null.StringFrom("s3cr3t")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	if job.Version != 1 {
+		t.Fatalf("Should create the first version: %d", job.Version)
+	}
+
+	job, err = jobService.UpdateJob(ctx, model.DefaultNamespace, job.ID, null.Int{}, model.JobUpdate{
+		HTTP: &model.HTTPJob{URL: "https://www.ardanlabs.com/broken", Method: "POST", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to update a job: %s", err)
+	}
+
+	if job.Version != 2 {
+		t.Fatalf("Should increment the version: %d", job.Version)
+	}
+
+	// Roll back
+	// -------------------------------------------------------------------------
+
+	if _, err := jobService.RollbackJob(ctx, model.DefaultNamespace, job.ID, 5); !errors.Is(err, errs.ErrJobVersionNotFound) {
+		t.Fatalf("Should not be able to roll back to a missing version: %v", err)
+	}
+
+	if _, err := jobService.RollbackJob(ctx, "team-a", job.ID, 1); !errors.Is(err, errs.ErrJobNotFound) {
+		t.Fatalf("Should not be able to roll back a job of another namespace: %v", err)
+	}
+
+	job, err = jobService.RollbackJob(ctx, model.DefaultNamespace, job.ID, 1)
+	if err != nil {
+		t.Fatalf("Should be able to roll back a job: %s", err)
+	}
+
+	job, err = jobService.GetJob(ctx, model.DefaultNamespace, job.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get a job: %s", err)
+	}
+
+	if job.Version != 3 || job.HTTPJob.URL != "https://www.ardanlabs.com" || job.HTTPJob.Auth.BearerToken.String != "s3cr3t" {
+		t.Fatalf("Should restore the first version as a new version: %d %s", job.Version, job.HTTPJob.URL)
+	}
+
+	// List versions
+	// -------------------------------------------------------------------------
+
+	jobVersions, err := jobService.GetJobVersions(ctx, model.DefaultNamespace, job.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job versions: %s", err)
+	}
+
+	urls := lo.Map(jobVersions, func(version model.JobVersion, _ int) string {
+		return fmt.Sprintf("%d %s", version.Version, version.HTTPJob.URL)
+	})
+	if diff := cmp.Diff([]string{"3 https://www.ardanlabs.com", "2 https://www.ardanlabs.com/broken", "1 https://www.ardanlabs.com"}, urls); diff != "" {
+		t.Fatalf("Should get back all versions, newest first: %s", diff)
+	}
+
+	jobVersions, err = jobService.GetJobVersions(ctx, "team-a", job.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job versions: %s", err)
+	}
+
+	if len(jobVersions) != 0 {
+		t.Fatalf("Should not get back versions of a job of another namespace: %d", len(jobVersions))
+	}
+}
+
+func patch(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	job, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
+		Type:      model.JobTypeHTTP,
+		ExecuteAt: null.TimeFrom(now.Add(1 * time.Second)),
+		HTTPJob: &model.HTTPJob{
+			URL:    "https://www.ardanlabs.com",
+			Method: "GET",
+			Auth:   model.Auth{Type: model.AuthTypeBearer, BearerToken: null.StringFrom("s3cr3t")},
+		},
+		Tags: []string{"billing"},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// Pause the job and change its tags and URL
+	// -------------------------------------------------------------------------
+
+	_, err = jobService.PatchJob(ctx, model.DefaultNamespace, job.ID, null.IntFrom(job.Version), []byte(`{
+		"status": "STOPPED",
+		"tags": ["billing", "nightly"],
+		"http_job": {"url": "https://www.ardanlabs.com/v2"}
+	}`))
+	if err != nil {
+		t.Fatalf("Should be able to patch a job: %s", err)
+	}
+
+	job, err = jobService.GetJob(ctx, model.DefaultNamespace, job.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get a job: %s", err)
+	}
+
+	if job.Status != model.JobStatusStopped || job.HTTPJob.URL != "https://www.ardanlabs.com/v2" {
+		t.Fatalf("Should persist the patched fields: %s %s", job.Status, job.HTTPJob.URL)
+	}
+
+	if diff := cmp.Diff([]string{"billing", "nightly"}, []string(job.Tags)); diff != "" {
+		t.Fatalf("Should persist the patched tags: %s", diff)
+	}
+
+	if job.HTTPJob.Auth.BearerToken.String != "s3cr3t" || job.HTTPJob.Method != "GET" {
+		t.Fatalf("Should keep the fields omitted from the patch: %s", job.HTTPJob.Method)
+	}
+
+	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(5*time.Second), "instance1", 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 0 {
+		t.Fatalf("Should not run a stopped job: %d", len(jobs))
+	}
+
+	// Invalid patches
+	// -------------------------------------------------------------------------
+
+	if _, err := jobService.PatchJob(ctx, model.DefaultNamespace, job.ID, null.Int{}, []byte(`{"status": "PAUSED"}`)); !errors.Is(err, errs.ErrInvalidJobStatus) {
+		t.Fatalf("Should not be able to patch an invalid status: %v", err)
+	}
+
+	if _, err := jobService.PatchJob(ctx, model.DefaultNamespace, job.ID, null.IntFrom(1), []byte(`{"status": "RUNNING"}`)); !errors.Is(err, errs.ErrJobVersionMismatch) {
+		t.Fatalf("Should not be able to patch a job with a stale version: %v", err)
+	}
+}
+
+func uniqueKey(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	newJob := func(url string) *model.JobCreate {
+		return &model.JobCreate{
+			Type:         model.JobTypeHTTP,
+			CronSchedule: null.StringFrom("0 * * * *"),
+			HTTPJob:      &model.HTTPJob{URL: url, Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+			UniqueKey:    null.StringFrom("nightly-report"),
+		}
+	}
+
+	// Create a job with a unique key
+	// -------------------------------------------------------------------------
+
+	job, created, err := jobService.CreateJob(ctx, model.DefaultNamespace, newJob("https://www.ardanlabs.com"))
+	if err != nil || !created {
+		t.Fatalf("Should be able to create a job: %v %v", created, err)
+	}
+
+	// Creating a job with the same key returns the existing job
+	// -------------------------------------------------------------------------
+
+	existing, created, err := jobService.CreateJob(ctx, model.DefaultNamespace, newJob("https://www.ardanlabs.com/v2"))
+	if err != nil || created {
+		t.Fatalf("Should get back the existing job: %v %v", created, err)
+	}
+
+	if existing.ID != job.ID || existing.HTTPJob.URL != "https://www.ardanlabs.com" {
+		t.Fatalf("Should get back the existing job: %s %s", existing.ID, existing.HTTPJob.URL)
+	}
+
+	page, err := jobService.ListJobs(ctx, model.DefaultNamespace, model.JobQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("Should be able to list jobs: %s", err)
+	}
+
+	if len(page.Jobs) != 1 {
+		t.Fatalf("Should not create a second job with the same key: %d", len(page.Jobs))
+	}
+
+	// Keys are unique within a namespace
+	// -------------------------------------------------------------------------
+
+	other, created, err := jobService.CreateJob(ctx, "team-a", newJob("https://www.ardanlabs.com"))
+	if err != nil || !created || other.ID == job.ID {
+		t.Fatalf("Should be able to create a job with the same key in another namespace: %v %v", created, err)
+	}
+
+	// The key can't be patched
+	// -------------------------------------------------------------------------
+
+	patched, err := jobService.PatchJob(ctx, model.DefaultNamespace, job.ID, null.Int{}, []byte(`{"unique_key": "other"}`))
+	if err != nil {
+		t.Fatalf("Should be able to patch a job: %s", err)
+	}
+
+	if patched.UniqueKey.String != "nightly-report" {
+		t.Fatalf("Should keep the unique key: %s", patched.UniqueKey.String)
+	}
+}
+
+func batch(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	newJob := func(url string, tags ...string) model.JobCreate {
+		return model.JobCreate{
+			Type:         model.JobTypeHTTP,
+			CronSchedule: null.StringFrom("0 * * * *"),
+			HTTPJob: &model.HTTPJob{
+				URL:    url,
+				Method: "GET",
+				Auth:   model.Auth{Type: model.AuthTypeBearer, BearerToken: null.StringFrom("s3cr3t")},
+			},
+			Tags: tags,
+		}
+	}
+
+	// An invalid job rejects the whole batch
+	// -------------------------------------------------------------------------
+
+	invalid := newJob("https://www.ardanlabs.com")
+	invalid.CronSchedule = null.StringFrom("every minute")
+
+	results, err := jobService.BatchCreateJobs(ctx, model.DefaultNamespace, &model.JobBatchCreate{
+		Jobs: []model.JobCreate{newJob("https://www.ardanlabs.com"), invalid},
+	})
+	if !errors.Is(err, errs.ErrInvalidJobBatch) {
+		t.Fatalf("Should not be able to create a batch with an invalid job: %v", err)
+	}
+
+	if results[0].Err != nil || !errors.Is(results[1].Err, errs.ErrInvalidCronSchedule) {
+		t.Fatalf("Should get back the error of the invalid job: %v %v", results[0].Err, results[1].Err)
+	}
+
+	page, err := jobService.ListJobs(ctx, model.DefaultNamespace, model.JobQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("Should be able to list jobs: %s", err)
+	}
+
+	if len(page.Jobs) != 0 {
+		t.Fatalf("Should not create any job of an invalid batch: %d", len(page.Jobs))
+	}
+
+	// Create a batch
+	// -------------------------------------------------------------------------
+
+	results, err = jobService.BatchCreateJobs(ctx, model.DefaultNamespace, &model.JobBatchCreate{
+		Jobs: []model.JobCreate{
+			newJob("https://www.ardanlabs.com/1", "customer-1"),
+			newJob("https://www.ardanlabs.com/2", "customer-1"),
+			newJob("https://www.ardanlabs.com/3", "customer-2"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a batch of jobs: %s", err)
+	}
+
+	if len(results) != 3 || !results[0].Created || results[0].Job == nil {
+		t.Fatalf("Should get back the created jobs: %v", results)
+	}
+
+	// Update the jobs of a customer
+	// -------------------------------------------------------------------------
+
+	results, err = jobService.BatchUpdateJobs(ctx, model.DefaultNamespace, &model.JobBatchUpdate{
+		JobSelector: model.JobSelector{Tags: []string{"customer-1"}},
+		Update:      model.JobUpdate{CronSchedule: lo.ToPtr("30 * * * *")},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to update a batch of jobs: %s", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Should update the jobs with the tag: %d", len(results))
+	}
+
+	for _, result := range results {
+		job, err := jobService.GetJob(ctx, model.DefaultNamespace, result.ID)
+		if err != nil {
+			t.Fatalf("Should be able to get a job: %s", err)
+		}
+
+		if job.CronSchedule.String != "30 * * * *" || job.Version != 2 || job.HTTPJob.Auth.BearerToken.String != "s3cr3t" {
+			t.Fatalf("Should persist the update: %s %d", job.CronSchedule.String, job.Version)
+		}
+	}
+
+	// Updating a job that doesn't exist rejects the whole batch
+	// -------------------------------------------------------------------------
+
+	_, err = jobService.BatchUpdateJobs(ctx, model.DefaultNamespace, &model.JobBatchUpdate{
+		JobSelector: model.JobSelector{IDs: []uuid.UUID{results[0].ID, uuid.New()}},
+		Update:      model.JobUpdate{CronSchedule: lo.ToPtr("45 * * * *")},
+	})
+	if !errors.Is(err, errs.ErrInvalidJobBatch) {
+		t.Fatalf("Should not be able to update a job that doesn't exist: %v", err)
+	}
+
+	// Updating a job that was modified in the meantime rejects the whole batch
+	// -------------------------------------------------------------------------
+
+	conflicts, err := jobService.BatchUpdateJobs(ctx, model.DefaultNamespace, &model.JobBatchUpdate{
+		JobSelector: model.JobSelector{
+			IDs:              []uuid.UUID{results[0].ID, results[1].ID},
+			ExpectedVersions: map[uuid.UUID]int64{results[0].ID: 2, results[1].ID: 1},
+		},
+		Update: model.JobUpdate{CronSchedule: lo.ToPtr("45 * * * *")},
+	})
+	if !errors.Is(err, errs.ErrInvalidJobBatch) {
+		t.Fatalf("Should not be able to update a job with another version: %v", err)
+	}
+
+	if conflicts[0].Err != nil || !errors.Is(conflicts[1].Err, errs.ErrJobBatchVersionMismatch) {
+		t.Fatalf("Should report the version mismatch of the job: %v %v", conflicts[0].Err, conflicts[1].Err)
+	}
+
+	// Delete the jobs of a customer
+	// -------------------------------------------------------------------------
+
+	results, err = jobService.BatchDeleteJobs(ctx, model.DefaultNamespace, &model.JobBatchDelete{
+		JobSelector: model.JobSelector{Tags: []string{"customer-1"}},
+	})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Should be able to delete a batch of jobs: %v", err)
+	}
+
+	page, err = jobService.ListJobs(ctx, model.DefaultNamespace, model.JobQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("Should be able to list jobs: %s", err)
+	}
+
+	if len(page.Jobs) != 1 || page.Jobs[0].Tags[0] != "customer-2" {
+		t.Fatalf("Should only delete the jobs with the tag: %d", len(page.Jobs))
+	}
+
+	// Deleting a job that was modified in the meantime reports the conflict
+	// -------------------------------------------------------------------------
+
+	results, err = jobService.BatchDeleteJobs(ctx, model.DefaultNamespace, &model.JobBatchDelete{
+		JobSelector: model.JobSelector{
+			IDs:              []uuid.UUID{page.Jobs[0].ID},
+			ExpectedVersions: map[uuid.UUID]int64{page.Jobs[0].ID: page.Jobs[0].Version + 1},
+		},
+	})
+	if err != nil || len(results) != 1 || !errors.Is(results[0].Err, errs.ErrJobBatchVersionMismatch) {
+		t.Fatalf("Should report the version mismatch of the job: %v %v", err, results)
+	}
+
+	if _, err := jobService.GetJob(ctx, model.DefaultNamespace, page.Jobs[0].ID); err != nil {
+		t.Fatalf("Should not delete a job with another version: %s", err)
+	}
+}
+
+func list(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	newJob := func(url string, tags ...string) model.JobCreate {
+		return model.JobCreate{
+			Type:         model.JobTypeHTTP,
+			CronSchedule: null.StringFrom("0 * * * *"),
+			HTTPJob:      &model.HTTPJob{URL: url, Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+			Tags:         tags,
+		}
+	}
+
+	_, err := jobService.BatchCreateJobs(ctx, model.DefaultNamespace, &model.JobBatchCreate{
+		Jobs: []model.JobCreate{
+			newJob("https://billing.example.com/invoices", "billing", "nightly"),
+			newJob("https://billing.example.com/reminders", "billing"),
+			newJob("https://reports.example.com/q_1", "reports"),
+			newJob("https://reports.example.com/qx1/daily", "reports", "nightly"),
+			newJob("https://www.ardanlabs.com"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create jobs: %s", err)
+	}
+
+	// Filters
+	// -------------------------------------------------------------------------
+
+	tests := []struct {
+		name   string
+		filter model.JobFilter
+		total  int64
+	}{
+		{name: "no filter", filter: model.JobFilter{}, total: 5},
+		{name: "type", filter: model.JobFilter{Type: model.JobTypeAMQP}, total: 0},
+		{name: "status", filter: model.JobFilter{Status: model.JobStatusRunning}, total: 5},
+		{name: "all tags", filter: model.JobFilter{Tags: []string{"billing", "nightly"}}, total: 1},
+		{name: "any tag", filter: model.JobFilter{Tags: []string{"billing", "nightly"}, TagMatch: model.TagMatchAny}, total: 3},
+		{name: "url", filter: model.JobFilter{URL: "BILLING.example"}, total: 2},
+		{name: "url with wildcard", filter: model.JobFilter{URL: "q_1"}, total: 1},
+		{name: "next run", filter: model.JobFilter{NextRunFrom: null.TimeFrom(time.Now().Add(2 * time.Hour))}, total: 0},
+		{name: "created", filter: model.JobFilter{CreatedFrom: null.TimeFrom(time.Now().Add(-time.Minute))}, total: 5},
+	}
+
+	for _, tt := range tests {
+		page, err := jobService.ListJobs(ctx, model.DefaultNamespace, model.JobQuery{JobFilter: tt.filter, Limit: 10})
+		if err != nil {
+			t.Fatalf("Should be able to list jobs by %s: %s", tt.name, err)
+		}
+
+		if page.Total != tt.total || int64(len(page.Jobs)) != tt.total {
+			t.Fatalf("Should get back %d jobs by %s: %d %d", tt.total, tt.name, page.Total, len(page.Jobs))
+		}
+	}
+
+	// Cursor pagination
+	// -------------------------------------------------------------------------
+
+	for _, sort := range []model.JobSort{model.DefaultJobSort, model.JobSortNextRun, "-updated_at"} {
+		var ids []uuid.UUID
+
+		query := model.JobQuery{Sort: sort, Limit: 2}
+		for {
+			page, err := jobService.ListJobs(ctx, model.DefaultNamespace, query)
+			if err != nil {
+				t.Fatalf("Should be able to list jobs by %s: %s", sort, err)
+			}
+
+			if page.Total != 5 {
+				t.Fatalf("Should get back the total number of jobs: %d", page.Total)
+			}
+
+			ids = append(ids, lo.Map(page.Jobs, func(job model.Job, _ int) uuid.UUID { return job.ID })...)
+
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+
+		if len(ids) != 5 || len(lo.Uniq(ids)) != 5 {
+			t.Fatalf("Should get back every job once by %s: %d", sort, len(ids))
+		}
+	}
+}
+
+func stats(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := postgres.New(test.DB, test.Log)
+	jobService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("@every 1m"),
+		HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// Record executions: a success, then three failures, of 100ms to 400ms, each started 1s after it was planned
+	// -------------------------------------------------------------------------
+
+	now := time.Now()
+	statuses := []model.JobExecutionStatus{
+		model.JobExecutionStatusSuccessful,
+		model.JobExecutionStatusFailed,
+		model.JobExecutionStatusFailed,
+		model.JobExecutionStatusFailed,
+	}
+
+	for i, status := range statuses {
+		start := now.Add(time.Duration(i-10) * time.Minute)
+		stop := start.Add(time.Duration(i+1) * 100 * time.Millisecond)
+
+		err := store.CreateJobExecution(ctx, job.ID, job.Version, "instance1", null.TimeFrom(start.Add(-time.Second)), start, stop, status, null.String{})
+		if err != nil {
+			t.Fatalf("Should be able to create a job execution: %s", err)
+		}
+	}
+
+	// Job stats
+	// -------------------------------------------------------------------------
+
+	jobStats, err := jobService.GetJobStats(ctx, model.DefaultNamespace, job.ID, []model.StatsWindow{"1h", "5m"})
+	if err != nil {
+		t.Fatalf("Should be able to get job stats: %s", err)
+	}
+
+	if jobStats.CurrentFailureStreak != 3 {
+		t.Fatalf("Should get back the current failure streak: %d", jobStats.CurrentFailureStreak)
+	}
+
+	hour := jobStats.Windows[0]
+	if hour.Executions != 4 || hour.Successful != 1 || hour.Failed != 3 || hour.SuccessRate.Float64 != 0.25 {
+		t.Fatalf("Should count the executions of the window: %+v", hour)
+	}
+
+	if hour.DurationP50.Float64 != 250 || hour.SchedulingLagAvg.Float64 != 1000 || hour.LongestFailureStreak != 3 {
+		t.Fatalf("Should get back the durations, lag and failure streak of the window: %+v", hour)
+	}
+
+	if empty := jobStats.Windows[1]; empty.Executions != 0 || empty.SuccessRate.Valid || empty.DurationP50.Valid {
+		t.Fatalf("Should get back empty stats for a window without executions: %+v", empty)
+	}
+
+	if _, err := jobService.GetJobStats(ctx, model.DefaultNamespace, uuid.New(), []model.StatsWindow{"1h"}); !errors.Is(err, errs.ErrJobNotFound) {
+		t.Fatalf("Should not get stats of a job that doesn't exist: %v", err)
+	}
+
+	// Namespace stats
+	// -------------------------------------------------------------------------
+
+	namespaceStats, err := jobService.GetStats(ctx, model.DefaultNamespace, []model.StatsWindow{"1h"})
+	if err != nil {
+		t.Fatalf("Should be able to get stats: %s", err)
+	}
+
+	if namespaceStats.FailingJobs != 1 || namespaceStats.Windows[0].Executions != 4 {
+		t.Fatalf("Should get back the stats of the namespace: %+v", namespaceStats)
+	}
+
+	if _, err := jobService.GetStats(ctx, model.DefaultNamespace, []model.StatsWindow{"1y"}); !errors.Is(err, errs.ErrInvalidStatsWindow) {
+		t.Fatalf("Should not get stats over an invalid window: %v", err)
+	}
 }
@@ -0,0 +1,80 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"go.uber.org/zap"
+)
+
+// GetJobStats returns the execution statistics of the job over each of the windows, which end now.
+func (s *Service) GetJobStats(ctx context.Context, namespace string, id uuid.UUID, windows []model.StatsWindow) (*model.JobStats, error) {
+	s.log.Info("Getting job stats", zap.String("namespace", namespace), zap.Any("id", id), zap.Any("windows", windows))
+
+	if err := model.ValidateStatsWindows(windows); err != nil {
+		return nil, err
+	}
+
+	// Statistics of jobs that don't exist are not found, rather than empty
+	if _, err := s.store.GetJob(ctx, namespace, id); err != nil {
+		return nil, err
+	}
+
+	streak, err := s.store.GetJobFailureStreak(ctx, namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.windowStats(ctx, namespace, uuid.NullUUID{UUID: id, Valid: true}, windows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.JobStats{JobID: id, CurrentFailureStreak: streak, Windows: stats}, nil
+}
+
+// GetStats returns the execution statistics of all jobs of the namespace over each of the windows, which end now.
+func (s *Service) GetStats(ctx context.Context, namespace string, windows []model.StatsWindow) (*model.NamespaceStats, error) {
+	s.log.Info("Getting stats", zap.String("namespace", namespace), zap.Any("windows", windows))
+
+	if err := model.ValidateStatsWindows(windows); err != nil {
+		return nil, err
+	}
+
+	failing, err := s.store.CountFailingJobs(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.windowStats(ctx, namespace, uuid.NullUUID{}, windows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.NamespaceStats{Namespace: namespace, FailingJobs: failing, Windows: stats}, nil
+}
+
+// windowStats computes the statistics of each window. All windows end at the same time.
+func (s *Service) windowStats(ctx context.Context, namespace string, jobID uuid.NullUUID, windows []model.StatsWindow) ([]model.ExecutionStats, error) {
+	now := time.Now()
+
+	stats := make([]model.ExecutionStats, 0, len(windows))
+	for _, window := range windows {
+		duration, err := window.Duration()
+		if err != nil {
+			return nil, err
+		}
+
+		windowStats, err := s.store.GetExecutionStats(ctx, namespace, jobID, now.Add(-duration), now)
+		if err != nil {
+			return nil, err
+		}
+
+		windowStats.Window = window
+		stats = append(stats, *windowStats)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,55 @@
+package namespace
+
+import (
+	"context"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store"
+	"go.uber.org/zap"
+)
+
+// Service manages the quotas of namespaces.
+type Service struct {
+	store store.Storer
+	log   *otelzap.Logger
+}
+
+// NewService creates a new namespace service with the given store and logger.
+func NewService(store store.Storer, log *otelzap.Logger) *Service {
+	return &Service{
+		store: store,
+		log:   log,
+	}
+}
+
+// GetNamespace returns the namespace with the given name.
+func (s *Service) GetNamespace(ctx context.Context, name string) (*model.Namespace, error) {
+	if err := model.ValidateNamespace(name); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetNamespace(ctx, name)
+}
+
+// ListNamespaces returns a list of namespaces with quotas with the given limit and offset.
+func (s *Service) ListNamespaces(ctx context.Context, limit, offset uint64) ([]model.Namespace, error) {
+	s.log.Info("Getting namespaces")
+	return s.store.ListNamespaces(ctx, limit, offset)
+}
+
+// SetQuota replaces the quota of the namespace. Jobs over the maximum number of jobs are kept, but no new jobs can be
+// created until the namespace is below the quota.
+func (s *Service) SetQuota(ctx context.Context, name string, quota model.NamespaceQuota) (*model.Namespace, error) {
+	s.log.Info("Setting namespace quota", zap.String("namespace", name), zap.Any("maxJobs", quota.MaxJobs), zap.Any("maxExecutionsPerMinute", quota.MaxExecutionsPerMinute))
+
+	if err := model.ValidateNamespace(name); err != nil {
+		return nil, err
+	}
+
+	if err := quota.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.store.SetNamespaceQuota(ctx, name, quota)
+}
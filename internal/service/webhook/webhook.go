@@ -0,0 +1,326 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/egress"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	// DefaultRetention is how long delivered events are kept in the delivery log.
+	DefaultRetention = 7 * 24 * time.Hour
+
+	// MaxAttempts is the number of attempts after which a delivery fails.
+	MaxAttempts = 10
+
+	// Failed attempts are retried after the initial backoff, doubled after every attempt up to the maximum backoff
+	initialBackoff = 30 * time.Second
+	maxBackoff     = 6 * time.Hour
+
+	deliveryTimeout = 10 * time.Second
+
+	// deliveryLease is how long a claimed delivery is leased to the manager attempting it. If the manager stops before
+	// completing the attempt, the delivery is attempted again after the lease expires.
+	deliveryLease = time.Minute
+
+	pollInterval    = time.Second
+	batchSize       = 100
+	cleanupInterval = 10 * time.Minute
+
+	// maxErrorLength limits the length of the recorded error of an attempt
+	maxErrorLength = 1024
+)
+
+// Headers of deliveries. The signature is "sha256=" followed by the hex encoded HMAC-SHA256 of the timestamp, a dot and
+// the body, keyed with the subscription's secret.
+const (
+	HeaderEvent     = "X-Scheduler-Event"
+	HeaderDelivery  = "X-Scheduler-Delivery"
+	HeaderTimestamp = "X-Scheduler-Timestamp"
+	HeaderSignature = "X-Scheduler-Signature"
+)
+
+// Service manages webhook subscriptions and delivers the webhook events written to the outbox by the store.
+type Service struct {
+	store     store.Storer
+	log       *otelzap.Logger
+	egress    *egress.Policy
+	client    *http.Client
+	retention time.Duration
+}
+
+// NewService creates a new webhook service with the given store and logger.
+func NewService(store store.Storer, log *otelzap.Logger) *Service {
+	return &Service{
+		store:     store,
+		log:       log,
+		client:    &http.Client{Timeout: deliveryTimeout},
+		retention: DefaultRetention,
+	}
+}
+
+// WithEgressPolicy makes the service reject subscriptions to destinations denied by the policy, and enforces the
+// policy when delivering events.
+func (s *Service) WithEgressPolicy(policy *egress.Policy) *Service {
+	s.egress = policy
+	s.client = policy.HTTPClient(s.client)
+	return s
+}
+
+// WithRetention sets how long delivered events are kept. A non-positive retention keeps the default.
+func (s *Service) WithRetention(retention time.Duration) *Service {
+	if retention > 0 {
+		s.retention = retention
+	}
+
+	return s
+}
+
+// CreateSubscription validates and stores a new subscription in the namespace, with a new signing secret.
+func (s *Service) CreateSubscription(ctx context.Context, namespace string, create *model.WebhookSubscriptionCreate) (*model.WebhookSubscription, error) {
+	s.log.Info("Creating webhook subscription", zap.String("namespace", namespace), zap.Any("events", create.Events))
+
+	subscription := create.ToSubscription(namespace)
+	if err := s.validate(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	secret, err := security.GenerateWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	subscription.Secret = null.StringFrom(secret)
+
+	if err := s.store.CreateWebhookSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription returns the subscription with the given ID from the namespace.
+func (s *Service) GetSubscription(ctx context.Context, namespace string, id uuid.UUID) (*model.WebhookSubscription, error) {
+	s.log.Info("Getting a webhook subscription", zap.String("namespace", namespace), zap.String("id", id.String()))
+	return s.store.GetWebhookSubscription(ctx, namespace, id)
+}
+
+// ListSubscriptions returns a list of subscriptions of the namespace with the given limit and offset.
+func (s *Service) ListSubscriptions(ctx context.Context, namespace string, limit, offset uint64) ([]model.WebhookSubscription, error) {
+	s.log.Info("Getting webhook subscriptions", zap.String("namespace", namespace))
+	return s.store.ListWebhookSubscriptions(ctx, namespace, limit, offset)
+}
+
+// UpdateSubscription applies the update to the subscription with the given ID, and replaces its secret if requested.
+func (s *Service) UpdateSubscription(ctx context.Context, namespace string, id uuid.UUID, update model.WebhookSubscriptionUpdate) (*model.WebhookSubscription, error) {
+	s.log.Info("Updating a webhook subscription", zap.String("namespace", namespace), zap.String("id", id.String()))
+
+	subscription, err := s.store.GetWebhookSubscription(ctx, namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.ApplyUpdate(update)
+	if err := s.validate(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	if update.RotateSecret {
+		secret, err := security.GenerateWebhookSecret()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		subscription.Secret = null.StringFrom(secret)
+	}
+
+	if err := s.store.UpdateWebhookSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription deletes the subscription with the given ID and its deliveries from the namespace.
+func (s *Service) DeleteSubscription(ctx context.Context, namespace string, id uuid.UUID) error {
+	s.log.Info("Deleting a webhook subscription", zap.String("namespace", namespace), zap.String("id", id.String()))
+	return s.store.DeleteWebhookSubscription(ctx, namespace, id)
+}
+
+// ListDeliveries returns the deliveries of the subscription with the given ID, newest first.
+func (s *Service) ListDeliveries(ctx context.Context, namespace string, id uuid.UUID, limit, offset uint64) ([]model.WebhookDelivery, error) {
+	s.log.Info("Getting webhook deliveries", zap.String("namespace", namespace), zap.String("id", id.String()))
+
+	if _, err := s.store.GetWebhookSubscription(ctx, namespace, id); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListWebhookDeliveries(ctx, namespace, id, limit, offset)
+}
+
+func (s *Service) validate(ctx context.Context, subscription *model.WebhookSubscription) error {
+	if err := subscription.Validate(); err != nil {
+		return err
+	}
+
+	return s.egress.Validate(ctx, subscription.URL)
+}
+
+// Run delivers webhook events and deletes old events until the context is done. Any number of managers can run
+// concurrently; each event is fanned out and each delivery attempted by a single manager.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.poll(ctx)
+
+			if time.Since(lastCleanup) >= cleanupInterval {
+				lastCleanup = time.Now()
+				if err := s.store.DeleteWebhookEvents(ctx, lastCleanup.Add(-s.retention)); err != nil {
+					s.log.Warn("Failed to delete old webhook events", zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// poll fans out the new events and attempts the due deliveries.
+func (s *Service) poll(ctx context.Context) {
+	for {
+		processed, err := s.store.ProcessWebhookEvents(ctx, batchSize)
+		if err != nil {
+			s.log.Warn("Failed to process webhook events", zap.Error(err))
+			return
+		}
+
+		if processed < batchSize {
+			break
+		}
+	}
+
+	now := time.Now()
+	deliveries, err := s.store.ClaimWebhookDeliveries(ctx, now, now.Add(deliveryLease), batchSize)
+	if err != nil {
+		s.log.Warn("Failed to claim webhook deliveries", zap.Error(err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *model.WebhookDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+}
+
+// deliver attempts the delivery and records the outcome. Failed attempts are retried with an exponential backoff
+// until the delivery runs out of attempts.
+func (s *Service) deliver(ctx context.Context, delivery *model.WebhookDelivery) {
+	statusCode, err := s.send(ctx, delivery)
+
+	delivery.LastStatusCode = null.NewInt(int64(statusCode), statusCode != 0)
+	delivery.LastError = null.String{}
+	delivery.NextAttemptAt = null.Time{}
+
+	switch {
+	case err == nil:
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.DeliveredAt = null.TimeFrom(time.Now())
+	case delivery.Attempts >= MaxAttempts:
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.LastError = null.StringFrom(truncate(err.Error()))
+	default:
+		delivery.Status = model.WebhookDeliveryPending
+		delivery.LastError = null.StringFrom(truncate(err.Error()))
+		delivery.NextAttemptAt = null.TimeFrom(time.Now().Add(Backoff(delivery.Attempts)))
+	}
+
+	if err != nil {
+		s.log.Warn("Webhook delivery attempt failed",
+			zap.Int64("delivery", delivery.ID),
+			zap.String("subscription", delivery.SubscriptionID.String()),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Error(err),
+		)
+	}
+
+	if err := s.store.CompleteWebhookDelivery(ctx, delivery); err != nil {
+		s.log.Warn("Failed to record webhook delivery attempt", zap.Int64("delivery", delivery.ID), zap.Error(err))
+	}
+}
+
+// send posts the signed event to the subscription's URL. Any 2xx response is a successful delivery.
+func (s *Service) send(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(delivery.Event.Type))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+security.SignWebhook(delivery.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain the body, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Backoff returns how long to wait before the next attempt of a delivery after the given number of failed attempts.
+func Backoff(attempts int) time.Duration {
+	backoff := initialBackoff
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxBackoff)
+}
+
+func truncate(message string) string {
+	if len(message) <= maxErrorLength {
+		return message
+	}
+
+	return message[:maxErrorLength]
+}
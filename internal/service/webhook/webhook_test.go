@@ -0,0 +1,376 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/audit"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database/dbtest"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/tests/docker"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/job"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
+	"gopkg.in/guregu/null.v4"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = dbtest.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer dbtest.StopDB(c)
+
+	encryptor, err := security.NewEncryptor("testkey123456789")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	postgres.SetEncryptor(encryptor)
+
+	m.Run()
+}
+
+// receiver records the deliveries it receives and responds with its status code.
+type receiver struct {
+	mu         sync.Mutex
+	statusCode int
+	requests   []*http.Request
+	bodies     [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.statusCode)
+}
+
+func (r *receiver) setStatusCode(statusCode int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statusCode = statusCode
+}
+
+func (r *receiver) received() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func Test_Webhooks(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := postgres.New(test.DB, test.Log)
+	jobService := job.NewService(store, test.Log)
+	webhookService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = audit.WithActor(ctx, "jwt:alice")
+
+	hook := &receiver{statusCode: http.StatusOK}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	// Subscriptions
+	// -------------------------------------------------------------------------
+
+	_, err := webhookService.CreateSubscription(ctx, model.DefaultNamespace, &model.WebhookSubscriptionCreate{URL: server.URL})
+	if !errors.Is(err, errs.ErrInvalidWebhookEvents) {
+		t.Fatalf("Should not create a subscription without events: %v", err)
+	}
+
+	subscription, err := webhookService.CreateSubscription(ctx, model.DefaultNamespace, &model.WebhookSubscriptionCreate{
+		URL:    server.URL,
+		Events: []model.WebhookEventType{model.WebhookEventJobCreated, model.WebhookEventJobDeleted, model.WebhookEventExecutionFailed},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a subscription: %s", err)
+	}
+
+	if !subscription.Enabled || subscription.Secret.ValueOrZero() == "" {
+		t.Fatalf("Should create an enabled subscription with a secret: %+v", subscription)
+	}
+
+	disabled, err := webhookService.CreateSubscription(ctx, model.DefaultNamespace, &model.WebhookSubscriptionCreate{
+		URL:     server.URL,
+		Events:  []model.WebhookEventType{model.WebhookEventJobCreated},
+		Enabled: null.BoolFrom(false),
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a disabled subscription: %s", err)
+	}
+
+	if _, err := webhookService.GetSubscription(ctx, "team-a", subscription.ID); !errors.Is(err, errs.ErrWebhookSubscriptionNotFound) {
+		t.Fatalf("Should not get a subscription of another namespace: %v", err)
+	}
+
+	// Job events are delivered with a signature
+	// -------------------------------------------------------------------------
+
+	created, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
+		Type:      model.JobTypeHTTP,
+		ExecuteAt: null.TimeFrom(time.Now().Add(time.Hour)),
+		HTTPJob: &model.HTTPJob{
+			URL:    "https://example.com",
+			Method: "POST",
+			Auth:   model.Auth{Type: model.AuthTypeBearer, BearerToken: null.StringFrom("secret-token")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// jobs of other namespaces and unsubscribed events are not delivered
+	other, _, err := jobService.CreateJob(ctx, "team-a", &model.JobCreate{
+		Type:      model.JobTypeHTTP,
+		ExecuteAt: null.TimeFrom(time.Now().Add(time.Hour)),
+		HTTPJob:   &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	if _, err := jobService.UpdateJob(ctx, model.DefaultNamespace, created.ID, null.Int{}, model.JobUpdate{Tags: &[]string{"billing"}}); err != nil {
+		t.Fatalf("Should be able to update the job: %s", err)
+	}
+
+	webhookService.poll(ctx)
+
+	if hook.received() != 1 {
+		t.Fatalf("Should deliver only the created event: %d", hook.received())
+	}
+
+	req, body := hook.requests[0], hook.bodies[0]
+	if req.Header.Get(HeaderEvent) != string(model.WebhookEventJobCreated) {
+		t.Fatalf("Should deliver the created event: %s", req.Header.Get(HeaderEvent))
+	}
+
+	signature := "sha256=" + security.SignWebhook(subscription.Secret.String, req.Header.Get(HeaderTimestamp), body)
+	if req.Header.Get(HeaderSignature) != signature {
+		t.Fatalf("Should sign the delivery with the subscription's secret: %s", req.Header.Get(HeaderSignature))
+	}
+
+	var event model.WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("Should deliver the event as JSON: %s", err)
+	}
+
+	var data model.WebhookJobData
+	if err := json.Unmarshal(event.Data, &data); err != nil || data.ID != created.ID || data.Actor != "jwt:alice" {
+		t.Fatalf("Should deliver the job and actor: %+v, %v", data, err)
+	}
+
+	if strings.Contains(string(body), "secret-token") {
+		t.Fatalf("Should not deliver the job's secrets: %s", body)
+	}
+
+	deliveries, err := webhookService.ListDeliveries(ctx, model.DefaultNamespace, subscription.ID, 10, 0)
+	if err != nil || len(deliveries) != 1 || deliveries[0].Status != model.WebhookDeliverySucceeded || deliveries[0].Attempts != 1 {
+		t.Fatalf("Should record the successful delivery: %+v, %v", deliveries, err)
+	}
+
+	deliveries, err = webhookService.ListDeliveries(ctx, model.DefaultNamespace, disabled.ID, 10, 0)
+	if err != nil || len(deliveries) != 0 {
+		t.Fatalf("Should not deliver events to disabled subscriptions: %+v, %v", deliveries, err)
+	}
+
+	if _, err := webhookService.ListDeliveries(ctx, "team-a", subscription.ID, 10, 0); !errors.Is(err, errs.ErrWebhookSubscriptionNotFound) {
+		t.Fatalf("Should not list the deliveries of another namespace: %v", err)
+	}
+
+	// Failed executions are delivered
+	// -------------------------------------------------------------------------
+
+	now := time.Now()
+	if err := store.CreateJobExecution(ctx, created.ID, created.Version, "instance1", null.Time{}, now, now.Add(time.Second), model.JobExecutionStatusSuccessful, null.String{}); err != nil {
+		t.Fatalf("Should be able to record an execution: %s", err)
+	}
+
+	if err := store.CreateJobExecution(ctx, created.ID, created.Version, "instance1", null.Time{}, now, now.Add(time.Second), model.JobExecutionStatusFailed, null.StringFrom("timeout")); err != nil {
+		t.Fatalf("Should be able to record an execution: %s", err)
+	}
+
+	if err := store.CreateJobExecution(ctx, other.ID, other.Version, "instance1", null.Time{}, now, now.Add(time.Second), model.JobExecutionStatusFailed, null.StringFrom("timeout")); err != nil {
+		t.Fatalf("Should be able to record an execution: %s", err)
+	}
+
+	webhookService.poll(ctx)
+
+	if hook.received() != 2 || hook.requests[1].Header.Get(HeaderEvent) != string(model.WebhookEventExecutionFailed) {
+		t.Fatalf("Should deliver only the failed execution of the namespace: %d", hook.received())
+	}
+
+	// Failed deliveries are retried with a backoff
+	// -------------------------------------------------------------------------
+
+	hook.setStatusCode(http.StatusInternalServerError)
+
+	if err := jobService.DeleteJob(ctx, model.DefaultNamespace, created.ID, null.Int{}); err != nil {
+		t.Fatalf("Should be able to delete the job: %s", err)
+	}
+
+	webhookService.poll(ctx)
+
+	deliveries, err = webhookService.ListDeliveries(ctx, model.DefaultNamespace, subscription.ID, 10, 0)
+	if err != nil || len(deliveries) != 3 {
+		t.Fatalf("Should record the deliveries: %+v, %v", deliveries, err)
+	}
+
+	failed := deliveries[0]
+	if failed.Event.Type != model.WebhookEventJobDeleted || failed.Status != model.WebhookDeliveryPending || failed.Attempts != 1 {
+		t.Fatalf("Should keep the failed delivery pending: %+v", failed)
+	}
+
+	if failed.LastStatusCode.Int64 != http.StatusInternalServerError || !failed.NextAttemptAt.Valid || failed.NextAttemptAt.Time.Before(time.Now().Add(20*time.Second)) {
+		t.Fatalf("Should record the failed attempt and back off: %+v", failed)
+	}
+
+	// the delivery is not attempted again before its backoff expired
+	webhookService.poll(ctx)
+	if hook.received() != 3 {
+		t.Fatalf("Should not retry the delivery before the backoff expired: %d", hook.received())
+	}
+
+	// Deleting the subscription deletes its deliveries
+	// -------------------------------------------------------------------------
+
+	if err := webhookService.DeleteSubscription(ctx, model.DefaultNamespace, subscription.ID); err != nil {
+		t.Fatalf("Should be able to delete the subscription: %s", err)
+	}
+
+	if _, err := webhookService.ListDeliveries(ctx, model.DefaultNamespace, subscription.ID, 10, 0); !errors.Is(err, errs.ErrWebhookSubscriptionNotFound) {
+		t.Fatalf("Should not find the deleted subscription: %v", err)
+	}
+}
+
+func Test_AutoStop(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := postgres.New(test.DB, test.Log)
+	jobService := job.NewService(store, test.Log)
+	webhookService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hook := &receiver{statusCode: http.StatusOK}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	_, err := webhookService.CreateSubscription(ctx, model.DefaultNamespace, &model.WebhookSubscriptionCreate{
+		URL:    server.URL,
+		Events: []model.WebhookEventType{model.WebhookEventJobAutoStopped},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a subscription: %s", err)
+	}
+
+	created, _, err := jobService.CreateJob(ctx, model.DefaultNamespace, &model.JobCreate{
+		Type:              model.JobTypeHTTP,
+		CronSchedule:      null.StringFrom("* * * * *"),
+		HTTPJob:           &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		AllowedFailedRuns: lo.ToPtr(2),
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// A success resets the failures counted towards the limit
+	now := time.Now()
+	for i, status := range []model.JobExecutionStatus{model.JobExecutionStatusFailed, model.JobExecutionStatusSuccessful, model.JobExecutionStatusFailed} {
+		start := now.Add(time.Duration(i) * time.Second)
+		if err := store.CreateJobExecution(ctx, created.ID, created.Version, "instance1", null.Time{}, start, start, status, null.String{}); err != nil {
+			t.Fatalf("Should be able to record an execution: %s", err)
+		}
+	}
+
+	running, err := jobService.GetJob(ctx, model.DefaultNamespace, created.ID)
+	if err != nil || running.Status != model.JobStatusRunning || running.Version != created.Version {
+		t.Fatalf("Should keep running the job until it failed twice in a row: %+v, %v", running, err)
+	}
+
+	start := now.Add(3 * time.Second)
+	if err := store.CreateJobExecution(ctx, created.ID, created.Version, "instance1", null.Time{}, start, start, model.JobExecutionStatusFailed, null.String{}); err != nil {
+		t.Fatalf("Should be able to record an execution: %s", err)
+	}
+
+	stopped, err := jobService.GetJob(ctx, model.DefaultNamespace, created.ID)
+	if err != nil || stopped.Status != model.JobStatusStopped || stopped.Version != created.Version+1 {
+		t.Fatalf("Should stop the job after it failed twice in a row: %+v, %v", stopped, err)
+	}
+
+	entries, err := jobService.GetJobAudit(ctx, model.DefaultNamespace, created.ID, 10, 0)
+	if err != nil || len(entries) != 2 || entries[0].Action != model.AuditActionPause || entries[0].Actor != audit.SystemActor {
+		t.Fatalf("Should audit the stop as a pause by the system: %+v, %v", entries, err)
+	}
+
+	webhookService.poll(ctx)
+
+	if hook.received() != 1 || hook.requests[0].Header.Get(HeaderEvent) != string(model.WebhookEventJobAutoStopped) {
+		t.Fatalf("Should deliver the auto-stopped event: %d", hook.received())
+	}
+
+	var event model.WebhookEvent
+	if err := json.Unmarshal(hook.bodies[0], &event); err != nil {
+		t.Fatalf("Should deliver the event as JSON: %s", err)
+	}
+
+	var data model.WebhookJobData
+	if err := json.Unmarshal(event.Data, &data); err != nil || data.ID != created.ID || data.Status != model.JobStatusStopped {
+		t.Fatalf("Should deliver the stopped job: %+v, %v", data, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 5, want: 8 * time.Minute},
+		{attempts: 20, want: 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+func (s *pgStore) CreateAPIKey(ctx context.Context, apiKey *model.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, namespace, name, prefix, key_hash, role, created_at)
+		VALUES (:id, :namespace, :name, :prefix, :key_hash, :role, :created_at)
+	`
+
+	_, err := s.db.NamedExecContext(ctx, query, toAPIKeyDB(apiKey))
+	if err != nil {
+		return fmt.Errorf("failed to insert API key into database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	var dbAPIKey apiKeyDB
+
+	err := s.db.GetContext(ctx, &dbAPIKey, `SELECT * FROM api_keys WHERE prefix = $1`, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key from database: %w", err)
+	}
+
+	return dbAPIKey.ToAPIKey(), nil
+}
+
+func (s *pgStore) ListAPIKeys(ctx context.Context, namespace string, limit, offset uint64) ([]model.APIKey, error) {
+	var dbAPIKeys []apiKeyDB
+
+	query := `SELECT * FROM api_keys WHERE namespace = $3 ORDER BY created_at LIMIT $1 OFFSET $2`
+	err := s.db.SelectContext(ctx, &dbAPIKeys, query, limit, offset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys from database: %w", err)
+	}
+
+	apiKeys := []model.APIKey{}
+	for _, dbAPIKey := range dbAPIKeys {
+		apiKeys = append(apiKeys, *dbAPIKey.ToAPIKey())
+	}
+
+	return apiKeys, nil
+}
+
+func (s *pgStore) RevokeAPIKey(ctx context.Context, namespace string, id uuid.UUID, revokedAt time.Time) error {
+	// Keep the original revocation time if the key was already revoked
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1 AND namespace = $3`
+	result, err := s.db.ExecContext(ctx, query, id, revokedAt, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return errs.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (s *pgStore) UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, lastUsedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update API key last used time: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+)
+
+// createJobAudit appends the entry to the audit log in the transaction of the change it records.
+func createJobAudit(ctx context.Context, tx *sqlx.Tx, entry *model.JobAudit) error {
+	dbEntry, err := toJobAuditDB(entry)
+	if err != nil {
+		return fmt.Errorf("failed to convert audit entry to db audit entry: %w", err)
+	}
+
+	query := `
+		INSERT INTO job_audit (job_id, namespace, action, actor, request_id, changes, created_at)
+		VALUES (:job_id, :namespace, :action, :actor, :request_id, :changes, :created_at)
+		RETURNING id
+	`
+
+	query, args, err := tx.BindNamed(query, dbEntry)
+	if err != nil {
+		return fmt.Errorf("failed to bind audit entry: %w", err)
+	}
+
+	if err := tx.GetContext(ctx, &entry.ID, query, args...); err != nil {
+		return fmt.Errorf("failed to insert audit entry into database: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobAudit returns the audit log entries of the namespace matching the filter, newest first.
+func (s *pgStore) ListJobAudit(ctx context.Context, namespace string, filter model.AuditFilter, limit, offset uint64) ([]model.JobAudit, error) {
+	conditions := []string{"namespace = $1"}
+	args := []interface{}{namespace}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.JobID.Valid {
+		addCondition("job_id = $%d", filter.JobID.UUID)
+	}
+
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+
+	if filter.Actor != "" {
+		addCondition("actor = $%d", filter.Actor)
+	}
+
+	if filter.From.Valid {
+		addCondition("created_at >= $%d", filter.From.Time)
+	}
+
+	if filter.To.Valid {
+		addCondition("created_at < $%d", filter.To.Time)
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT * FROM job_audit WHERE %s ORDER BY id DESC LIMIT $%d OFFSET $%d
+	`, strings.Join(conditions, " AND "), len(args)-1, len(args))
+
+	var dbEntries []jobAuditDB
+	if err := s.db.SelectContext(ctx, &dbEntries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get audit entries from database: %w", err)
+	}
+
+	entries := []model.JobAudit{}
+	for _, dbEntry := range dbEntries {
+		entry, err := dbEntry.ToJobAudit()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db audit entry to audit entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+)
+
+// CreateJobs creates the jobs in a single transaction: either all jobs are created, or none of them.
+func (s *pgStore) CreateJobs(ctx context.Context, writes []model.JobWrite) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	counts := map[string]int{}
+	for _, write := range writes {
+		counts[write.Job.Namespace]++
+	}
+
+	for namespace, count := range counts {
+		if err := checkJobQuota(ctx, tx, namespace, count); err != nil {
+			return err
+		}
+	}
+
+	for _, write := range writes {
+		if err := s.insertJob(ctx, tx, write.Job, write.Audit); err != nil {
+			return fmt.Errorf("job %s: %w", write.Job.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateJobs updates the jobs in a single transaction: either all jobs are updated, or none of them.
+func (s *pgStore) UpdateJobs(ctx context.Context, writes []model.JobWrite) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	for _, write := range writes {
+		if err := s.updateJob(ctx, tx, write.Job, write.Audit); err != nil {
+			return fmt.Errorf("job %s: %w", write.Job.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteJobs deletes the jobs in a single transaction: either all jobs are deleted, or none of them. Jobs that no
+// longer exist are skipped.
+func (s *pgStore) DeleteJobs(ctx context.Context, writes []model.JobWrite) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	for _, write := range writes {
+		if err := s.deleteJob(ctx, tx, write.Job, write.Audit); err != nil {
+			return fmt.Errorf("job %s: %w", write.Job.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetJobsByID returns the jobs with the given IDs from the namespace. Jobs that don't exist are omitted.
+func (s *pgStore) GetJobsByID(ctx context.Context, namespace string, ids []uuid.UUID) ([]model.Job, error) {
+	query := `
+		SELECT * FROM jobs WHERE namespace = $1 AND id = ANY($2::uuid[])
+	`
+
+	idStrings := lo.Map(ids, func(id uuid.UUID, _ int) string { return id.String() })
+
+	var dbJobs []jobDB
+	if err := s.db.SelectContext(ctx, &dbJobs, query, namespace, idStrings); err != nil {
+		return nil, fmt.Errorf("failed to get jobs from database: %w", err)
+	}
+
+	jobs := make([]model.Job, 0, len(dbJobs))
+	for _, dbJob := range dbJobs {
+		job, err := dbJob.ToJob(s.encryptor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// canaryPlaintext is encrypted into the canary record. Any instance configured with different keys can't decrypt it.
+const canaryPlaintext = "distributed-scheduler-encryption-canary"
+
+var ErrEncryptionKeyMismatch = errors.New("the configured encryption keys can't decrypt the canary record written by another instance: make sure the manager and all runners use the same encryption keys")
+
+// VerifyEncryptionCanary checks that the configured encryptor can decrypt the canary record. The first
+// instance to start writes the canary, so an instance configured with different keys fails at startup
+// instead of failing to decrypt job secrets later.
+func VerifyEncryptionCanary(ctx context.Context, db *sqlx.DB) error {
+	ciphertext, err := defaultEncryptor.Encrypt(canaryPlaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt the canary record: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO encryption_canary (id, ciphertext) VALUES (1, $1) ON CONFLICT (id) DO NOTHING`, *ciphertext)
+	if err != nil {
+		return fmt.Errorf("failed to write the canary record (were the database migrations run?): %w", err)
+	}
+
+	var stored string
+	err = db.GetContext(ctx, &stored, `SELECT ciphertext FROM encryption_canary WHERE id = 1`)
+	if err != nil {
+		return fmt.Errorf("failed to read the canary record: %w", err)
+	}
+
+	plaintext, err := defaultEncryptor.Decrypt(stored)
+	if err != nil || *plaintext != canaryPlaintext {
+		return ErrEncryptionKeyMismatch
+	}
+
+	return nil
+}
+
+// rotateCanary re-encrypts the canary record with the active key.
+func (r *KeyRotator) rotateCanary(ctx context.Context) error {
+	var stored string
+	err := r.db.GetContext(ctx, &stored, `SELECT ciphertext FROM encryption_canary WHERE id = 1`)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to read the canary record: %w", err)
+	}
+
+	if _, err := r.encryptor.Decrypt(stored); err != nil {
+		return fmt.Errorf("failed to decrypt the canary record: %w", ErrEncryptionKeyMismatch)
+	}
+
+	if !r.needsRotation([]string{stored}) {
+		return nil
+	}
+
+	ciphertext, err := r.encryptor.Encrypt(canaryPlaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt the canary record: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `UPDATE encryption_canary SET ciphertext = $1, updated_at = NOW() WHERE id = 1`, *ciphertext)
+	if err != nil {
+		return fmt.Errorf("failed to update the canary record: %w", err)
+	}
+
+	return nil
+}
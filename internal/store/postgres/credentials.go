@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+// uniqueViolation is the Postgres error code for unique constraint violations.
+const uniqueViolation = "23505"
+
+func (s *pgStore) CreateCredential(ctx context.Context, credential *model.Credential) error {
+	dbCredential, err := toCredentialDB(s.encryptor, credential)
+	if err != nil {
+		return fmt.Errorf("failed to convert credential to db credential: %w", err)
+	}
+
+	query := `
+		INSERT INTO credentials (namespace, name, type, data, created_at, updated_at)
+		VALUES (:namespace, :name, :type, :data, :created_at, :updated_at)
+	`
+
+	_, err = s.db.NamedExecContext(ctx, query, dbCredential)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return errs.ErrCredentialAlreadyExists
+		}
+		return fmt.Errorf("failed to insert credential into database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) GetCredential(ctx context.Context, namespace, name string) (*model.Credential, error) {
+	var dbCredential credentialDB
+
+	query := `
+		SELECT * FROM credentials WHERE namespace = $1 AND name = $2
+	`
+	err := s.db.GetContext(ctx, &dbCredential, query, namespace, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrCredentialNotFound
+		}
+		return nil, fmt.Errorf("failed to get credential from database: %w", err)
+	}
+
+	credential, err := dbCredential.ToCredential(s.encryptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db credential to credential: %w", err)
+	}
+
+	return credential, nil
+}
+
+func (s *pgStore) ListCredentials(ctx context.Context, namespace string, limit, offset uint64) ([]model.Credential, error) {
+	query := `
+		SELECT * FROM credentials WHERE namespace = $3 ORDER BY name LIMIT $1 OFFSET $2
+	`
+
+	var dbCredentials []credentialDB
+	err := s.db.SelectContext(ctx, &dbCredentials, query, limit, offset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials from database: %w", err)
+	}
+
+	credentials := []model.Credential{}
+	for _, dbCredential := range dbCredentials {
+		credential, err := dbCredential.ToCredential(s.encryptor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db credential to credential: %w", err)
+		}
+		credentials = append(credentials, *credential)
+	}
+
+	return credentials, nil
+}
+
+func (s *pgStore) UpdateCredential(ctx context.Context, credential *model.Credential) error {
+	dbCredential, err := toCredentialDB(s.encryptor, credential)
+	if err != nil {
+		return fmt.Errorf("failed to convert credential to db credential: %w", err)
+	}
+
+	query := `
+		UPDATE credentials
+		SET type = :type, data = :data, updated_at = :updated_at
+		WHERE namespace = :namespace AND name = :name
+	`
+
+	result, err := s.db.NamedExecContext(ctx, query, dbCredential)
+	if err != nil {
+		return fmt.Errorf("failed to update credential in database: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return errs.ErrCredentialNotFound
+	}
+
+	return nil
+}
+
+func (s *pgStore) DeleteCredential(ctx context.Context, namespace, name string) error {
+	// Refuse to delete credentials that jobs of the namespace still depend on
+	query := `
+		DELETE FROM credentials
+		WHERE namespace = $1 AND name = $2 AND NOT EXISTS (
+			SELECT 1 FROM jobs
+			WHERE namespace = $1 AND (http_job->'auth'->>'credential' = $2 OR amqp_job->>'connection_credential' = $2)
+		)
+	`
+
+	result, err := s.db.ExecContext(ctx, query, namespace, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete credential from database: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete credential from database: %w", err)
+	}
+
+	if rows == 0 {
+		// Either the credential doesn't exist or it is in use
+		if _, err := s.GetCredential(ctx, namespace, name); err != nil {
+			return err
+		}
+		return errs.ErrCredentialInUse
+	}
+
+	return nil
+}
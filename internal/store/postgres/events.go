@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store"
+	"gopkg.in/guregu/null.v4"
+)
+
+type executionEventDB struct {
+	ID          int64       `db:"id"`
+	Namespace   string      `db:"namespace"`
+	JobID       uuid.UUID   `db:"job_id"`
+	Type        string      `db:"type"`
+	InstanceID  null.String `db:"instance_id"`
+	ScheduledAt null.Time   `db:"scheduled_at"`
+	ExecutionID null.Int    `db:"execution_id"`
+	CreatedAt   time.Time   `db:"created_at"`
+
+	// Columns of the execution of finished events
+	Execution struct {
+		ID           null.Int    `db:"id"`
+		JobVersion   null.Int    `db:"job_version"`
+		Status       null.String `db:"status"`
+		StartTime    null.Time   `db:"start_time"`
+		EndTime      null.Time   `db:"end_time"`
+		ErrorMessage null.String `db:"error_message"`
+	} `db:"execution"`
+}
+
+func (e *executionEventDB) ToModel() model.ExecutionEvent {
+	event := model.ExecutionEvent{
+		ID:          e.ID,
+		Type:        model.ExecutionEventType(e.Type),
+		Namespace:   e.Namespace,
+		JobID:       e.JobID,
+		InstanceID:  e.InstanceID,
+		ScheduledAt: e.ScheduledAt,
+		CreatedAt:   e.CreatedAt,
+	}
+
+	if e.Execution.ID.Valid {
+		event.Execution = &model.JobExecution{
+			ID:           int(e.Execution.ID.Int64),
+			JobID:        e.JobID,
+			JobVersion:   e.Execution.JobVersion,
+			ScheduledAt:  e.ScheduledAt,
+			StartTime:    e.Execution.StartTime.Time,
+			EndTime:      e.Execution.EndTime.Time,
+			Success:      e.Execution.Status.String == string(model.JobExecutionStatusSuccessful),
+			ErrorMessage: e.Execution.ErrorMessage,
+			InstanceID:   e.InstanceID,
+		}
+	}
+
+	return event
+}
+
+// executionEventsQuery selects events with the execution of finished events.
+const executionEventsQuery = `
+	SELECT
+		ev.id, ev.namespace, ev.job_id, ev.type, ev.instance_id, ev.scheduled_at, ev.execution_id, ev.created_at,
+		ex.id AS "execution.id",
+		ex.job_version AS "execution.job_version",
+		ex.status AS "execution.status",
+		ex.start_time AS "execution.start_time",
+		ex.end_time AS "execution.end_time",
+		ex.error_message AS "execution.error_message"
+	FROM execution_events ev
+	LEFT JOIN job_executions ex ON ex.id = ev.execution_id
+`
+
+// createExecutionEvent stores the event in the transaction of the change it announces. Listeners of the execution
+// events channel are notified with the ID of the event when the transaction commits.
+func createExecutionEvent(ctx context.Context, tx *sqlx.Tx, event *model.ExecutionEvent, executionID null.Int) error {
+	query := `
+		WITH event AS (
+			INSERT INTO execution_events (namespace, job_id, type, instance_id, scheduled_at, execution_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, now())
+			RETURNING id
+		)
+		SELECT pg_notify($7, id::text) FROM event
+	`
+
+	_, err := tx.ExecContext(ctx, query, event.Namespace, event.JobID, event.Type, event.InstanceID, event.ScheduledAt, executionID, store.ExecutionEventsChannel)
+	if err != nil {
+		return fmt.Errorf("failed to insert execution event into database: %w", err)
+	}
+
+	return nil
+}
+
+// GetExecutionEvent returns the event with the given ID, of any namespace, or nil if it no longer exists.
+func (s *pgStore) GetExecutionEvent(ctx context.Context, id int64) (*model.ExecutionEvent, error) {
+	var dbEvent executionEventDB
+	err := s.db.GetContext(ctx, &dbEvent, executionEventsQuery+` WHERE ev.id = $1`, id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get execution event from database: %w", err)
+	}
+
+	event := dbEvent.ToModel()
+	return &event, nil
+}
+
+// lateEventWindow is how many events before the event with the given ID are checked for having been committed after it.
+const lateEventWindow = 1000
+
+// ListExecutionEvents returns the events of the namespace, or of a job of the namespace, emitted after the event with
+// the given ID, oldest first. Events are numbered when they are emitted, but streamed when their transaction commits,
+// so the events emitted shortly before the event whose transaction hadn't committed yet are returned as well.
+func (s *pgStore) ListExecutionEvents(ctx context.Context, namespace string, jobID uuid.NullUUID, afterID int64, limit uint64) ([]model.ExecutionEvent, error) {
+	query := executionEventsQuery + `
+		WHERE ev.namespace = $1 AND ($3::uuid IS NULL OR ev.job_id = $3) AND (
+			ev.id > $2
+			OR ev.id > $2 - $5 AND ev.id < $2 AND EXISTS (
+				SELECT 1 FROM execution_events prev
+				WHERE prev.id = $2 AND ev.xid <> prev.xid AND NOT pg_visible_in_snapshot(ev.xid, prev.snapshot)
+			)
+		)
+		ORDER BY ev.id
+		LIMIT $4
+	`
+
+	var dbEvents []executionEventDB
+	if err := s.db.SelectContext(ctx, &dbEvents, query, namespace, afterID, jobID, limit, lateEventWindow); err != nil {
+		return nil, fmt.Errorf("failed to get execution events from database: %w", err)
+	}
+
+	events := make([]model.ExecutionEvent, 0, len(dbEvents))
+	for _, dbEvent := range dbEvents {
+		events = append(events, dbEvent.ToModel())
+	}
+
+	return events, nil
+}
+
+// DeleteExecutionEvents deletes the events emitted before the given time.
+func (s *pgStore) DeleteExecutionEvents(ctx context.Context, before time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM execution_events WHERE created_at < $1`, before); err != nil {
+		return fmt.Errorf("failed to delete execution events from database: %w", err)
+	}
+
+	return nil
+}
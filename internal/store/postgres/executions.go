@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+)
+
+// SearchJobExecutions returns a page of the executions of all jobs of the namespace matching the query, the most
+// recent first, with the total number of matching executions. Pages following a cursor start after the execution the
+// cursor points to (keyset pagination).
+func (s *pgStore) SearchJobExecutions(ctx context.Context, namespace string, query model.ExecutionQuery) (*model.ExecutionPage, error) {
+	conditions := []string{"e.namespace = $1"}
+	args := []interface{}{namespace}
+
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if query.Status != "" {
+		conditions = append(conditions, "e.status = "+arg(query.Status))
+	}
+
+	if query.From.Valid {
+		conditions = append(conditions, "e.start_time >= "+arg(query.From.Time))
+	}
+
+	if query.To.Valid {
+		conditions = append(conditions, "e.start_time < "+arg(query.To.Time))
+	}
+
+	if query.InstanceID != "" {
+		conditions = append(conditions, "e.instance_id = "+arg(query.InstanceID))
+	}
+
+	if query.Error != "" {
+		conditions = append(conditions, "e.error_message ILIKE '%' || "+arg(likeEscaper.Replace(query.Error))+" || '%'")
+	}
+
+	// The jobs are only joined when filtering by their type or tags
+	from := "job_executions e"
+	if query.JobType != "" || len(query.Tags) > 0 {
+		from = "job_executions e JOIN jobs j ON j.id = e.job_id"
+
+		if query.JobType != "" {
+			conditions = append(conditions, "j.type = "+arg(query.JobType))
+		}
+
+		if len(query.Tags) > 0 {
+			if query.TagMatch == model.TagMatchAny {
+				conditions = append(conditions, "j.tags && "+arg(query.Tags))
+			} else {
+				conditions = append(conditions, "j.tags @> "+arg(query.Tags))
+			}
+		}
+	}
+
+	page := &model.ExecutionPage{Executions: []model.JobExecution{}}
+
+	countQuery := fmt.Sprintf(`SELECT count(*) FROM %s WHERE %s`, from, strings.Join(conditions, " AND "))
+	if err := s.db.GetContext(ctx, &page.Total, countQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to count job executions: %w", err)
+	}
+
+	if query.Cursor != "" {
+		cursor, err := model.ParseExecutionCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(e.start_time, e.id) < (%s, %s)", arg(cursor.StartTime), arg(cursor.ID)))
+	}
+
+	searchQuery := fmt.Sprintf(`
+		SELECT e.* FROM %s WHERE %s ORDER BY e.start_time DESC, e.id DESC LIMIT %s OFFSET %s
+	`, from, strings.Join(conditions, " AND "), arg(query.Limit), arg(query.Offset))
+
+	var dbExecutions []executionDB
+	if err := s.db.SelectContext(ctx, &dbExecutions, searchQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to get job executions from database: %w", err)
+	}
+
+	for _, dbExecution := range dbExecutions {
+		page.Executions = append(page.Executions, *dbExecution.ToModel())
+	}
+
+	if query.Limit > 0 && uint64(len(page.Executions)) == query.Limit {
+		page.NextCursor = model.NewExecutionCursor(&page.Executions[len(page.Executions)-1])
+	}
+
+	return page, nil
+}
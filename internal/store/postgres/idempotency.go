@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+// ReserveIdempotencyKey stores the key for a request in progress, unless the namespace already has an unexpired key
+// with the same value. If it does, the existing key is returned instead.
+func (s *pgStore) ReserveIdempotencyKey(ctx context.Context, key *model.IdempotencyKey) (bool, *model.IdempotencyKey, error) {
+	// an expired key is replaced, as if it didn't exist
+	query := `
+		INSERT INTO idempotency_keys (namespace, key, request_hash, created_at, expires_at)
+		VALUES (:namespace, :key, :request_hash, :created_at, :expires_at)
+		ON CONFLICT (namespace, key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			etag = NULL,
+			response = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	`
+
+	result, err := s.db.NamedExecContext(ctx, query, toIdempotencyKeyDB(key))
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to insert idempotency key into database: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to insert idempotency key into database: %w", err)
+	}
+
+	if rows > 0 {
+		return true, nil, nil
+	}
+
+	var existing idempotencyKeyDB
+	err = s.db.GetContext(ctx, &existing, `SELECT * FROM idempotency_keys WHERE namespace = $1 AND key = $2`, key.Namespace, key.Key)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// the key was released after the insert, the request can be retried
+		return false, nil, errs.ErrIdempotencyKeyInProgress
+	case err != nil:
+		return false, nil, fmt.Errorf("failed to get idempotency key from database: %w", err)
+	}
+
+	return false, existing.ToIdempotencyKey(), nil
+}
+
+// CompleteIdempotencyKey stores the response of the request with the key.
+func (s *pgStore) CompleteIdempotencyKey(ctx context.Context, key *model.IdempotencyKey) error {
+	query := `
+		UPDATE idempotency_keys SET status_code = :status_code, etag = :etag, response = :response, expires_at = :expires_at
+		WHERE namespace = :namespace AND key = :key AND request_hash = :request_hash
+	`
+
+	if _, err := s.db.NamedExecContext(ctx, query, toIdempotencyKeyDB(key)); err != nil {
+		return fmt.Errorf("failed to update idempotency key in database: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey deletes the key, so the request can be retried with it.
+func (s *pgStore) DeleteIdempotencyKey(ctx context.Context, namespace, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE namespace = $1 AND key = $2`, namespace, key); err != nil {
+		return fmt.Errorf("failed to delete idempotency key from database: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes the keys that expired before the given time.
+func (s *pgStore) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, before); err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys from database: %w", err)
+	}
+
+	return nil
+}
@@ -12,16 +12,21 @@ import (
 	"gopkg.in/guregu/null.v4"
 )
 
-var encryptor security.Encryptor
+// defaultEncryptor encrypts the secrets of stores created without WithEncryptor.
+var defaultEncryptor security.Encryptor
 
+// SetEncryptor sets the encryptor of the stores created from now on without WithEncryptor.
 func SetEncryptor(e security.Encryptor) {
-	encryptor = e
+	defaultEncryptor = e
 }
 
 type jobDB struct {
 	ID           uuid.UUID      `db:"id"`
+	Namespace    string         `db:"namespace"`
 	Type         string         `db:"type"`
 	Status       string         `db:"status"`
+	Version      int64          `db:"version"`
+	UniqueKey    null.String    `db:"unique_key"`
 	ExecuteAt    null.Time      `db:"execute_at"`
 	CronSchedule null.String    `db:"cron_schedule"`
 	HTTPJob      []byte         `db:"http_job"`
@@ -32,19 +37,26 @@ type jobDB struct {
 	LockedUntil  null.Time      `db:"locked_until"`
 	LockedBy     null.String    `db:"locked_by"`
 	Tags         pq.StringArray `db:"tags"`
+
+	AllowedFailedRuns *int `db:"allowed_failed_runs"`
 }
 
-func toJobDB(j *model.Job) (*jobDB, error) {
+func toJobDB(encryptor security.Encryptor, j *model.Job) (*jobDB, error) {
 	dbJ := &jobDB{
 		ID:           j.ID,
+		Namespace:    j.Namespace,
 		Type:         string(j.Type),
 		Status:       string(j.Status),
+		Version:      j.Version,
+		UniqueKey:    j.UniqueKey,
 		ExecuteAt:    j.ExecuteAt,
 		CronSchedule: j.CronSchedule,
 		CreatedAt:    j.CreatedAt,
 		UpdatedAt:    j.UpdatedAt,
 		NextRun:      j.NextRun,
 		Tags:         j.Tags,
+
+		AllowedFailedRuns: j.AllowedFailedRuns,
 	}
 
 	if j.HTTPJob != nil {
@@ -70,6 +82,37 @@ func toJobDB(j *model.Job) (*jobDB, error) {
 			}
 
 			j.HTTPJob.Auth.BearerToken = null.StringFrom(*encryptedToken)
+		case model.AuthTypeHMAC:
+			encryptedSecret, err := encryptor.Encrypt(j.HTTPJob.Auth.HMACSecret.ValueOrZero())
+			if err != nil {
+				return nil, err
+			}
+
+			j.HTTPJob.Auth.HMACSecret = null.StringFrom(*encryptedSecret)
+		case model.AuthTypeOAuth2ClientCredentials:
+			if j.HTTPJob.Auth.OAuth2 != nil {
+				encryptedSecret, err := encryptor.Encrypt(j.HTTPJob.Auth.OAuth2.ClientSecret.ValueOrZero())
+				if err != nil {
+					return nil, err
+				}
+
+				j.HTTPJob.Auth.OAuth2.ClientSecret = null.StringFrom(*encryptedSecret)
+			}
+		}
+
+		// Encrypt the client certificate and key, if the job uses mutual TLS
+		if j.HTTPJob.TLS != nil && j.HTTPJob.TLS.ClientKey.Valid {
+			encryptedCert, err := encryptor.Encrypt(j.HTTPJob.TLS.ClientCert.ValueOrZero())
+			if err != nil {
+				return nil, err
+			}
+			j.HTTPJob.TLS.ClientCert = null.StringFrom(*encryptedCert)
+
+			encryptedKey, err := encryptor.Encrypt(j.HTTPJob.TLS.ClientKey.ValueOrZero())
+			if err != nil {
+				return nil, err
+			}
+			j.HTTPJob.TLS.ClientKey = null.StringFrom(*encryptedKey)
 		}
 
 		httpJob, err := json.Marshal(j.HTTPJob)
@@ -101,17 +144,22 @@ func toJobDB(j *model.Job) (*jobDB, error) {
 	return dbJ, nil
 }
 
-func (j *jobDB) ToJob() (*model.Job, error) {
+func (j *jobDB) ToJob(encryptor security.Encryptor) (*model.Job, error) {
 	job := &model.Job{
 		ID:           j.ID,
+		Namespace:    j.Namespace,
 		Type:         model.JobType(j.Type),
 		Status:       model.JobStatus(j.Status),
+		Version:      j.Version,
+		UniqueKey:    j.UniqueKey,
 		ExecuteAt:    j.ExecuteAt,
 		CronSchedule: j.CronSchedule,
 		CreatedAt:    j.CreatedAt,
 		UpdatedAt:    j.UpdatedAt,
 		NextRun:      j.NextRun,
 		Tags:         j.Tags,
+
+		AllowedFailedRuns: j.AllowedFailedRuns,
 	}
 
 	if err := unmarshalNullableJSON(j.HTTPJob, &job.HTTPJob); err != nil {
@@ -140,6 +188,36 @@ func (j *jobDB) ToJob() (*model.Job, error) {
 			}
 
 			job.HTTPJob.Auth.BearerToken = null.StringFrom(*decryptedToken)
+		case model.AuthTypeHMAC:
+			decryptedSecret, err := encryptor.Decrypt(job.HTTPJob.Auth.HMACSecret.ValueOrZero())
+			if err != nil {
+				return nil, err
+			}
+
+			job.HTTPJob.Auth.HMACSecret = null.StringFrom(*decryptedSecret)
+		case model.AuthTypeOAuth2ClientCredentials:
+			if job.HTTPJob.Auth.OAuth2 != nil {
+				decryptedSecret, err := encryptor.Decrypt(job.HTTPJob.Auth.OAuth2.ClientSecret.ValueOrZero())
+				if err != nil {
+					return nil, err
+				}
+
+				job.HTTPJob.Auth.OAuth2.ClientSecret = null.StringFrom(*decryptedSecret)
+			}
+		}
+
+		if job.HTTPJob.TLS != nil && job.HTTPJob.TLS.ClientKey.Valid {
+			decryptedCert, err := encryptor.Decrypt(job.HTTPJob.TLS.ClientCert.ValueOrZero())
+			if err != nil {
+				return nil, err
+			}
+			job.HTTPJob.TLS.ClientCert = null.StringFrom(*decryptedCert)
+
+			decryptedKey, err := encryptor.Decrypt(job.HTTPJob.TLS.ClientKey.ValueOrZero())
+			if err != nil {
+				return nil, err
+			}
+			job.HTTPJob.TLS.ClientKey = null.StringFrom(*decryptedKey)
 		}
 	}
 
@@ -169,10 +247,14 @@ func unmarshalNullableJSON(data []byte, v interface{}) error {
 type executionDB struct {
 	ID           int         `db:"id"`
 	JobID        uuid.UUID   `db:"job_id"`
+	JobVersion   null.Int    `db:"job_version"`
+	Namespace    string      `db:"namespace"`
+	ScheduledAt  null.Time   `db:"scheduled_at"`
 	Status       string      `db:"status"`
 	StartTime    time.Time   `db:"start_time"`
 	EndTime      time.Time   `db:"end_time"`
 	ErrorMessage null.String `db:"error_message"`
+	InstanceID   null.String `db:"instance_id"`
 	CreatedAt    time.Time   `db:"created_at"`
 }
 
@@ -180,9 +262,355 @@ func (e *executionDB) ToModel() *model.JobExecution {
 	return &model.JobExecution{
 		ID:           e.ID,
 		JobID:        e.JobID,
+		JobVersion:   e.JobVersion,
 		Success:      e.Status == string(model.JobExecutionStatusSuccessful),
+		ScheduledAt:  e.ScheduledAt,
 		StartTime:    e.StartTime,
 		EndTime:      e.EndTime,
 		ErrorMessage: e.ErrorMessage,
+		InstanceID:   e.InstanceID,
+	}
+}
+
+type jobVersionDB struct {
+	JobID        uuid.UUID      `db:"job_id"`
+	Version      int64          `db:"version"`
+	Type         string         `db:"type"`
+	ExecuteAt    null.Time      `db:"execute_at"`
+	CronSchedule null.String    `db:"cron_schedule"`
+	HTTPJob      []byte         `db:"http_job"`
+	AMQPJob      []byte         `db:"amqp_job"`
+	Tags         pq.StringArray `db:"tags"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
+
+// toJobDB converts the version to a job, so its secrets can be decrypted and encrypted the same way.
+func (v *jobVersionDB) toJobDB() *jobDB {
+	return &jobDB{
+		ID:           v.JobID,
+		Type:         v.Type,
+		Version:      v.Version,
+		ExecuteAt:    v.ExecuteAt,
+		CronSchedule: v.CronSchedule,
+		HTTPJob:      v.HTTPJob,
+		AMQPJob:      v.AMQPJob,
+		Tags:         v.Tags,
+		CreatedAt:    v.CreatedAt,
+	}
+}
+
+func (v *jobVersionDB) ToJobVersion(encryptor security.Encryptor) (*model.JobVersion, error) {
+	job, err := v.toJobDB().ToJob(encryptor)
+	if err != nil {
+		return nil, err
 	}
+
+	return &model.JobVersion{
+		JobID:        job.ID,
+		Version:      job.Version,
+		Type:         job.Type,
+		ExecuteAt:    job.ExecuteAt,
+		CronSchedule: job.CronSchedule,
+		HTTPJob:      job.HTTPJob,
+		AMQPJob:      job.AMQPJob,
+		Tags:         job.Tags,
+		CreatedAt:    job.CreatedAt,
+	}, nil
+}
+
+type credentialDB struct {
+	Namespace string    `db:"namespace"`
+	Name      string    `db:"name"`
+	Type      string    `db:"type"`
+	Data      []byte    `db:"data"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// credentialSecrets holds the encrypted secrets of a credential, stored as JSON in the data column.
+type credentialSecrets struct {
+	Username    null.String `json:"username,omitempty"`
+	Password    null.String `json:"password,omitempty"`
+	BearerToken null.String `json:"bearer_token,omitempty"`
+	HMACSecret  null.String `json:"hmac_secret,omitempty"`
+	Connection  null.String `json:"connection,omitempty"`
+}
+
+func toCredentialDB(encryptor security.Encryptor, c *model.Credential) (*credentialDB, error) {
+	secrets := credentialSecrets{}
+
+	for _, field := range []struct {
+		from null.String
+		to   *null.String
+	}{
+		{c.Username, &secrets.Username},
+		{c.Password, &secrets.Password},
+		{c.BearerToken, &secrets.BearerToken},
+		{c.HMACSecret, &secrets.HMACSecret},
+		{c.Connection, &secrets.Connection},
+	} {
+		if !field.from.Valid {
+			continue
+		}
+
+		encrypted, err := encryptor.Encrypt(field.from.String)
+		if err != nil {
+			return nil, err
+		}
+
+		*field.to = null.StringFrom(*encrypted)
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal credential secrets")
+	}
+
+	return &credentialDB{
+		Namespace: c.Namespace,
+		Name:      c.Name,
+		Type:      string(c.Type),
+		Data:      data,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}, nil
+}
+
+func (c *credentialDB) ToCredential(encryptor security.Encryptor) (*model.Credential, error) {
+	secrets := credentialSecrets{}
+	if err := json.Unmarshal(c.Data, &secrets); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal credential secrets")
+	}
+
+	credential := &model.Credential{
+		Namespace: c.Namespace,
+		Name:      c.Name,
+		Type:      model.CredentialType(c.Type),
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+
+	for _, field := range []struct {
+		from null.String
+		to   *null.String
+	}{
+		{secrets.Username, &credential.Username},
+		{secrets.Password, &credential.Password},
+		{secrets.BearerToken, &credential.BearerToken},
+		{secrets.HMACSecret, &credential.HMACSecret},
+		{secrets.Connection, &credential.Connection},
+	} {
+		if !field.from.Valid {
+			continue
+		}
+
+		decrypted, err := encryptor.Decrypt(field.from.String)
+		if err != nil {
+			return nil, err
+		}
+
+		*field.to = null.StringFrom(*decrypted)
+	}
+
+	return credential, nil
+}
+
+type apiKeyDB struct {
+	ID         uuid.UUID `db:"id"`
+	Namespace  string    `db:"namespace"`
+	Name       string    `db:"name"`
+	Prefix     string    `db:"prefix"`
+	KeyHash    string    `db:"key_hash"`
+	Role       string    `db:"role"`
+	CreatedAt  time.Time `db:"created_at"`
+	LastUsedAt null.Time `db:"last_used_at"`
+	RevokedAt  null.Time `db:"revoked_at"`
+}
+
+func toAPIKeyDB(k *model.APIKey) *apiKeyDB {
+	return &apiKeyDB{
+		ID:         k.ID,
+		Namespace:  k.Namespace,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		KeyHash:    k.Hash,
+		Role:       string(k.Role),
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+func (k *apiKeyDB) ToAPIKey() *model.APIKey {
+	return &model.APIKey{
+		ID:         k.ID,
+		Namespace:  k.Namespace,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Hash:       k.KeyHash,
+		Role:       model.Role(k.Role),
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+type namespaceDB struct {
+	Name                   string    `db:"name"`
+	MaxJobs                null.Int  `db:"max_jobs"`
+	MaxExecutionsPerMinute null.Int  `db:"max_executions_per_minute"`
+	CreatedAt              time.Time `db:"created_at"`
+	UpdatedAt              time.Time `db:"updated_at"`
+}
+
+func (n *namespaceDB) ToNamespace() *model.Namespace {
+	return &model.Namespace{
+		Name: n.Name,
+		NamespaceQuota: model.NamespaceQuota{
+			MaxJobs:                n.MaxJobs,
+			MaxExecutionsPerMinute: n.MaxExecutionsPerMinute,
+		},
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+	}
+}
+
+type jobAuditDB struct {
+	ID        int64       `db:"id"`
+	JobID     uuid.UUID   `db:"job_id"`
+	Namespace string      `db:"namespace"`
+	Action    string      `db:"action"`
+	Actor     string      `db:"actor"`
+	RequestID null.String `db:"request_id"`
+	Changes   []byte      `db:"changes"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
+func toJobAuditDB(a *model.JobAudit) (*jobAuditDB, error) {
+	changes, err := json.Marshal(a.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jobAuditDB{
+		JobID:     a.JobID,
+		Namespace: a.Namespace,
+		Action:    string(a.Action),
+		Actor:     a.Actor,
+		RequestID: a.RequestID,
+		Changes:   changes,
+		CreatedAt: a.CreatedAt,
+	}, nil
+}
+
+func (a *jobAuditDB) ToJobAudit() (*model.JobAudit, error) {
+	audit := &model.JobAudit{
+		ID:        a.ID,
+		JobID:     a.JobID,
+		Namespace: a.Namespace,
+		Action:    model.AuditAction(a.Action),
+		Actor:     a.Actor,
+		RequestID: a.RequestID,
+		CreatedAt: a.CreatedAt,
+	}
+
+	if err := json.Unmarshal(a.Changes, &audit.Changes); err != nil {
+		return nil, err
+	}
+
+	return audit, nil
+}
+
+type idempotencyKeyDB struct {
+	Namespace   string      `db:"namespace"`
+	Key         string      `db:"key"`
+	RequestHash string      `db:"request_hash"`
+	StatusCode  null.Int    `db:"status_code"`
+	ETag        null.String `db:"etag"`
+	Response    []byte      `db:"response"`
+	CreatedAt   time.Time   `db:"created_at"`
+	ExpiresAt   time.Time   `db:"expires_at"`
+}
+
+func toIdempotencyKeyDB(k *model.IdempotencyKey) *idempotencyKeyDB {
+	return &idempotencyKeyDB{
+		Namespace:   k.Namespace,
+		Key:         k.Key,
+		RequestHash: k.RequestHash,
+		StatusCode:  k.StatusCode,
+		ETag:        k.ETag,
+		Response:    k.Response,
+		CreatedAt:   k.CreatedAt,
+		ExpiresAt:   k.ExpiresAt,
+	}
+}
+
+func (k *idempotencyKeyDB) ToIdempotencyKey() *model.IdempotencyKey {
+	return &model.IdempotencyKey{
+		Namespace:   k.Namespace,
+		Key:         k.Key,
+		RequestHash: k.RequestHash,
+		StatusCode:  k.StatusCode,
+		ETag:        k.ETag,
+		Response:    k.Response,
+		CreatedAt:   k.CreatedAt,
+		ExpiresAt:   k.ExpiresAt,
+	}
+}
+
+type webhookSubscriptionDB struct {
+	ID        uuid.UUID      `db:"id"`
+	Namespace string         `db:"namespace"`
+	URL       string         `db:"url"`
+	Events    pq.StringArray `db:"events"`
+	Enabled   bool           `db:"enabled"`
+	Secret    string         `db:"secret"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+func toWebhookSubscriptionDB(encryptor security.Encryptor, s *model.WebhookSubscription) (*webhookSubscriptionDB, error) {
+	encryptedSecret, err := encryptor.Encrypt(s.Secret.ValueOrZero())
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(pq.StringArray, 0, len(s.Events))
+	for _, event := range s.Events {
+		events = append(events, string(event))
+	}
+
+	return &webhookSubscriptionDB{
+		ID:        s.ID,
+		Namespace: s.Namespace,
+		URL:       s.URL,
+		Events:    events,
+		Enabled:   s.Enabled,
+		Secret:    *encryptedSecret,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}, nil
+}
+
+func (s *webhookSubscriptionDB) ToWebhookSubscription(encryptor security.Encryptor) (*model.WebhookSubscription, error) {
+	decryptedSecret, err := encryptor.Decrypt(s.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]model.WebhookEventType, 0, len(s.Events))
+	for _, event := range s.Events {
+		events = append(events, model.WebhookEventType(event))
+	}
+
+	return &model.WebhookSubscription{
+		ID:        s.ID,
+		Namespace: s.Namespace,
+		URL:       s.URL,
+		Events:    events,
+		Enabled:   s.Enabled,
+		Secret:    null.StringFrom(*decryptedSecret),
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}, nil
 }
@@ -14,8 +14,14 @@ import (
 	"gopkg.in/guregu/null.v4"
 )
 
+var testEncryptor security.Encryptor
+
 func init() {
-	SetEncryptor(security.NewEncryptor("testkey123456789"))
+	var err error
+	testEncryptor, err = security.NewEncryptor("testkey123456789")
+	if err != nil {
+		panic(err)
+	}
 }
 
 func TestJobDB_ToJob_HTTPJob(t *testing.T) {
@@ -30,7 +36,7 @@ func TestJobDB_ToJob_HTTPJob(t *testing.T) {
 		UpdatedAt:    time.Now(),
 	}
 
-	job, err := jobDB.ToJob()
+	job, err := jobDB.ToJob(testEncryptor)
 	require.NoError(t, err)
 
 	assert.Equal(t, job.ID, jobDB.ID)
@@ -48,7 +54,7 @@ func TestJobDB_ToJob_HTTPJob(t *testing.T) {
 func TestJobDB_ToJob_AMQPJob(t *testing.T) {
 
 	// AMQP connection must be encrypted
-	connection, err := encryptor.Encrypt("amqp://localhost:3000")
+	connection, err := testEncryptor.Encrypt("amqp://localhost:3000")
 	assert.NoError(t, err)
 	amqpJob := fmt.Sprintf(`{"connection": "%s", "exchange": "Test", "routing_key": "Test", "headers": {}, "body": "Text Plain", "body_encoding": null, "content_type": "text/plain"}`, *connection)
 
@@ -63,7 +69,7 @@ func TestJobDB_ToJob_AMQPJob(t *testing.T) {
 		UpdatedAt:    time.Now(),
 	}
 
-	job, err := jobDB.ToJob()
+	job, err := jobDB.ToJob(testEncryptor)
 	require.NoError(t, err)
 
 	assert.Equal(t, job.ID, jobDB.ID)
@@ -83,3 +89,67 @@ func TestJobDB_ToJob_AMQPJob(t *testing.T) {
 	// AMQP connection is decrypted
 	assert.JSONEq(t, `{"connection": "amqp://localhost:3000", "exchange": "Test", "routing_key": "Test", "headers": {}, "body": "Text Plain", "body_encoding": null, "content_type": "text/plain"}`, string(marshalledJob))
 }
+
+func TestCredentialDB_RoundTrip(t *testing.T) {
+	credential := &model.Credential{
+		Name:      "billing-api",
+		Type:      model.CredentialTypeBasic,
+		Username:  null.StringFrom("user"),
+		Password:  null.StringFrom("pass"),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	dbCredential, err := toCredentialDB(testEncryptor, credential)
+	require.NoError(t, err)
+
+	// Secrets must not be stored in plaintext
+	assert.NotContains(t, string(dbCredential.Data), `"user"`)
+	assert.NotContains(t, string(dbCredential.Data), `"pass"`)
+
+	decoded, err := dbCredential.ToCredential(testEncryptor)
+	require.NoError(t, err)
+	assert.Equal(t, credential, decoded)
+}
+
+func TestJobDB_ReEncrypt(t *testing.T) {
+	oldKeyring, err := security.NewKeyring(map[string]string{"v1": "testkey123456789"}, "v1")
+	require.NoError(t, err)
+
+	dbJob, err := toJobDB(oldKeyring, &model.Job{
+		ID:   uuid.New(),
+		Type: model.JobTypeHTTP,
+		HTTPJob: &model.HTTPJob{
+			URL:    "https://example.com",
+			Method: "GET",
+			Auth:   model.Auth{Type: model.AuthTypeBearer, BearerToken: null.StringFrom("token")},
+		},
+	})
+	require.NoError(t, err)
+
+	// Rotate to a new key, keeping the old one for decryption
+	keyring, err := security.NewKeyring(map[string]string{"v1": "testkey123456789", "v2": "testkey987654321"}, "v2")
+	require.NoError(t, err)
+	rotator := &KeyRotator{encryptor: keyring}
+
+	ciphertexts, err := dbJob.ciphertexts()
+	require.NoError(t, err)
+	assert.Len(t, ciphertexts, 1)
+	assert.True(t, rotator.needsRotation(ciphertexts))
+
+	job, err := dbJob.ToJob(keyring)
+	require.NoError(t, err)
+	assert.Equal(t, "token", job.HTTPJob.Auth.BearerToken.String)
+
+	rotated, err := toJobDB(keyring, job)
+	require.NoError(t, err)
+
+	rotatedJob := model.HTTPJob{}
+	require.NoError(t, json.Unmarshal(rotated.HTTPJob, &rotatedJob))
+	assert.Equal(t, "v2", security.KeyID(rotatedJob.Auth.BearerToken.String))
+
+	// Rotated jobs are skipped when the rotation is resumed
+	ciphertexts, err = rotated.ciphertexts()
+	require.NoError(t, err)
+	assert.False(t, rotator.needsRotation(ciphertexts))
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+)
+
+// GetNamespace returns the namespace with the given name. Namespaces without quotas are returned without limits.
+func (s *pgStore) GetNamespace(ctx context.Context, name string) (*model.Namespace, error) {
+	var dbNamespace namespaceDB
+
+	err := s.db.GetContext(ctx, &dbNamespace, `SELECT * FROM namespaces WHERE name = $1`, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &model.Namespace{Name: name}, nil
+		}
+		return nil, fmt.Errorf("failed to get namespace from database: %w", err)
+	}
+
+	return dbNamespace.ToNamespace(), nil
+}
+
+// ListNamespaces returns the namespaces with quotas.
+func (s *pgStore) ListNamespaces(ctx context.Context, limit, offset uint64) ([]model.Namespace, error) {
+	var dbNamespaces []namespaceDB
+
+	err := s.db.SelectContext(ctx, &dbNamespaces, `SELECT * FROM namespaces ORDER BY name LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespaces from database: %w", err)
+	}
+
+	namespaces := []model.Namespace{}
+	for _, dbNamespace := range dbNamespaces {
+		namespaces = append(namespaces, *dbNamespace.ToNamespace())
+	}
+
+	return namespaces, nil
+}
+
+func (s *pgStore) SetNamespaceQuota(ctx context.Context, name string, quota model.NamespaceQuota) (*model.Namespace, error) {
+	query := `
+		INSERT INTO namespaces (name, max_jobs, max_executions_per_minute)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE
+		SET max_jobs = $2, max_executions_per_minute = $3, updated_at = now()
+		RETURNING *
+	`
+
+	var dbNamespace namespaceDB
+	err := s.db.GetContext(ctx, &dbNamespace, query, name, quota.MaxJobs, quota.MaxExecutionsPerMinute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set namespace quota: %w", err)
+	}
+
+	return dbNamespace.ToNamespace(), nil
+}
@@ -5,33 +5,75 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/GLCharge/otelzap"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/audit"
 	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
 	"github.com/xBlaz3kx/distributed-scheduler/internal/store"
 	"gopkg.in/guregu/null.v4"
 )
 
 type pgStore struct {
-	db  *sqlx.DB
-	log *otelzap.Logger
+	db        *sqlx.DB
+	log       *otelzap.Logger
+	encryptor security.Encryptor
 }
 
-// New creates a new PostgresSQL store.
-func New(db *sqlx.DB, log *otelzap.Logger) store.Storer {
-	return &pgStore{
-		db:  db,
-		log: log,
+// Option configures a store.
+type Option func(s *pgStore)
+
+// WithEncryptor makes the store encrypt secrets with the encryptor, instead of the one set with SetEncryptor.
+func WithEncryptor(encryptor security.Encryptor) Option {
+	return func(s *pgStore) {
+		s.encryptor = encryptor
 	}
 }
 
-func (s *pgStore) UpdateJob(ctx context.Context, job *model.Job) error {
+// New creates a new PostgresSQL store. Secrets are encrypted with the encryptor set with SetEncryptor, unless
+// configured otherwise.
+func New(db *sqlx.DB, log *otelzap.Logger, opts ...Option) store.Storer {
+	s := &pgStore{
+		db:        db,
+		log:       log,
+		encryptor: defaultEncryptor,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
 
-	dbJob, err := toJobDB(job)
+	return s
+}
+
+func (s *pgStore) UpdateJob(ctx context.Context, job *model.Job, audit *model.JobAudit) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	if err := s.updateJob(ctx, tx, job, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// updateJob updates the job in the transaction, if its version still matches, and stores the new version.
+func (s *pgStore) updateJob(ctx context.Context, tx *sqlx.Tx, job *model.Job, audit *model.JobAudit) error {
+	dbJob, err := toJobDB(s.encryptor, job)
 	if err != nil {
 		return fmt.Errorf("failed to convert job to database job: %w", err)
 	}
@@ -41,24 +83,47 @@ func (s *pgStore) UpdateJob(ctx context.Context, job *model.Job) error {
 			jobs
 		SET
 			 type = :type,
+			 status = :status,
 			 execute_at = :execute_at,
 			 cron_schedule = :cron_schedule,
 			 http_job = :http_job,
 			 amqp_job = :amqp_job,
+			 tags = :tags,
+			 allowed_failed_runs = :allowed_failed_runs,
 			 updated_at = :updated_at,
-			 next_run = :next_run
-		WHERE id = :id
+			 next_run = :next_run,
+			 version = version + 1
+		WHERE id = :id AND namespace = :namespace AND version = :version
+		RETURNING version
 		`
 
-	_, err = s.db.NamedExecContext(ctx, query, dbJob)
+	query, args, err := tx.BindNamed(query, dbJob)
 	if err != nil {
+		return fmt.Errorf("failed to bind job: %w", err)
+	}
+
+	err = tx.GetContext(ctx, &job.Version, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return s.jobChangedError(ctx, job.Namespace, job.ID)
+		}
 		return fmt.Errorf("failed to update job in database: %w", err)
 	}
 
-	return nil
+	if err := createJobVersion(ctx, tx, job.ID); err != nil {
+		return err
+	}
+
+	if audit != nil {
+		if err := createJobAudit(ctx, tx, audit); err != nil {
+			return err
+		}
+	}
+
+	return createJobWebhookEvent(ctx, tx, model.WebhookEventJobUpdated, job, audit)
 }
 
-func (s *pgStore) GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool, limit, offset uint64) ([]*model.JobExecution, error) {
+func (s *pgStore) GetJobExecutions(ctx context.Context, namespace string, jobID uuid.UUID, failedOnly bool, limit, offset uint64) ([]*model.JobExecution, error) {
 
 	extraFilter := ""
 	if failedOnly {
@@ -71,12 +136,12 @@ func (s *pgStore) GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedO
 		FROM
 			job_executions
 		WHERE
-			job_id = $1` + extraFilter +
+			job_id = $1 AND namespace = $4` + extraFilter +
 		` ORDER BY start_time DESC
 		LIMIT $2 OFFSET $3`
 
 	var dbExecutions []*executionDB
-	err := s.db.SelectContext(ctx, &dbExecutions, query, jobID, limit, offset)
+	err := s.db.SelectContext(ctx, &dbExecutions, query, jobID, limit, offset, namespace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get job executions from database: %w", err)
 	}
@@ -91,9 +156,35 @@ func (s *pgStore) GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedO
 
 }
 
-func (s *pgStore) CreateJob(ctx context.Context, job *model.Job) error {
+// jobsUniqueKeyIndex enforces unique job keys within a namespace.
+const jobsUniqueKeyIndex = "jobs_namespace_unique_key_index"
+
+func (s *pgStore) CreateJob(ctx context.Context, job *model.Job, audit *model.JobAudit) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	if err := checkJobQuota(ctx, tx, job.Namespace, 1); err != nil {
+		return err
+	}
+
+	if err := s.insertJob(ctx, tx, job, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
 
-	dbJob, err := toJobDB(job)
+// insertJob inserts the job and its first version in the transaction.
+func (s *pgStore) insertJob(ctx context.Context, tx *sqlx.Tx, job *model.Job, audit *model.JobAudit) error {
+	dbJob, err := toJobDB(s.encryptor, job)
 	if err != nil {
 		return fmt.Errorf("failed to convert job to db job: %w", err)
 	}
@@ -102,8 +193,11 @@ func (s *pgStore) CreateJob(ctx context.Context, job *model.Job) error {
 	query := `
 	INSERT INTO jobs (
 		id,
+		namespace,
 	 	type,
 	 	status,
+	 	version,
+	 	unique_key,
 	 	execute_at,
 	 	cron_schedule,
 	 	http_job,
@@ -111,11 +205,15 @@ func (s *pgStore) CreateJob(ctx context.Context, job *model.Job) error {
 	 	created_at,
 	 	updated_at,
 	 	next_run,
-	    tags
+	    tags,
+	    allowed_failed_runs
 	) VALUES (
 	 	:id,
+	 	:namespace,
 	 	:type,
 	 	:status,
+	 	:version,
+	 	:unique_key,
 	 	:execute_at,
 	 	:cron_schedule,
 	 	:http_job,
@@ -123,27 +221,69 @@ func (s *pgStore) CreateJob(ctx context.Context, job *model.Job) error {
 	 	:created_at,
 	 	:updated_at,
 	 	:next_run,
-    	:tags
+    	:tags,
+    	:allowed_failed_runs
 	)
  `
 
-	_, err = s.db.NamedExecContext(ctx, query, dbJob)
+	_, err = tx.NamedExecContext(ctx, query, dbJob)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == jobsUniqueKeyIndex {
+			return errs.ErrJobUniqueKeyExists
+		}
 		return fmt.Errorf("failed to insert job into database: %w", err)
 	}
 
+	if err := createJobVersion(ctx, tx, job.ID); err != nil {
+		return err
+	}
+
+	if audit != nil {
+		if err := createJobAudit(ctx, tx, audit); err != nil {
+			return err
+		}
+	}
+
+	return createJobWebhookEvent(ctx, tx, model.WebhookEventJobCreated, job, audit)
+}
+
+// checkJobQuota returns ErrJobQuotaExceeded if creating the given number of jobs would exceed the maximum number of
+// jobs of the namespace. The quota row is locked until the transaction ends, so concurrent job creations in the
+// namespace can't exceed the quota.
+func checkJobQuota(ctx context.Context, tx *sqlx.Tx, namespace string, count int) error {
+	var maxJobs null.Int
+	err := tx.GetContext(ctx, &maxJobs, `SELECT max_jobs FROM namespaces WHERE name = $1 FOR UPDATE`, namespace)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get namespace quota: %w", err)
+	case !maxJobs.Valid:
+		return nil
+	}
+
+	var jobs int64
+	if err := tx.GetContext(ctx, &jobs, `SELECT count(*) FROM jobs WHERE namespace = $1`, namespace); err != nil {
+		return fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	if jobs+int64(count) > maxJobs.Int64 {
+		return errs.ErrJobQuotaExceeded
+	}
+
 	return nil
 }
 
-func (s *pgStore) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+func (s *pgStore) GetJob(ctx context.Context, namespace string, id uuid.UUID) (*model.Job, error) {
 	// create a JobDB struct to hold the result of the query
 	var dbJob jobDB
 
 	// execute the query to get the job by ID
 	query := `
-        SELECT * FROM jobs WHERE id = $1
+        SELECT * FROM jobs WHERE id = $1 AND namespace = $2
     `
-	err := s.db.GetContext(ctx, &dbJob, query, id)
+	err := s.db.GetContext(ctx, &dbJob, query, id, namespace)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errs.ErrJobNotFound
@@ -152,7 +292,7 @@ func (s *pgStore) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
 	}
 
 	// convert the JobDB struct to a Job struct
-	job, err := dbJob.ToJob()
+	job, err := dbJob.ToJob(s.encryptor)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert db job to job: %w", err)
 	}
@@ -160,49 +300,209 @@ func (s *pgStore) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
 	return job, nil
 }
 
-func (s *pgStore) DeleteJob(ctx context.Context, id uuid.UUID) error {
-	// delete job from database
+// GetJobByUniqueKey returns the job with the given unique key from the namespace.
+func (s *pgStore) GetJobByUniqueKey(ctx context.Context, namespace, uniqueKey string) (*model.Job, error) {
+	var dbJob jobDB
+
 	query := `
-        DELETE FROM jobs WHERE id = $1
-    `
-	_, err := s.db.ExecContext(ctx, query, id)
+		SELECT * FROM jobs WHERE namespace = $1 AND unique_key = $2
+	`
+	err := s.db.GetContext(ctx, &dbJob, query, namespace, uniqueKey)
 	if err != nil {
-		return fmt.Errorf("failed to delete job from database: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job from database: %w", err)
+	}
+
+	job, err := dbJob.ToJob(s.encryptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (s *pgStore) DeleteJob(ctx context.Context, job *model.Job, audit *model.JobAudit) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	if err := s.deleteJob(ctx, tx, job, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
-func (s *pgStore) ListJobs(ctx context.Context, limit, offset uint64, tags []string) ([]model.Job, error) {
-	// get all jobs from database
-	args := []interface{}{limit, offset}
+// deleteJob deletes the job in the transaction, if its version still matches. Deleting a job that no longer exists has
+// no effect.
+func (s *pgStore) deleteJob(ctx context.Context, tx *sqlx.Tx, job *model.Job, audit *model.JobAudit) error {
+	// delete job from database, if it wasn't changed since it was read
 	query := `
-        SELECT * FROM jobs ORDER BY id DESC LIMIT $1 OFFSET $2 
+        DELETE FROM jobs WHERE id = $1 AND namespace = $2 AND version = $3
     `
-	if len(tags) > 0 {
-		args = append(args, tags)
-		query = `
-			SELECT * FROM jobs WHERE tags @> $3 ORDER BY id DESC LIMIT $1 OFFSET $2 
-		`
+	result, err := tx.ExecContext(ctx, query, job.ID, job.Namespace, job.Version)
+	if err != nil {
+		return fmt.Errorf("failed to delete job from database: %w", err)
 	}
 
-	var dbJobs []jobDB
-	err := s.db.SelectContext(ctx, &dbJobs, query, args...)
+	rows, err := result.RowsAffected()
 	if err != nil {
+		return fmt.Errorf("failed to delete job from database: %w", err)
+	}
+
+	if rows == 0 {
+		if err := s.jobChangedError(ctx, job.Namespace, job.ID); !errors.Is(err, errs.ErrJobNotFound) {
+			return err
+		}
+
+		return nil
+	}
+
+	if audit != nil {
+		if err := createJobAudit(ctx, tx, audit); err != nil {
+			return err
+		}
+	}
+
+	return createJobWebhookEvent(ctx, tx, model.WebhookEventJobDeleted, job, audit)
+}
+
+// jobChangedError returns the reason a conditional write of a job affected no rows: ErrJobNotFound if the job
+// doesn't exist, or ErrJobConflict if it was changed since it was read.
+func (s *pgStore) jobChangedError(ctx context.Context, namespace string, id uuid.UUID) error {
+	var exists bool
+	err := s.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM jobs WHERE id = $1 AND namespace = $2)`, id, namespace)
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to get job from database: %w", err)
+	case exists:
+		return errs.ErrJobConflict
+	default:
+		return errs.ErrJobNotFound
+	}
+}
+
+// jobSortColumns maps the fields jobs can be sorted by to the expressions they are sorted by. Jobs that won't run again
+// are sorted after all other jobs by next run; the indexes use the same expressions.
+var jobSortColumns = map[model.JobSort]string{
+	model.JobSortCreatedAt: "created_at",
+	model.JobSortUpdatedAt: "updated_at",
+	model.JobSortNextRun:   "COALESCE(next_run, 'infinity'::timestamptz)",
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListJobs returns a page of the jobs of the namespace matching the query, with the total number of matching jobs.
+// Pages following a cursor start after the job the cursor points to (keyset pagination).
+func (s *pgStore) ListJobs(ctx context.Context, namespace string, query model.JobQuery) (*model.JobPage, error) {
+	conditions := []string{"namespace = $1"}
+	args := []interface{}{namespace}
+
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if query.Type != "" {
+		conditions = append(conditions, "type = "+arg(query.Type))
+	}
+
+	if query.Status != "" {
+		conditions = append(conditions, "status = "+arg(query.Status))
+	}
+
+	if len(query.Tags) > 0 {
+		if query.TagMatch == model.TagMatchAny {
+			conditions = append(conditions, "tags && "+arg(query.Tags))
+		} else {
+			conditions = append(conditions, "tags @> "+arg(query.Tags))
+		}
+	}
+
+	if query.URL != "" {
+		conditions = append(conditions, "http_job->>'url' ILIKE '%' || "+arg(likeEscaper.Replace(query.URL))+" || '%'")
+	}
+
+	if query.Exchange != "" {
+		conditions = append(conditions, "amqp_job->>'exchange' ILIKE '%' || "+arg(likeEscaper.Replace(query.Exchange))+" || '%'")
+	}
+
+	if query.NextRunFrom.Valid {
+		conditions = append(conditions, "next_run >= "+arg(query.NextRunFrom.Time))
+	}
+
+	if query.NextRunTo.Valid {
+		conditions = append(conditions, "next_run < "+arg(query.NextRunTo.Time))
+	}
+
+	if query.CreatedFrom.Valid {
+		conditions = append(conditions, "created_at >= "+arg(query.CreatedFrom.Time))
+	}
+
+	if query.CreatedTo.Valid {
+		conditions = append(conditions, "created_at < "+arg(query.CreatedTo.Time))
+	}
+
+	page := &model.JobPage{Jobs: []model.Job{}}
+
+	countQuery := fmt.Sprintf(`SELECT count(*) FROM jobs WHERE %s`, strings.Join(conditions, " AND "))
+	if err := s.db.GetContext(ctx, &page.Total, countQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	sort := query.SortOrDefault()
+	column, ok := jobSortColumns[sort.Field()]
+	if !ok {
+		return nil, errs.ErrInvalidJobSort
+	}
+
+	direction, comparison := "ASC", ">"
+	if sort.Descending() {
+		direction, comparison = "DESC", "<"
+	}
+
+	if query.Cursor != "" {
+		cursor, err := model.ParseJobCursor(query.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s::timestamptz, %s::uuid)", column, comparison, arg(cursor.Value), arg(cursor.ID)))
+	}
+
+	listQuery := fmt.Sprintf(`
+		SELECT * FROM jobs WHERE %s ORDER BY %s %s, id %s LIMIT %s OFFSET %s
+	`, strings.Join(conditions, " AND "), column, direction, direction, arg(query.Limit), arg(query.Offset))
+
+	var dbJobs []jobDB
+	if err := s.db.SelectContext(ctx, &dbJobs, listQuery, args...); err != nil {
 		return nil, fmt.Errorf("failed to get jobs from database: %w", err)
 	}
 
 	// convert JobDB structs to Job structs
-	jobs := []model.Job{}
 	for _, dbJob := range dbJobs {
-		job, err := dbJob.ToJob()
+		job, err := dbJob.ToJob(s.encryptor)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
 		}
-		jobs = append(jobs, *job)
+		page.Jobs = append(page.Jobs, *job)
 	}
 
-	return jobs, nil
+	if query.Limit > 0 && uint64(len(page.Jobs)) == query.Limit {
+		page.NextCursor = model.NewJobCursor(sort, &page.Jobs[len(page.Jobs)-1])
+	}
+
+	return page, nil
 }
 
 func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error) {
@@ -213,14 +513,36 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 
 	defer rollback(tx, s.log)
 
-	// Get jobs that should be run at time at and are not currently locked
+	// Get jobs that should be run at time at and are not currently locked. To schedule fairly across namespaces, the due
+	// jobs of each namespace are ranked by their next run time and picked round-robin: first the most overdue job of
+	// every namespace, then the second one, and so on. Namespaces with an execution quota only get as many jobs as
+	// they have executions left in the last minute, counting the jobs that are still running.
 	rows, err := tx.QueryContext(ctx, `
-	   SELECT *
+	   WITH due AS (
+	       SELECT id, namespace, row_number() OVER (PARTITION BY namespace ORDER BY next_run) AS rank
+	       FROM jobs
+	       WHERE next_run <= $1 AND (locked_until IS NULL OR locked_until <= $1) AND status = 'RUNNING'
+	   ),
+	   budget AS (
+	       SELECT n.name, n.max_executions_per_minute - (
+	           SELECT count(*) FROM job_executions e
+	           WHERE e.namespace = n.name AND e.start_time > $1::timestamptz - INTERVAL '1 minute'
+	       ) - (
+	           SELECT count(*) FROM jobs r
+	           WHERE r.namespace = n.name AND r.locked_until > $1
+	       ) AS remaining
+	       FROM namespaces n
+	       WHERE n.max_executions_per_minute IS NOT NULL
+	   )
+	   SELECT jobs.*
 	   FROM jobs
-	   WHERE next_run <= $1 AND (locked_until IS NULL OR locked_until <= $2) AND status = 'RUNNING'
-	   LIMIT $3
-	   FOR UPDATE SKIP LOCKED
-	`, at, at, limit)
+	   JOIN due ON due.id = jobs.id
+	   LEFT JOIN budget ON budget.name = due.namespace
+	   WHERE budget.remaining IS NULL OR due.rank <= budget.remaining
+	   ORDER BY due.rank, jobs.next_run
+	   LIMIT $2
+	   FOR UPDATE OF jobs SKIP LOCKED
+	`, at, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query jobs: %w", err)
 	}
@@ -235,7 +557,7 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 	var jobs []*model.Job
 	for _, dbJob := range dbJobs {
 
-		job, err := dbJob.ToJob()
+		job, err := dbJob.ToJob(s.encryptor)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
 		}
@@ -249,6 +571,17 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 	   `, lockedUntil, instanceID, job.ID); err != nil {
 			return nil, fmt.Errorf("failed to lock job: %w", err)
 		}
+
+		event := &model.ExecutionEvent{
+			Type:        model.ExecutionEventStarted,
+			Namespace:   job.Namespace,
+			JobID:       job.ID,
+			InstanceID:  null.NewString(instanceID, instanceID != ""),
+			ScheduledAt: job.NextRun,
+		}
+		if err := createExecutionEvent(ctx, tx, event, null.Int{}); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -258,33 +591,145 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 	return jobs, nil
 }
 
-func (s *pgStore) FinishJob(ctx context.Context, jobID uuid.UUID, nextRun null.Time) error {
+func (s *pgStore) FinishJob(ctx context.Context, jobID uuid.UUID, version int64, nextRun null.Time) error {
 
-	// finish job in database
+	// finish job in database. The next run time is only set if the job wasn't updated while it ran, as the update
+	// already set the next run time of the new definition.
 	query := `
 		UPDATE jobs SET 
-		        next_run = $1, 
+		        next_run = CASE WHEN version = $3 THEN $1 ELSE next_run END, 
 		        locked_until = null, locked_by = null, updated_at = now() 
 		WHERE id = $2
 	`
-	_, err := s.db.ExecContext(ctx, query, nextRun, jobID)
+	_, err := s.db.ExecContext(ctx, query, nextRun, jobID, version)
 	if err != nil {
 		return fmt.Errorf("failed to finish job in database: %w", err)
 	}
 
 	return nil
 }
-func (s *pgStore) CreateJobExecution(ctx context.Context, jobID uuid.UUID, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error {
 
-	// create job execution in database
+func (s *pgStore) CreateJobExecution(ctx context.Context, jobID uuid.UUID, jobVersion int64, instanceID string, scheduledAt null.Time, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error {
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	// create job execution in database, in the namespace of the job
 	query := `
-		INSERT INTO job_executions (job_id, start_time, end_time, status, error_message, created_at) 
-		VALUES ($1, $2, $3, $4, $5, now())
+		INSERT INTO job_executions (job_id, job_version, instance_id, scheduled_at, namespace, start_time, end_time, status, error_message, created_at) 
+		SELECT id, $6, NULLIF($7, ''), $8, namespace, $2, $3, $4, $5, now() FROM jobs WHERE id = $1
+		RETURNING id, namespace
 	`
-	_, err := s.db.ExecContext(ctx, query, jobID, startTime, stopTime, status, errorMessage)
-	if err != nil {
+
+	var execution struct {
+		ID        int64  `db:"id"`
+		Namespace string `db:"namespace"`
+	}
+
+	err = tx.GetContext(ctx, &execution, query, jobID, startTime, stopTime, status, errorMessage, jobVersion, instanceID, scheduledAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// the job was deleted while it ran
+		return nil
+	case err != nil:
 		return fmt.Errorf("failed to create job execution in database: %w", err)
 	}
 
+	event := &model.ExecutionEvent{
+		Type:        model.ExecutionEventFinished,
+		Namespace:   execution.Namespace,
+		JobID:       jobID,
+		InstanceID:  null.NewString(instanceID, instanceID != ""),
+		ScheduledAt: scheduledAt,
+	}
+	if err := createExecutionEvent(ctx, tx, event, null.IntFrom(execution.ID)); err != nil {
+		return err
+	}
+
+	if status == model.JobExecutionStatusFailed {
+		failed := model.JobExecution{
+			ID:           int(execution.ID),
+			JobID:        jobID,
+			JobVersion:   null.IntFrom(jobVersion),
+			ScheduledAt:  scheduledAt,
+			StartTime:    startTime,
+			EndTime:      stopTime,
+			ErrorMessage: errorMessage,
+			InstanceID:   event.InstanceID,
+		}
+		if err := createWebhookEvent(ctx, tx, model.WebhookEventExecutionFailed, execution.Namespace, jobID, failed); err != nil {
+			return err
+		}
+
+		if err := s.autoStopJob(ctx, tx, jobID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
+
+// autoStopJob stops the job in the transaction recording its failed execution, if it failed at least as many times in
+// a row as it allows. The stop is versioned and audited like a pause through the API, by the system actor.
+func (s *pgStore) autoStopJob(ctx context.Context, tx *sqlx.Tx, jobID uuid.UUID) error {
+	query := `
+		UPDATE jobs SET status = 'STOPPED', version = version + 1, updated_at = now()
+		WHERE id = $1 AND status = 'RUNNING' AND allowed_failed_runs <= (
+			SELECT count(*) FROM job_executions
+			WHERE job_id = $1 AND status = 'FAILED' AND start_time > COALESCE((
+				SELECT max(start_time) FROM job_executions WHERE job_id = $1 AND status = 'SUCCESSFUL'
+			), '-infinity'::timestamptz)
+		)
+		RETURNING *
+	`
+
+	var dbJob jobDB
+	err := tx.GetContext(ctx, &dbJob, query, jobID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// the job allows more failures, or isn't running
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to stop job in database: %w", err)
+	}
+
+	job, err := dbJob.ToJob(s.encryptor)
+	if err != nil {
+		return fmt.Errorf("failed to convert db job to job: %w", err)
+	}
+
+	running := *job
+	running.Status = model.JobStatusRunning
+
+	changes, err := model.DiffJobs(&running, job)
+	if err != nil {
+		return fmt.Errorf("failed to diff job: %w", err)
+	}
+
+	entry := &model.JobAudit{
+		JobID:     job.ID,
+		Namespace: job.Namespace,
+		Action:    model.AuditActionPause,
+		Actor:     audit.SystemActor,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	}
+
+	if err := createJobVersion(ctx, tx, job.ID); err != nil {
+		return err
+	}
+
+	if err := createJobAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return createJobWebhookEvent(ctx, tx, model.WebhookEventJobAutoStopped, job, entry)
+}
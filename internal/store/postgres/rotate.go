@@ -0,0 +1,442 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+// KeyRotator re-encrypts all stored secrets with the active encryption key.
+type KeyRotator struct {
+	db        *sqlx.DB
+	log       *otelzap.Logger
+	encryptor security.Encryptor
+}
+
+// RotationResult contains the number of re-encrypted records, not counting the skipped records that were already rotated.
+type RotationResult struct {
+	Jobs        int
+	JobVersions int
+	Credentials int
+
+	WebhookSubscriptions int
+}
+
+// NewKeyRotator creates a key rotator re-encrypting the secrets with the encryptor set with SetEncryptor.
+func NewKeyRotator(db *sqlx.DB, log *otelzap.Logger) *KeyRotator {
+	return &KeyRotator{
+		db:        db,
+		log:       log,
+		encryptor: defaultEncryptor,
+	}
+}
+
+// Rotate decrypts the secrets of every job, job version, credential and webhook subscription, as well as the encryption canary, with any known key
+// and re-encrypts them with the active key. Records are processed in batches, each in its own transaction, so the rotation can be safely
+// resumed if interrupted. Records whose secrets are already encrypted with the active key are skipped, so a resumed rotation only
+// re-encrypts the remaining records.
+func (r *KeyRotator) Rotate(ctx context.Context, batchSize int) (*RotationResult, error) {
+	result := &RotationResult{}
+
+	lastID := uuid.Nil
+	for {
+		scanned, rotated, nextID, err := r.rotateJobBatch(ctx, lastID, batchSize)
+		if err != nil {
+			return result, err
+		}
+
+		result.Jobs += rotated
+		if scanned < batchSize {
+			break
+		}
+
+		lastID = nextID
+		r.log.Info("Rotated job batch", zap.Int("jobs", result.Jobs))
+	}
+
+	lastVersion := jobVersionKey{}
+	for {
+		scanned, rotated, next, err := r.rotateJobVersionBatch(ctx, lastVersion, batchSize)
+		if err != nil {
+			return result, err
+		}
+
+		result.JobVersions += rotated
+		if scanned < batchSize {
+			break
+		}
+
+		lastVersion = next
+		r.log.Info("Rotated job version batch", zap.Int("jobVersions", result.JobVersions))
+	}
+
+	last := credentialKey{}
+	for {
+		scanned, rotated, next, err := r.rotateCredentialBatch(ctx, last, batchSize)
+		if err != nil {
+			return result, err
+		}
+
+		result.Credentials += rotated
+		if scanned < batchSize {
+			break
+		}
+
+		last = next
+		r.log.Info("Rotated credential batch", zap.Int("credentials", result.Credentials))
+	}
+
+	lastSubscriptionID := uuid.Nil
+	for {
+		scanned, rotated, nextID, err := r.rotateWebhookSubscriptionBatch(ctx, lastSubscriptionID, batchSize)
+		if err != nil {
+			return result, err
+		}
+
+		result.WebhookSubscriptions += rotated
+		if scanned < batchSize {
+			break
+		}
+
+		lastSubscriptionID = nextID
+		r.log.Info("Rotated webhook subscription batch", zap.Int("webhookSubscriptions", result.WebhookSubscriptions))
+	}
+
+	if err := r.rotateCanary(ctx); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// rotateJobBatch re-encrypts the jobs after the ID, returning the number of scanned and re-encrypted jobs and the ID of the
+// last scanned job.
+func (r *KeyRotator) rotateJobBatch(ctx context.Context, afterID uuid.UUID, batchSize int) (int, int, uuid.UUID, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, afterID, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, r.log)
+
+	var dbJobs []jobDB
+	err = tx.SelectContext(ctx, &dbJobs, `
+		SELECT * FROM jobs
+		WHERE id > $1 AND (http_job IS NOT NULL OR amqp_job IS NOT NULL)
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE
+	`, afterID, batchSize)
+	if err != nil {
+		return 0, 0, afterID, fmt.Errorf("failed to get jobs from database: %w", err)
+	}
+
+	lastID, count := afterID, 0
+	for _, dbJob := range dbJobs {
+		lastID = dbJob.ID
+
+		ciphertexts, err := dbJob.ciphertexts()
+		if err != nil {
+			return 0, 0, afterID, fmt.Errorf("failed to get the secrets of job %s: %w", dbJob.ID, err)
+		}
+
+		if !r.needsRotation(ciphertexts) {
+			continue
+		}
+
+		// Decrypt with whichever key the secrets were encrypted with
+		job, err := dbJob.ToJob(r.encryptor)
+		if err != nil {
+			return 0, 0, afterID, fmt.Errorf("failed to decrypt job %s: %w", dbJob.ID, err)
+		}
+
+		// Encrypt with the active key
+		rotated, err := toJobDB(r.encryptor, job)
+		if err != nil {
+			return 0, 0, afterID, fmt.Errorf("failed to encrypt job %s: %w", dbJob.ID, err)
+		}
+
+		_, err = tx.NamedExecContext(ctx, `UPDATE jobs SET http_job = :http_job, amqp_job = :amqp_job WHERE id = :id`, rotated)
+		if err != nil {
+			return 0, 0, afterID, fmt.Errorf("failed to update job %s: %w", dbJob.ID, err)
+		}
+
+		count++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, afterID, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(dbJobs), count, lastID, nil
+}
+
+// jobVersionKey is the primary key of a job version.
+type jobVersionKey struct {
+	JobID   uuid.UUID
+	Version int64
+}
+
+// rotateJobVersionBatch re-encrypts the job versions after the key, like rotateJobBatch.
+func (r *KeyRotator) rotateJobVersionBatch(ctx context.Context, after jobVersionKey, batchSize int) (int, int, jobVersionKey, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, after, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, r.log)
+
+	var dbVersions []jobVersionDB
+	err = tx.SelectContext(ctx, &dbVersions, `
+		SELECT * FROM job_versions
+		WHERE (job_id, version) > ($1, $2) AND (http_job IS NOT NULL OR amqp_job IS NOT NULL)
+		ORDER BY job_id, version
+		LIMIT $3
+		FOR UPDATE
+	`, after.JobID, after.Version, batchSize)
+	if err != nil {
+		return 0, 0, after, fmt.Errorf("failed to get job versions from database: %w", err)
+	}
+
+	last, count := after, 0
+	for _, dbVersion := range dbVersions {
+		last = jobVersionKey{JobID: dbVersion.JobID, Version: dbVersion.Version}
+
+		dbJob := dbVersion.toJobDB()
+
+		ciphertexts, err := dbJob.ciphertexts()
+		if err != nil {
+			return 0, 0, after, fmt.Errorf("failed to get the secrets of job %s version %d: %w", dbVersion.JobID, dbVersion.Version, err)
+		}
+
+		if !r.needsRotation(ciphertexts) {
+			continue
+		}
+
+		job, err := dbJob.ToJob(r.encryptor)
+		if err != nil {
+			return 0, 0, after, fmt.Errorf("failed to decrypt job %s version %d: %w", dbVersion.JobID, dbVersion.Version, err)
+		}
+
+		rotated, err := toJobDB(r.encryptor, job)
+		if err != nil {
+			return 0, 0, after, fmt.Errorf("failed to encrypt job %s version %d: %w", dbVersion.JobID, dbVersion.Version, err)
+		}
+
+		_, err = tx.NamedExecContext(ctx, `UPDATE job_versions SET http_job = :http_job, amqp_job = :amqp_job WHERE job_id = :id AND version = :version`, rotated)
+		if err != nil {
+			return 0, 0, after, fmt.Errorf("failed to update job %s version %d: %w", dbVersion.JobID, dbVersion.Version, err)
+		}
+
+		count++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, after, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(dbVersions), count, last, nil
+}
+
+// credentialKey is the primary key of a credential.
+type credentialKey struct {
+	Namespace string
+	Name      string
+}
+
+// rotateCredentialBatch re-encrypts the credentials after the key, like rotateJobBatch.
+func (r *KeyRotator) rotateCredentialBatch(ctx context.Context, after credentialKey, batchSize int) (int, int, credentialKey, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, after, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, r.log)
+
+	var dbCredentials []credentialDB
+	err = tx.SelectContext(ctx, &dbCredentials, `
+		SELECT * FROM credentials
+		WHERE (namespace, name) > ($1, $2)
+		ORDER BY namespace, name
+		LIMIT $3
+		FOR UPDATE
+	`, after.Namespace, after.Name, batchSize)
+	if err != nil {
+		return 0, 0, after, fmt.Errorf("failed to get credentials from database: %w", err)
+	}
+
+	last, count := after, 0
+	for _, dbCredential := range dbCredentials {
+		last = credentialKey{Namespace: dbCredential.Namespace, Name: dbCredential.Name}
+
+		ciphertexts, err := dbCredential.ciphertexts()
+		if err != nil {
+			return 0, 0, after, fmt.Errorf("failed to get the secrets of credential %s/%s: %w", dbCredential.Namespace, dbCredential.Name, err)
+		}
+
+		if !r.needsRotation(ciphertexts) {
+			continue
+		}
+
+		credential, err := dbCredential.ToCredential(r.encryptor)
+		if err != nil {
+			return 0, 0, after, fmt.Errorf("failed to decrypt credential %s/%s: %w", dbCredential.Namespace, dbCredential.Name, err)
+		}
+
+		rotated, err := toCredentialDB(r.encryptor, credential)
+		if err != nil {
+			return 0, 0, after, fmt.Errorf("failed to encrypt credential %s/%s: %w", dbCredential.Namespace, dbCredential.Name, err)
+		}
+
+		_, err = tx.NamedExecContext(ctx, `UPDATE credentials SET data = :data WHERE namespace = :namespace AND name = :name`, rotated)
+		if err != nil {
+			return 0, 0, after, fmt.Errorf("failed to update credential %s/%s: %w", dbCredential.Namespace, dbCredential.Name, err)
+		}
+
+		count++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, after, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(dbCredentials), count, last, nil
+}
+
+// rotateWebhookSubscriptionBatch re-encrypts the webhook subscriptions after the ID, like rotateJobBatch.
+func (r *KeyRotator) rotateWebhookSubscriptionBatch(ctx context.Context, afterID uuid.UUID, batchSize int) (int, int, uuid.UUID, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, afterID, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, r.log)
+
+	var dbSubscriptions []webhookSubscriptionDB
+	err = tx.SelectContext(ctx, &dbSubscriptions, `
+		SELECT * FROM webhook_subscriptions
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE
+	`, afterID, batchSize)
+	if err != nil {
+		return 0, 0, afterID, fmt.Errorf("failed to get webhook subscriptions from database: %w", err)
+	}
+
+	lastID, count := afterID, 0
+	for _, dbSubscription := range dbSubscriptions {
+		lastID = dbSubscription.ID
+
+		if !r.needsRotation([]string{dbSubscription.Secret}) {
+			continue
+		}
+
+		subscription, err := dbSubscription.ToWebhookSubscription(r.encryptor)
+		if err != nil {
+			return 0, 0, afterID, fmt.Errorf("failed to decrypt webhook subscription %s: %w", dbSubscription.ID, err)
+		}
+
+		rotated, err := toWebhookSubscriptionDB(r.encryptor, subscription)
+		if err != nil {
+			return 0, 0, afterID, fmt.Errorf("failed to encrypt webhook subscription %s: %w", dbSubscription.ID, err)
+		}
+
+		_, err = tx.NamedExecContext(ctx, `UPDATE webhook_subscriptions SET secret = :secret WHERE id = :id`, rotated)
+		if err != nil {
+			return 0, 0, afterID, fmt.Errorf("failed to update webhook subscription %s: %w", dbSubscription.ID, err)
+		}
+
+		count++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, afterID, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(dbSubscriptions), count, lastID, nil
+}
+
+// needsRotation reports whether any of the secrets of a record isn't encrypted with the active key. If the encryptor
+// can't tell, the record is always re-encrypted.
+func (r *KeyRotator) needsRotation(ciphertexts []string) bool {
+	checker, ok := r.encryptor.(security.RotationChecker)
+	if !ok {
+		return true
+	}
+
+	for _, ciphertext := range ciphertexts {
+		if checker.NeedsRotation(ciphertext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ciphertexts returns the encrypted secrets of the job, the same ones ToJob decrypts.
+func (j *jobDB) ciphertexts() ([]string, error) {
+	var ciphertexts []string
+
+	var httpJob *model.HTTPJob
+	if err := unmarshalNullableJSON(j.HTTPJob, &httpJob); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal http job")
+	}
+
+	if httpJob != nil {
+		auth := httpJob.Auth
+		switch auth.Type {
+		case model.AuthTypeBasic:
+			ciphertexts = append(ciphertexts, auth.Username.ValueOrZero(), auth.Password.ValueOrZero())
+		case model.AuthTypeBearer:
+			ciphertexts = append(ciphertexts, auth.BearerToken.ValueOrZero())
+		case model.AuthTypeHMAC:
+			ciphertexts = append(ciphertexts, auth.HMACSecret.ValueOrZero())
+		case model.AuthTypeOAuth2ClientCredentials:
+			if auth.OAuth2 != nil {
+				ciphertexts = append(ciphertexts, auth.OAuth2.ClientSecret.ValueOrZero())
+			}
+		}
+
+		if httpJob.TLS != nil && httpJob.TLS.ClientKey.Valid {
+			ciphertexts = append(ciphertexts, httpJob.TLS.ClientCert.ValueOrZero(), httpJob.TLS.ClientKey.ValueOrZero())
+		}
+	}
+
+	var amqpJob *model.AMQPJob
+	if err := unmarshalNullableJSON(j.AMQPJob, &amqpJob); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal amqp job")
+	}
+
+	if amqpJob != nil {
+		ciphertexts = append(ciphertexts, amqpJob.Connection)
+	}
+
+	return ciphertexts, nil
+}
+
+// ciphertexts returns the encrypted secrets of the credential.
+func (c *credentialDB) ciphertexts() ([]string, error) {
+	secrets := credentialSecrets{}
+	if err := json.Unmarshal(c.Data, &secrets); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal credential secrets")
+	}
+
+	var ciphertexts []string
+	for _, secret := range []null.String{secrets.Username, secrets.Password, secrets.BearerToken, secrets.HMACSecret, secrets.Connection} {
+		if secret.Valid {
+			ciphertexts = append(ciphertexts, secret.String)
+		}
+	}
+
+	return ciphertexts, nil
+}
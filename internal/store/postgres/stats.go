@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	"gopkg.in/guregu/null.v4"
+)
+
+type executionStatsDB struct {
+	Executions       int64      `db:"executions"`
+	Successful       int64      `db:"successful"`
+	Failed           int64      `db:"failed"`
+	DurationP50      null.Float `db:"duration_p50"`
+	DurationP95      null.Float `db:"duration_p95"`
+	DurationP99      null.Float `db:"duration_p99"`
+	SchedulingLagAvg null.Float `db:"scheduling_lag_avg"`
+}
+
+// GetExecutionStats aggregates the executions of the job, or of all jobs of the namespace if the job ID is null,
+// started from (inclusive) to (exclusive).
+func (s *pgStore) GetExecutionStats(ctx context.Context, namespace string, jobID uuid.NullUUID, from, to time.Time) (*model.ExecutionStats, error) {
+	// Durations and lags are in milliseconds. Percentiles and averages over no executions are NULL.
+	query := `
+		SELECT
+			count(*) AS executions,
+			count(*) FILTER (WHERE status = 'SUCCESSFUL') AS successful,
+			count(*) FILTER (WHERE status = 'FAILED') AS failed,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM end_time - start_time) * 1000) AS duration_p50,
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM end_time - start_time) * 1000) AS duration_p95,
+			percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM end_time - start_time) * 1000) AS duration_p99,
+			avg(EXTRACT(EPOCH FROM start_time - scheduled_at) * 1000) AS scheduling_lag_avg
+		FROM job_executions
+		WHERE namespace = $1 AND start_time >= $2 AND start_time < $3 AND ($4::uuid IS NULL OR job_id = $4)
+	`
+
+	var dbStats executionStatsDB
+	if err := s.db.GetContext(ctx, &dbStats, query, namespace, from, to, jobID); err != nil {
+		return nil, fmt.Errorf("failed to get execution stats from database: %w", err)
+	}
+
+	// Failure streaks are runs of consecutive failed executions of a job: the difference between the position of an
+	// execution among all executions of the job and among those with the same status is the same within a run.
+	streakQuery := `
+		SELECT COALESCE(max(length), 0) FROM (
+			SELECT count(*) AS length FROM (
+				SELECT
+					job_id,
+					status,
+					row_number() OVER (PARTITION BY job_id ORDER BY start_time, id) -
+					row_number() OVER (PARTITION BY job_id, status ORDER BY start_time, id) AS run
+				FROM job_executions
+				WHERE namespace = $1 AND start_time >= $2 AND start_time < $3 AND ($4::uuid IS NULL OR job_id = $4)
+			) runs
+			WHERE status = 'FAILED'
+			GROUP BY job_id, run
+		) streaks
+	`
+
+	stats := &model.ExecutionStats{
+		From:             from,
+		To:               to,
+		Executions:       dbStats.Executions,
+		Successful:       dbStats.Successful,
+		Failed:           dbStats.Failed,
+		DurationP50:      dbStats.DurationP50,
+		DurationP95:      dbStats.DurationP95,
+		DurationP99:      dbStats.DurationP99,
+		SchedulingLagAvg: dbStats.SchedulingLagAvg,
+	}
+
+	if err := s.db.GetContext(ctx, &stats.LongestFailureStreak, streakQuery, namespace, from, to, jobID); err != nil {
+		return nil, fmt.Errorf("failed to get failure streaks from database: %w", err)
+	}
+
+	if stats.Executions > 0 {
+		stats.SuccessRate = null.FloatFrom(float64(stats.Successful) / float64(stats.Executions))
+	}
+
+	return stats, nil
+}
+
+// GetJobFailureStreak returns the number of executions of the job that failed since it last succeeded.
+func (s *pgStore) GetJobFailureStreak(ctx context.Context, namespace string, jobID uuid.UUID) (int64, error) {
+	query := `
+		SELECT count(*) FROM job_executions
+		WHERE namespace = $1 AND job_id = $2 AND status = 'FAILED' AND start_time > COALESCE((
+			SELECT max(start_time) FROM job_executions WHERE namespace = $1 AND job_id = $2 AND status = 'SUCCESSFUL'
+		), '-infinity'::timestamptz)
+	`
+
+	var streak int64
+	if err := s.db.GetContext(ctx, &streak, query, namespace, jobID); err != nil {
+		return 0, fmt.Errorf("failed to get failure streak from database: %w", err)
+	}
+
+	return streak, nil
+}
+
+// CountFailingJobs returns the number of jobs of the namespace whose last execution failed.
+func (s *pgStore) CountFailingJobs(ctx context.Context, namespace string) (int64, error) {
+	query := `
+		SELECT count(*) FROM (
+			SELECT DISTINCT ON (job_id) status FROM job_executions
+			WHERE namespace = $1
+			ORDER BY job_id, start_time DESC, id DESC
+		) last
+		WHERE status = 'FAILED'
+	`
+
+	var count int64
+	if err := s.db.GetContext(ctx, &count, query, namespace); err != nil {
+		return 0, fmt.Errorf("failed to count failing jobs in database: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+)
+
+// createJobVersion stores the current definition of the job as a new version, in the transaction that changed it.
+func createJobVersion(ctx context.Context, tx *sqlx.Tx, jobID uuid.UUID) error {
+	query := `
+		INSERT INTO job_versions (job_id, version, type, execute_at, cron_schedule, http_job, amqp_job, tags, created_at)
+		SELECT id, version, type, execute_at, cron_schedule, http_job, amqp_job, tags, updated_at FROM jobs WHERE id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, jobID); err != nil {
+		return fmt.Errorf("failed to insert job version into database: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobVersions returns the versions of the job with the given ID from the namespace, newest first.
+func (s *pgStore) ListJobVersions(ctx context.Context, namespace string, jobID uuid.UUID, limit, offset uint64) ([]model.JobVersion, error) {
+	query := `
+		SELECT v.* FROM job_versions v JOIN jobs j ON j.id = v.job_id
+		WHERE v.job_id = $1 AND j.namespace = $2
+		ORDER BY v.version DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	var dbVersions []jobVersionDB
+	if err := s.db.SelectContext(ctx, &dbVersions, query, jobID, namespace, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to get job versions from database: %w", err)
+	}
+
+	versions := []model.JobVersion{}
+	for _, dbVersion := range dbVersions {
+		version, err := dbVersion.ToJobVersion(s.encryptor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job version to job version: %w", err)
+		}
+		versions = append(versions, *version)
+	}
+
+	return versions, nil
+}
+
+// GetJobVersion returns the given version of the job with the given ID from the namespace.
+func (s *pgStore) GetJobVersion(ctx context.Context, namespace string, jobID uuid.UUID, version int64) (*model.JobVersion, error) {
+	query := `
+		SELECT v.* FROM job_versions v JOIN jobs j ON j.id = v.job_id
+		WHERE v.job_id = $1 AND j.namespace = $2 AND v.version = $3
+	`
+
+	var dbVersion jobVersionDB
+	if err := s.db.GetContext(ctx, &dbVersion, query, jobID, namespace, version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrJobVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to get job version from database: %w", err)
+	}
+
+	jobVersion, err := dbVersion.ToJobVersion(s.encryptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db job version to job version: %w", err)
+	}
+
+	return jobVersion, nil
+}
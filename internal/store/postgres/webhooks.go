@@ -0,0 +1,343 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+	errs "github.com/xBlaz3kx/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+type webhookDeliveryDB struct {
+	ID             int64       `db:"id"`
+	SubscriptionID uuid.UUID   `db:"subscription_id"`
+	EventID        int64       `db:"event_id"`
+	Status         string      `db:"status"`
+	Attempts       int         `db:"attempts"`
+	NextAttemptAt  null.Time   `db:"next_attempt_at"`
+	LastStatusCode null.Int    `db:"last_status_code"`
+	LastError      null.String `db:"last_error"`
+	DeliveredAt    null.Time   `db:"delivered_at"`
+	CreatedAt      time.Time   `db:"created_at"`
+	UpdatedAt      time.Time   `db:"updated_at"`
+
+	// Columns of the subscription, only selected when claiming deliveries
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+
+	Event struct {
+		ID        int64     `db:"id"`
+		Namespace string    `db:"namespace"`
+		JobID     uuid.UUID `db:"job_id"`
+		Type      string    `db:"type"`
+		Data      []byte    `db:"data"`
+		CreatedAt time.Time `db:"created_at"`
+	} `db:"event"`
+}
+
+func (d *webhookDeliveryDB) ToModel() model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		Event: model.WebhookEvent{
+			ID:        d.Event.ID,
+			Type:      model.WebhookEventType(d.Event.Type),
+			Namespace: d.Event.Namespace,
+			JobID:     d.Event.JobID,
+			Data:      d.Event.Data,
+			CreatedAt: d.Event.CreatedAt,
+		},
+		Status:         model.WebhookDeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		URL:            d.URL,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+// webhookDeliveryEventColumns selects the event of deliveries, joined as e.
+const webhookDeliveryEventColumns = `
+	e.id AS "event.id",
+	e.namespace AS "event.namespace",
+	e.job_id AS "event.job_id",
+	e.type AS "event.type",
+	e.data AS "event.data",
+	e.created_at AS "event.created_at"
+`
+
+// createWebhookEvent writes the event to the outbox in the transaction of the change it announces, if an enabled
+// subscription of the namespace subscribes to its type.
+func createWebhookEvent(ctx context.Context, tx *sqlx.Tx, eventType model.WebhookEventType, namespace string, jobID uuid.UUID, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event data: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_events (namespace, job_id, type, data, created_at)
+		SELECT $1, $2, $3, $4, now()
+		WHERE EXISTS (
+			SELECT 1 FROM webhook_subscriptions WHERE namespace = $1 AND enabled AND $3 = ANY(events)
+		)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, namespace, jobID, eventType, payload); err != nil {
+		return fmt.Errorf("failed to insert webhook event into database: %w", err)
+	}
+
+	return nil
+}
+
+// createJobWebhookEvent writes a job event to the outbox. The job's secrets are never part of the event.
+func createJobWebhookEvent(ctx context.Context, tx *sqlx.Tx, eventType model.WebhookEventType, job *model.Job, audit *model.JobAudit) error {
+	return createWebhookEvent(ctx, tx, eventType, job.Namespace, job.ID, model.NewWebhookJobData(job, audit))
+}
+
+func (s *pgStore) CreateWebhookSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	dbSubscription, err := toWebhookSubscriptionDB(s.encryptor, subscription)
+	if err != nil {
+		return fmt.Errorf("failed to convert webhook subscription to db webhook subscription: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_subscriptions (id, namespace, url, events, enabled, secret, created_at, updated_at)
+		VALUES (:id, :namespace, :url, :events, :enabled, :secret, :created_at, :updated_at)
+	`
+
+	if _, err := s.db.NamedExecContext(ctx, query, dbSubscription); err != nil {
+		return fmt.Errorf("failed to insert webhook subscription into database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) GetWebhookSubscription(ctx context.Context, namespace string, id uuid.UUID) (*model.WebhookSubscription, error) {
+	var dbSubscription webhookSubscriptionDB
+
+	query := `
+		SELECT * FROM webhook_subscriptions WHERE id = $1 AND namespace = $2
+	`
+	err := s.db.GetContext(ctx, &dbSubscription, query, id, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrWebhookSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription from database: %w", err)
+	}
+
+	subscription, err := dbSubscription.ToWebhookSubscription(s.encryptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db webhook subscription to webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (s *pgStore) ListWebhookSubscriptions(ctx context.Context, namespace string, limit, offset uint64) ([]model.WebhookSubscription, error) {
+	query := `
+		SELECT * FROM webhook_subscriptions WHERE namespace = $1 ORDER BY created_at, id LIMIT $2 OFFSET $3
+	`
+
+	var dbSubscriptions []webhookSubscriptionDB
+	if err := s.db.SelectContext(ctx, &dbSubscriptions, query, namespace, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions from database: %w", err)
+	}
+
+	subscriptions := []model.WebhookSubscription{}
+	for _, dbSubscription := range dbSubscriptions {
+		subscription, err := dbSubscription.ToWebhookSubscription(s.encryptor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db webhook subscription to webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+
+	return subscriptions, nil
+}
+
+func (s *pgStore) UpdateWebhookSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	dbSubscription, err := toWebhookSubscriptionDB(s.encryptor, subscription)
+	if err != nil {
+		return fmt.Errorf("failed to convert webhook subscription to db webhook subscription: %w", err)
+	}
+
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = :url, events = :events, enabled = :enabled, secret = :secret, updated_at = :updated_at
+		WHERE id = :id AND namespace = :namespace
+	`
+
+	result, err := s.db.NamedExecContext(ctx, query, dbSubscription)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription in database: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return errs.ErrWebhookSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// DeleteWebhookSubscription deletes the subscription with its deliveries.
+func (s *pgStore) DeleteWebhookSubscription(ctx context.Context, namespace string, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1 AND namespace = $2`, id, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription from database: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return errs.ErrWebhookSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// ProcessWebhookEvents fans the oldest unprocessed events out to pending deliveries of the enabled subscriptions of
+// their namespace and type, and marks the events as processed. It returns the number of processed events. Concurrent
+// managers process different events.
+func (s *pgStore) ProcessWebhookEvents(ctx context.Context, limit uint64) (int64, error) {
+	query := `
+		WITH events AS (
+			SELECT id, namespace, type FROM webhook_events
+			WHERE processed_at IS NULL
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), deliveries AS (
+			INSERT INTO webhook_deliveries (subscription_id, event_id, status, next_attempt_at, created_at, updated_at)
+			SELECT s.id, e.id, $2, now(), now(), now()
+			FROM events e
+			JOIN webhook_subscriptions s ON s.namespace = e.namespace AND s.enabled AND e.type = ANY(s.events)
+			ON CONFLICT (subscription_id, event_id) DO NOTHING
+		)
+		UPDATE webhook_events SET processed_at = now() WHERE id IN (SELECT id FROM events)
+	`
+
+	result, err := s.db.ExecContext(ctx, query, limit, model.WebhookDeliveryPending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to process webhook events: %w", err)
+	}
+
+	processed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to process webhook events: %w", err)
+	}
+
+	return processed, nil
+}
+
+// ClaimWebhookDeliveries claims the pending deliveries of enabled subscriptions that are due at the given time, and
+// leases them until lockedUntil: if the delivery isn't completed by then, it is attempted again. Claiming a delivery
+// counts as an attempt.
+func (s *pgStore) ClaimWebhookDeliveries(ctx context.Context, at, lockedUntil time.Time, limit uint64) ([]model.WebhookDelivery, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1, next_attempt_at = $2, updated_at = now()
+			WHERE id IN (
+				SELECT d.id FROM webhook_deliveries d
+				JOIN webhook_subscriptions s ON s.id = d.subscription_id
+				WHERE d.status = $3 AND d.next_attempt_at <= $1 AND s.enabled
+				ORDER BY d.next_attempt_at
+				LIMIT $4
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT c.*, s.url, s.secret, ` + webhookDeliveryEventColumns + `
+		FROM claimed c
+		JOIN webhook_subscriptions s ON s.id = c.subscription_id
+		JOIN webhook_events e ON e.id = c.event_id
+		ORDER BY c.next_attempt_at, c.id
+	`
+
+	var dbDeliveries []webhookDeliveryDB
+	if err := s.db.SelectContext(ctx, &dbDeliveries, query, at, lockedUntil, model.WebhookDeliveryPending, limit); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]model.WebhookDelivery, 0, len(dbDeliveries))
+	for _, dbDelivery := range dbDeliveries {
+		delivery := dbDelivery.ToModel()
+
+		secret, err := s.encryptor.Decrypt(dbDelivery.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret of webhook subscription %s: %w", dbDelivery.SubscriptionID, err)
+		}
+
+		delivery.Secret = *secret
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// CompleteWebhookDelivery records the outcome of an attempt of the delivery.
+func (s *pgStore) CompleteWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5, delivered_at = $6, updated_at = now()
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, delivery.ID, delivery.Status, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError, delivery.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery in database: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries returns the deliveries of the subscription, newest first.
+func (s *pgStore) ListWebhookDeliveries(ctx context.Context, namespace string, subscriptionID uuid.UUID, limit, offset uint64) ([]model.WebhookDelivery, error) {
+	query := `
+		SELECT d.*, ` + webhookDeliveryEventColumns + `
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		JOIN webhook_events e ON e.id = d.event_id
+		WHERE s.namespace = $1 AND d.subscription_id = $2
+		ORDER BY d.id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	var dbDeliveries []webhookDeliveryDB
+	if err := s.db.SelectContext(ctx, &dbDeliveries, query, namespace, subscriptionID, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries from database: %w", err)
+	}
+
+	deliveries := make([]model.WebhookDelivery, 0, len(dbDeliveries))
+	for _, dbDelivery := range dbDeliveries {
+		deliveries = append(deliveries, dbDelivery.ToModel())
+	}
+
+	return deliveries, nil
+}
+
+// DeleteWebhookEvents deletes the processed events created before the given time without pending deliveries, together
+// with their deliveries.
+func (s *pgStore) DeleteWebhookEvents(ctx context.Context, before time.Time) error {
+	query := `
+		DELETE FROM webhook_events e
+		WHERE e.created_at < $1 AND e.processed_at IS NOT NULL AND NOT EXISTS (
+			SELECT 1 FROM webhook_deliveries d WHERE d.event_id = e.id AND d.status = $2
+		)
+	`
+
+	if _, err := s.db.ExecContext(ctx, query, before, model.WebhookDeliveryPending); err != nil {
+		return fmt.Errorf("failed to delete webhook events from database: %w", err)
+	}
+
+	return nil
+}
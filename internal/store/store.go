@@ -9,17 +9,90 @@ import (
 	"gopkg.in/guregu/null.v4"
 )
 
+// ExecutionEventsChannel is the notification channel the IDs of new execution events are sent on.
+const ExecutionEventsChannel = "execution_events"
+
+// Storer persists jobs, credentials and API keys. Jobs, credentials and API keys belong to a namespace, and can only
+// be accessed within their namespace; the namespace of jobs, credentials and API keys passed to the store is taken
+// from the namespace field.
+//
+// Changes to jobs are recorded in the audit log in the same transaction, if an audit entry is passed. Jobs are only
+// updated or deleted if their version still matches the version of the job passed to the store; otherwise
+// ErrJobConflict is returned.
 type Storer interface {
 	// CRUD operations for jobs
-	CreateJob(ctx context.Context, job *model.Job) error
-	GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
-	DeleteJob(ctx context.Context, id uuid.UUID) error
-	ListJobs(ctx context.Context, limit, offset uint64, tags []string) ([]model.Job, error)
-	UpdateJob(ctx context.Context, job *model.Job) error
+	CreateJob(ctx context.Context, job *model.Job, audit *model.JobAudit) error
+	GetJob(ctx context.Context, namespace string, id uuid.UUID) (*model.Job, error)
+	GetJobByUniqueKey(ctx context.Context, namespace, uniqueKey string) (*model.Job, error)
+	DeleteJob(ctx context.Context, job *model.Job, audit *model.JobAudit) error
+	ListJobs(ctx context.Context, namespace string, query model.JobQuery) (*model.JobPage, error)
+	UpdateJob(ctx context.Context, job *model.Job, audit *model.JobAudit) error
+
+	// Batch operations on jobs, each in a single transaction
+	CreateJobs(ctx context.Context, writes []model.JobWrite) error
+	UpdateJobs(ctx context.Context, writes []model.JobWrite) error
+	DeleteJobs(ctx context.Context, writes []model.JobWrite) error
+	GetJobsByID(ctx context.Context, namespace string, ids []uuid.UUID) ([]model.Job, error)
+
+	// Versions of job definitions, stored whenever a job is created or updated
+	ListJobVersions(ctx context.Context, namespace string, jobID uuid.UUID, limit, offset uint64) ([]model.JobVersion, error)
+	GetJobVersion(ctx context.Context, namespace string, jobID uuid.UUID, version int64) (*model.JobVersion, error)
 
-	// Get jobs to run
+	// Audit log of job changes
+	ListJobAudit(ctx context.Context, namespace string, filter model.AuditFilter, limit, offset uint64) ([]model.JobAudit, error)
+
+	// Get jobs to run, across all namespaces
 	GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error)
-	FinishJob(ctx context.Context, jobID uuid.UUID, nextRun null.Time) error
-	CreateJobExecution(ctx context.Context, jobID uuid.UUID, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error
-	GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool, limit, offset uint64) ([]*model.JobExecution, error)
+	FinishJob(ctx context.Context, jobID uuid.UUID, version int64, nextRun null.Time) error
+	CreateJobExecution(ctx context.Context, jobID uuid.UUID, jobVersion int64, instanceID string, scheduledAt null.Time, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error
+	GetJobExecutions(ctx context.Context, namespace string, jobID uuid.UUID, failedOnly bool, limit, offset uint64) ([]*model.JobExecution, error)
+	SearchJobExecutions(ctx context.Context, namespace string, query model.ExecutionQuery) (*model.ExecutionPage, error)
+
+	// Execution statistics; a null job ID covers all jobs of the namespace
+	GetExecutionStats(ctx context.Context, namespace string, jobID uuid.NullUUID, from, to time.Time) (*model.ExecutionStats, error)
+	GetJobFailureStreak(ctx context.Context, namespace string, jobID uuid.UUID) (int64, error)
+	CountFailingJobs(ctx context.Context, namespace string) (int64, error)
+
+	// Execution events, emitted when jobs are picked up to run and when their executions are recorded
+	GetExecutionEvent(ctx context.Context, id int64) (*model.ExecutionEvent, error)
+	ListExecutionEvents(ctx context.Context, namespace string, jobID uuid.NullUUID, afterID int64, limit uint64) ([]model.ExecutionEvent, error)
+	DeleteExecutionEvents(ctx context.Context, before time.Time) error
+
+	// Webhook subscriptions, and the deliveries of the webhook events written to the outbox along with job changes and
+	// failed executions
+	CreateWebhookSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+	GetWebhookSubscription(ctx context.Context, namespace string, id uuid.UUID) (*model.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context, namespace string, limit, offset uint64) ([]model.WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+	DeleteWebhookSubscription(ctx context.Context, namespace string, id uuid.UUID) error
+	ProcessWebhookEvents(ctx context.Context, limit uint64) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, at, lockedUntil time.Time, limit uint64) ([]model.WebhookDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	ListWebhookDeliveries(ctx context.Context, namespace string, subscriptionID uuid.UUID, limit, offset uint64) ([]model.WebhookDelivery, error)
+	DeleteWebhookEvents(ctx context.Context, before time.Time) error
+
+	// CRUD operations for named credentials
+	CreateCredential(ctx context.Context, credential *model.Credential) error
+	GetCredential(ctx context.Context, namespace, name string) (*model.Credential, error)
+	ListCredentials(ctx context.Context, namespace string, limit, offset uint64) ([]model.Credential, error)
+	UpdateCredential(ctx context.Context, credential *model.Credential) error
+	DeleteCredential(ctx context.Context, namespace, name string) error
+
+	// API keys
+	CreateAPIKey(ctx context.Context, apiKey *model.APIKey) error
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*model.APIKey, error)
+	ListAPIKeys(ctx context.Context, namespace string, limit, offset uint64) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, namespace string, id uuid.UUID, revokedAt time.Time) error
+	UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error
+
+	// Idempotency keys of requests, with the responses of completed requests
+	ReserveIdempotencyKey(ctx context.Context, key *model.IdempotencyKey) (reserved bool, existing *model.IdempotencyKey, err error)
+	CompleteIdempotencyKey(ctx context.Context, key *model.IdempotencyKey) error
+	DeleteIdempotencyKey(ctx context.Context, namespace, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) error
+
+	// Namespace quotas
+	GetNamespace(ctx context.Context, name string) (*model.Namespace, error)
+	ListNamespaces(ctx context.Context, limit, offset uint64) ([]model.Namespace, error)
+	SetNamespaceQuota(ctx context.Context, name string, quota model.NamespaceQuota) (*model.Namespace, error)
 }
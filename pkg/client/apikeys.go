@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// CreateAPIKey issues an API key. The key is only returned in this response.
+func (c *Client) CreateAPIKey(ctx context.Context, create APIKeyCreate, opts ...RequestOption) (*CreatedAPIKey, error) {
+	r := newRequest(http.MethodPost, "/v1/api-keys", opts)
+	r.body = create
+
+	apiKey := &CreatedAPIKey{}
+	if _, err := c.do(ctx, r, apiKey); err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// ListAPIKeys returns a page of the API keys of the namespace, identified by their prefix.
+func (c *Client) ListAPIKeys(ctx context.Context, page ListOptions, opts ...RequestOption) ([]APIKey, error) {
+	r := newRequest(http.MethodGet, "/v1/api-keys", opts)
+	page.setQuery(r.query)
+
+	var apiKeys []APIKey
+	if _, err := c.do(ctx, r, &apiKeys); err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+// IterateAPIKeys iterates over the API keys of the namespace, starting at the page.
+func (c *Client) IterateAPIKeys(page ListOptions, opts ...RequestOption) *Iterator[APIKey] {
+	return offsetIterator(page, func(ctx context.Context, page ListOptions) ([]APIKey, error) {
+		return c.ListAPIKeys(ctx, page, opts...)
+	})
+}
+
+// RevokeAPIKey revokes the API key with the ID.
+func (c *Client) RevokeAPIKey(ctx context.Context, id uuid.UUID, opts ...RequestOption) error {
+	_, err := c.do(ctx, newRequest(http.MethodDelete, "/v1/api-keys/"+id.String(), opts), nil)
+	return err
+}
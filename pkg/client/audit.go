@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// AuditFilter filters the audit log of a namespace. Empty fields match all entries.
+type AuditFilter struct {
+	Action AuditAction
+
+	// Actor made the change, e.g. "jwt:alice"
+	Actor string
+
+	// Range of creation times, including the start and excluding the end
+	From null.Time
+	To   null.Time
+}
+
+// ListAudit returns a page of the audit log of job changes in the namespace, newest first.
+func (c *Client) ListAudit(ctx context.Context, filter AuditFilter, page ListOptions, opts ...RequestOption) ([]JobAudit, error) {
+	r := newRequest(http.MethodGet, "/v1/audit", opts)
+	setString(r.query, "action", string(filter.Action))
+	setString(r.query, "actor", filter.Actor)
+	setTime(r.query, "from", filter.From)
+	setTime(r.query, "to", filter.To)
+	page.setQuery(r.query)
+
+	var entries []JobAudit
+	if _, err := c.do(ctx, r, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// IterateAudit iterates over the audit log of the namespace, starting at the page.
+func (c *Client) IterateAudit(filter AuditFilter, page ListOptions, opts ...RequestOption) *Iterator[JobAudit] {
+	return offsetIterator(page, func(ctx context.Context, page ListOptions) ([]JobAudit, error) {
+		return c.ListAudit(ctx, filter, page, opts...)
+	})
+}
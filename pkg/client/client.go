@@ -0,0 +1,404 @@
+// Package client is a Go client of the Management API of the scheduler.
+//
+// A client is created with the URL of the API and options, e.g. the API key:
+//
+//	c, err := client.New("http://localhost:8000", client.WithAPIKey(key))
+//
+//	job, _, err := c.CreateJob(ctx, &client.JobCreate{...})
+//
+// Failed requests return an *Error, which matches the error of its status code, e.g. errors.Is(err, client.ErrNotFound).
+// Requests that are safe to retry are retried when the API is unavailable, see RetryConfig.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
+)
+
+// Headers of the API.
+const (
+	APIKeyHeader             = "X-API-Key"
+	AuthorizationHeader      = "Authorization"
+	RequestIDHeader          = "X-Request-ID"
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	TotalCountHeader         = "X-Total-Count"
+	NextCursorHeader         = "X-Next-Cursor"
+	LastEventIDHeader        = "Last-Event-ID"
+)
+
+const (
+	// DefaultTimeout is the timeout of requests, unless set with WithTimeout.
+	DefaultTimeout = 30 * time.Second
+
+	// maxErrorBody limits how much of an error response is read.
+	maxErrorBody = 1 << 20
+
+	// defaultLimit is the page size of the API for lists without a limit.
+	defaultLimit = 10
+)
+
+// DefaultRetryConfig is the retry configuration of a client, unless set with WithRetry.
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// RetryConfig configures the retries of requests that failed because the API couldn't be reached, was unavailable
+// (502, 503 or 504) or limited the requests (429). Only requests that are safe to retry are retried: reads, updates
+// and deletions of a version, and creations with an idempotency key. Creations get a generated idempotency key if
+// retries are enabled, unless set with WithIdempotencyKey.
+type RetryConfig struct {
+	// MaxAttempts of a request, including the first one; 1 disables retries
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry, which is doubled with every retry up to MaxBackoff. A
+	// Retry-After header of the response takes precedence.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Client calls the Management API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	header     http.Header
+	timeout    time.Duration
+	retry      RetryConfig
+}
+
+// Option configures a client.
+type Option func(c *Client)
+
+// WithHTTPClient sets the HTTP client used to call the API, e.g. with a custom transport. Its timeout also applies to
+// streams of execution events, so it should be set with WithTimeout instead.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates requests with the API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.header.Set(APIKeyHeader, key)
+	}
+}
+
+// WithBearerToken authenticates requests with the bearer token, e.g. a JWT issued by an identity provider.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.header.Set(AuthorizationHeader, "Bearer "+token)
+	}
+}
+
+// WithHeader sets a header of all requests.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Set(key, value)
+	}
+}
+
+// WithTimeout sets the timeout of requests, including their retries; 0 disables the timeout. Streams of execution
+// events have no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetry sets the retry configuration.
+func WithRetry(retry RetryConfig) Option {
+	return func(c *Client) {
+		c.retry = retry
+	}
+}
+
+// New creates a client of the API at the base URL, e.g. http://localhost:8000.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL: unsupported scheme %q", parsed.Scheme)
+	}
+
+	c := &Client{
+		baseURL:    parsed,
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+		timeout:    DefaultTimeout,
+		retry:      DefaultRetryConfig,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+
+	return c, nil
+}
+
+// RequestOption configures a single request.
+type RequestOption func(r *request)
+
+// WithIdempotencyKey makes a creation safe to retry: retries with the same key return the response of the original
+// request, within the TTL of idempotency keys of the API.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(r *request) {
+		r.header.Set(IdempotencyKeyHeader, key)
+	}
+}
+
+// WithRequestID sets the ID of the request, which is recorded in the audit log of the API.
+func WithRequestID(requestID string) RequestOption {
+	return func(r *request) {
+		r.header.Set(RequestIDHeader, requestID)
+	}
+}
+
+// request is a request to the API.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+
+	// body is encoded as JSON, unless it is a json.RawMessage
+	body interface{}
+
+	// idempotent requests can be retried
+	idempotent bool
+
+	// stream requests have no timeout, and their response body is not read
+	stream bool
+}
+
+func newRequest(method, path string, opts []RequestOption) *request {
+	r := &request{
+		method:     method,
+		path:       path,
+		query:      make(url.Values),
+		header:     make(http.Header),
+		idempotent: method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// idempotencyKey makes the creation safe to retry with the idempotency key of the request, or a generated key if
+// retries are enabled.
+func (c *Client) idempotencyKey(r *request) {
+	if r.header.Get(IdempotencyKeyHeader) == "" && c.retry.MaxAttempts > 1 {
+		r.header.Set(IdempotencyKeyHeader, uuid.NewString())
+	}
+
+	r.idempotent = r.header.Get(IdempotencyKeyHeader) != ""
+}
+
+// response is a response of the API.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+
+	// stream is the unread body of stream requests
+	stream io.ReadCloser
+}
+
+// do sends the request, retrying it if possible, and decodes the response into out, if set. Responses with an error
+// status are returned as an *Error.
+func (c *Client) do(ctx context.Context, r *request, out interface{}) (*response, error) {
+	if c.timeout > 0 && !r.stream {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var body []byte
+	if r.body != nil {
+		var err error
+		if raw, ok := r.body.(json.RawMessage); ok {
+			body = raw
+		} else if body, err = json.Marshal(r.body); err != nil {
+			return nil, fmt.Errorf("encoding request: %w", err)
+		}
+	}
+
+	retry := backoff.NewExponentialBackOff()
+	retry.InitialInterval = c.retry.InitialBackoff
+	retry.MaxInterval = c.retry.MaxBackoff
+	retry.MaxElapsedTime = 0
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, r, body)
+
+		canRetry := r.idempotent && attempt < c.retry.MaxAttempts && ctx.Err() == nil
+		if err == nil && (!canRetry || !retryable(resp.status)) {
+			if resp.status >= http.StatusBadRequest {
+				return resp, newError(resp)
+			}
+
+			if out != nil && len(resp.body) > 0 {
+				if err := json.Unmarshal(resp.body, out); err != nil {
+					return resp, fmt.Errorf("decoding response: %w", err)
+				}
+			}
+
+			return resp, nil
+		}
+
+		if !canRetry {
+			return nil, err
+		}
+
+		wait := retry.NextBackOff()
+		if resp != nil {
+			wait = retryAfter(resp.header, wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				return resp, newError(resp)
+			}
+			return nil, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send sends the request once. The body of error responses is always read, so they can be retried or returned.
+func (c *Client) send(ctx context.Context, r *request, body []byte) (*response, error) {
+	endpoint := c.baseURL.JoinPath(r.path)
+	endpoint.RawQuery = r.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, r.method, endpoint.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range c.header {
+		httpReq.Header[key] = values
+	}
+
+	for key, values := range r.header {
+		httpReq.Header[key] = values
+	}
+
+	if httpReq.Header.Get("Accept") == "" {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	if body != nil && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response{status: httpResp.StatusCode, header: httpResp.Header}
+	if r.stream && httpResp.StatusCode < http.StatusBadRequest {
+		resp.stream = httpResp.Body
+		return resp, nil
+	}
+	defer httpResp.Body.Close()
+
+	limit := int64(maxErrorBody)
+	if httpResp.StatusCode < http.StatusBadRequest {
+		limit = -1
+	}
+
+	if resp.body, err = readBody(httpResp.Body, limit); err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// readBody reads the body, up to the limit if it isn't negative.
+func readBody(body io.Reader, limit int64) ([]byte, error) {
+	if limit >= 0 {
+		body = io.LimitReader(body, limit)
+	}
+
+	return io.ReadAll(body)
+}
+
+// retryable reports whether requests failing with the status may succeed when retried.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the wait of the Retry-After header in seconds, if it is set, or the given wait.
+func retryAfter(header http.Header, wait time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return wait
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// ListOptions selects a page of a list.
+type ListOptions struct {
+	// Limit of items of the page, 10 by default
+	Limit uint64
+
+	// Offset of the page
+	Offset uint64
+}
+
+// setQuery sets the limit and offset query parameters.
+func (o ListOptions) setQuery(query url.Values) {
+	if o.Limit > 0 {
+		query.Set("limit", strconv.FormatUint(o.Limit, 10))
+	}
+
+	if o.Offset > 0 {
+		query.Set("offset", strconv.FormatUint(o.Offset, 10))
+	}
+}
+
+// totalCount returns the total number of items of the X-Total-Count header.
+func totalCount(header http.Header) (int64, error) {
+	total, err := strconv.ParseInt(header.Get(TotalCountHeader), 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid X-Total-Count header")
+	}
+
+	return total, nil
+}
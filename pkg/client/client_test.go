@@ -0,0 +1,447 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/model"
+)
+
+// noBackoff retries requests immediately.
+var noBackoff = RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, append([]Option{WithRetry(noBackoff)}, opts...)...)
+	require.NoError(t, err)
+
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("http://localhost:8000/")
+	assert.NoError(t, err)
+
+	_, err = New("localhost:8000")
+	assert.Error(t, err)
+
+	_, err = New("ftp://localhost")
+	assert.Error(t, err)
+}
+
+func TestError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, "req-1")
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "job not found"})
+	})
+
+	_, err := c.GetJob(context.Background(), uuid.New())
+	require.Error(t, err)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrConflict)
+
+	apiErr := &Error{}
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "job not found", apiErr.Message)
+	assert.Equal(t, "req-1", apiErr.RequestID)
+}
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusPreconditionFailed, ErrPreconditionFailed},
+		{http.StatusUnprocessableEntity, ErrUnprocessableEntity},
+		{http.StatusPreconditionRequired, ErrPreconditionRequired},
+		{http.StatusTooManyRequests, ErrTooManyRequests},
+		{http.StatusServiceUnavailable, ErrUnavailable},
+		{http.StatusInternalServerError, ErrServer},
+		{http.StatusBadGateway, ErrServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			err := &Error{StatusCode: tt.status}
+			assert.ErrorIs(t, err, tt.want)
+			assert.NotErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "unavailable"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, Job{Version: 2})
+	})
+
+	job, err := c.GetJob(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), job.Version)
+	assert.Equal(t, int32(3), attempts.Load())
+
+	// Requests are given up after the maximum number of attempts
+	attempts.Store(-10)
+
+	_, err = c.GetJob(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, int32(-7), attempts.Load())
+}
+
+func TestRetryCreate(t *testing.T) {
+	var attempts atomic.Int32
+	keys := make(chan string, 3)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get(IdempotencyKeyHeader)
+		if attempts.Add(1) == 1 {
+			writeJSON(w, http.StatusBadGateway, errorResponse{Error: "bad gateway"})
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, Job{Version: 1})
+	})
+
+	_, created, err := c.CreateJob(context.Background(), &JobCreate{Type: JobTypeHTTP})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// Retries of a creation have the same generated idempotency key
+	first, second := <-keys, <-keys
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, second)
+
+	_, _, err = c.CreateJob(context.Background(), &JobCreate{Type: JobTypeHTTP}, WithIdempotencyKey("key-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", <-keys)
+}
+
+func TestNoRetry(t *testing.T) {
+	var attempts atomic.Int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "unavailable"})
+	}
+
+	// Rollbacks are not idempotent
+	c := newTestClient(t, handler)
+	_, err := c.RollbackJob(context.Background(), uuid.New(), 1)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, int32(1), attempts.Load())
+
+	// Creations are not retried without retries, and get no idempotency key
+	attempts.Store(0)
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(IdempotencyKeyHeader))
+		handler(w, r)
+	}, WithRetry(RetryConfig{MaxAttempts: 1}))
+
+	_, _, err = c.CreateJob(context.Background(), &JobCreate{})
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestTimeout(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}, WithTimeout(50*time.Millisecond))
+
+	_, err := c.GetJob(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestHeaders(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "dsk_key", r.Header.Get(APIKeyHeader))
+		assert.Equal(t, "req-1", r.Header.Get(RequestIDHeader))
+
+		switch r.Method {
+		case http.MethodPut:
+			assert.Equal(t, `"3"`, r.Header.Get("If-Match"))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			writeJSON(w, http.StatusOK, Job{Version: 4})
+		case http.MethodPatch:
+			assert.Equal(t, "*", r.Header.Get("If-Match"))
+			assert.Equal(t, "application/merge-patch+json", r.Header.Get("Content-Type"))
+
+			patch := map[string]interface{}{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+			assert.Equal(t, map[string]interface{}{"status": "STOPPED"}, patch)
+
+			writeJSON(w, http.StatusOK, Job{Status: JobStatusStopped})
+		case http.MethodDelete:
+			assert.Equal(t, `"5"`, r.Header.Get("If-Match"))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}, WithAPIKey("dsk_key"))
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	job, err := c.UpdateJob(ctx, id, 3, JobUpdate{}, WithRequestID("req-1"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), job.Version)
+
+	job, err = c.PatchJob(ctx, id, AnyVersion, map[string]interface{}{"status": JobStatusStopped}, WithRequestID("req-1"))
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusStopped, job.Status)
+
+	assert.NoError(t, c.DeleteJob(ctx, id, 5, WithRequestID("req-1")))
+}
+
+func TestIterateJobs(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{"a", "b"}, r.URL.Query()["tags"])
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+
+		w.Header().Set(TotalCountHeader, "3")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Header().Set(NextCursorHeader, "next")
+			writeJSON(w, http.StatusOK, []Job{{ID: ids[0]}, {ID: ids[1]}})
+		case "next":
+			assert.Empty(t, r.URL.Query().Get("offset"))
+			writeJSON(w, http.StatusOK, []Job{{ID: ids[2]}})
+		}
+	})
+
+	query := JobQuery{JobFilter: JobFilter{Tags: []string{"a", "b"}}, Limit: 2}
+
+	page, err := c.ListJobs(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, "next", page.NextCursor)
+
+	jobs, err := c.IterateJobs(query).All(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	for i, job := range jobs {
+		assert.Equal(t, ids[i], job.ID)
+	}
+}
+
+func TestIterateOffset(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+
+		var credentials []Credential
+		for i := offset; i < 5 && i < offset+2; i++ {
+			credentials = append(credentials, Credential{Name: fmt.Sprintf("credential-%d", i)})
+		}
+
+		writeJSON(w, http.StatusOK, credentials)
+	})
+
+	it := c.IterateCredentials(ListOptions{Limit: 2})
+
+	var names []string
+	for it.Next(context.Background()) {
+		names = append(names, it.Item().Name)
+	}
+
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"credential-0", "credential-1", "credential-2", "credential-3", "credential-4"}, names)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestIteratorError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden"})
+	})
+
+	it := c.IterateAPIKeys(ListOptions{})
+	assert.False(t, it.Next(context.Background()))
+	assert.ErrorIs(t, it.Err(), ErrForbidden)
+}
+
+func TestBatchError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/jobs:batchCreate", r.URL.Path)
+		writeJSON(w, http.StatusBadRequest, JobBatchResponse{
+			Error:   "invalid job batch",
+			Results: []JobBatchResult{{Index: 1, Status: http.StatusBadRequest, Error: "invalid cron schedule"}},
+		})
+	})
+
+	response, err := c.BatchCreateJobs(context.Background(), &JobBatchCreate{})
+	assert.ErrorIs(t, err, ErrBadRequest)
+	require.NotNil(t, response)
+	require.Len(t, response.Results, 1)
+	assert.Equal(t, "invalid cron schedule", response.Results[0].Error)
+}
+
+func TestStreamExecutions(t *testing.T) {
+	jobID := uuid.New()
+
+	var connections atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/jobs/"+jobID.String()+"/executions/stream", r.URL.Path)
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "retry: 0\n\n: heartbeat\n\n")
+
+		// The stream ends after every event, and is resumed after the last event
+		switch connections.Add(1) {
+		case 1:
+			assert.Equal(t, "4", r.Header.Get(LastEventIDHeader))
+			_, _ = fmt.Fprintf(w, "id: 5\nevent: execution.started\ndata: {\"id\":5,\"type\":\"execution.started\",\"job_id\":%q}\n\n", jobID)
+		case 2:
+			assert.Equal(t, "5", r.Header.Get(LastEventIDHeader))
+			_, _ = fmt.Fprintf(w, "id: 6\nevent: execution.finished\ndata: {\"id\":6,\"type\":\"execution.finished\",\"job_id\":%q}\n\n", jobID)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := c.StreamJobExecutions(ctx, jobID, 4)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	event, err := stream.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), event.ID)
+	assert.Equal(t, ExecutionEventStarted, event.Type)
+	assert.Equal(t, jobID, event.JobID)
+
+	event, err = stream.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), event.ID)
+	assert.Equal(t, ExecutionEventFinished, event.Type)
+	assert.Equal(t, int64(6), stream.LastEventID())
+
+	cancel()
+	_, err = stream.Next()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStreamError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "job not found"})
+	})
+
+	_, err := c.StreamJobExecutions(context.Background(), uuid.New(), 0)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// jsonFields collects the JSON fields of the type and of its nested types of the client and of the API, with the
+// types they are encoded as.
+func jsonFields(typ reflect.Type, prefix string, fields map[string]string) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case name == "-" || !field.IsExported():
+			continue
+		case field.Anonymous && name == "":
+			jsonFields(field.Type, prefix, fields)
+			continue
+		case name == "":
+			name = field.Name
+		}
+
+		fieldType, encoding := field.Type, ""
+		for fieldType.Kind() == reflect.Pointer || fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map {
+			encoding += fieldType.Kind().String() + " "
+			fieldType = fieldType.Elem()
+		}
+
+		isOwn := strings.HasSuffix(fieldType.PkgPath(), "/pkg/client") || strings.HasSuffix(fieldType.PkgPath(), "/internal/model")
+		if !isOwn {
+			fields[prefix+name] = encoding + fieldType.String()
+			continue
+		}
+
+		fields[prefix+name] = encoding + fieldType.Kind().String()
+		if fieldType.Kind() == reflect.Struct {
+			jsonFields(fieldType, prefix+name+".", fields)
+		}
+	}
+}
+
+func TestTypesMatchAPI(t *testing.T) {
+	tests := []struct {
+		client interface{}
+		api    interface{}
+	}{
+		{Job{}, model.Job{}},
+		{JobCreate{}, model.JobCreate{}},
+		{JobUpdate{}, model.JobUpdate{}},
+		{JobVersion{}, model.JobVersion{}},
+		{JobBatchCreate{}, model.JobBatchCreate{}},
+		{JobBatchUpdate{}, model.JobBatchUpdate{}},
+		{JobBatchDelete{}, model.JobBatchDelete{}},
+		{JobBatchResponse{}, model.JobBatchResponse{}},
+		{JobExecution{}, model.JobExecution{}},
+		{ExecutionEvent{}, model.ExecutionEvent{}},
+		{JobStats{}, model.JobStats{}},
+		{NamespaceStats{}, model.NamespaceStats{}},
+		{JobAudit{}, model.JobAudit{}},
+		{Credential{}, model.Credential{}},
+		{CredentialUpdate{}, model.CredentialUpdate{}},
+		{WebhookSubscription{}, model.WebhookSubscription{}},
+		{WebhookSubscriptionCreate{}, model.WebhookSubscriptionCreate{}},
+		{WebhookSubscriptionUpdate{}, model.WebhookSubscriptionUpdate{}},
+		{WebhookDelivery{}, model.WebhookDelivery{}},
+		{APIKey{}, model.APIKey{}},
+		{APIKeyCreate{}, model.APIKeyCreate{}},
+		{CreatedAPIKey{}, model.CreatedAPIKey{}},
+	}
+
+	for _, tt := range tests {
+		name := reflect.TypeOf(tt.client).Name()
+		t.Run(name, func(t *testing.T) {
+			clientFields, apiFields := map[string]string{}, map[string]string{}
+			jsonFields(reflect.TypeOf(tt.client), "", clientFields)
+			jsonFields(reflect.TypeOf(tt.api), "", apiFields)
+
+			assert.Equal(t, apiFields, clientFields)
+		})
+	}
+
+	for _, action := range []AuditAction{AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionRollback, AuditActionPause, AuditActionResume} {
+		assert.True(t, model.AuditAction(action).Valid(), action)
+	}
+}
@@ -0,0 +1,146 @@
+// Package clienttest serves the Management API in-process, for tests of services using the client:
+//
+//	server := clienttest.NewServer(t, clienttest.Config{Database: clienttest.DatabaseConfig{...}})
+//	c := server.Client()
+//
+// The server requires a Postgres database. Authentication is disabled: all requests are made by an admin of the
+// default namespace. Jobs are stored, but not executed, as no runner is started, and webhook events are not delivered.
+package clienttest
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GLCharge/otelzap"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	api "github.com/xBlaz3kx/distributed-scheduler/internal/api/http"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database/dbmigrate"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/events"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/idempotency"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/service/webhook"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/store/postgres"
+	"github.com/xBlaz3kx/distributed-scheduler/pkg/client"
+	"go.uber.org/zap"
+)
+
+// encryptionKey encrypts the secrets stored by the server; AES-256 requires 32 bytes.
+const encryptionKey = "clienttest-encryption-key-32byte"
+
+// DatabaseConfig is the connection configuration of the database of the server.
+type DatabaseConfig = database.Config
+
+// Config configures the test server.
+type Config struct {
+	// Database the server stores jobs in; it is migrated when the server starts. Tests should use a dedicated
+	// database, as stored jobs are not deleted.
+	Database DatabaseConfig
+}
+
+// Server is the Management API served in-process.
+type Server struct {
+	// URL of the API, e.g. http://127.0.0.1:38155
+	URL string
+
+	server *httptest.Server
+	db     *sqlx.DB
+	cancel context.CancelFunc
+	close  sync.Once
+}
+
+// NewServer starts a server, which is closed when the test ends. The secrets stored by the server are encrypted with
+// a test key, without changing the encryption key of the process.
+func NewServer(t testing.TB, cfg Config) *Server {
+	t.Helper()
+
+	log := otelzap.New(zap.NewNop())
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		t.Fatalf("opening database connection: %v", err)
+	}
+
+	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelMigrate()
+
+	if err := database.StatusCheck(migrateCtx, db); err != nil {
+		_ = db.Close()
+		t.Fatalf("checking database: %v", err)
+	}
+
+	if err := dbmigrate.Migrate(migrateCtx, db); err != nil {
+		_ = db.Close()
+		t.Fatalf("migrating database: %v", err)
+	}
+
+	encryptor, err := security.NewEncryptor(encryptionKey)
+	if err != nil {
+		_ = db.Close()
+		t.Fatalf("creating the encryptor: %v", err)
+	}
+
+	store := postgres.New(db, log, postgres.WithEncryptor(encryptor))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Execution events are streamed like by the manager
+	eventsService := events.NewService(store, database.NewListener(cfg.Database), log)
+	go eventsService.Run(ctx)
+
+	idempotencyService := idempotency.NewService(store, log)
+	go idempotencyService.Run(ctx)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	err = api.Api(router, api.APIMuxConfig{
+		Log:         log,
+		DB:          db,
+		Encryptor:   encryptor,
+		Idempotency: idempotencyService,
+		Events:      eventsService,
+		Webhooks:    webhook.NewService(store, log),
+	})
+	if err != nil {
+		cancel()
+		_ = db.Close()
+		t.Fatalf("setting up the API: %v", err)
+	}
+
+	server := &Server{
+		server: httptest.NewServer(router),
+		db:     db,
+		cancel: cancel,
+	}
+	server.URL = server.server.URL
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Client returns a client of the server with the options.
+func (s *Server) Client(opts ...client.Option) *client.Client {
+	c, err := client.New(s.URL, opts...)
+	if err != nil {
+		// the URL of the server is always valid
+		panic(err)
+	}
+
+	return c
+}
+
+// Close stops the server, ending the streams of execution events. It is called when the test ends.
+func (s *Server) Close() {
+	s.close.Do(func() {
+		s.cancel()
+		s.server.CloseClientConnections()
+		s.server.Close()
+		_ = s.db.Close()
+	})
+}
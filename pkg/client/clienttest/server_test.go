@@ -0,0 +1,143 @@
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/database/dbtest"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/security"
+	"github.com/xBlaz3kx/distributed-scheduler/internal/pkg/tests/docker"
+	"github.com/xBlaz3kx/distributed-scheduler/pkg/client"
+	"gopkg.in/guregu/null.v4"
+)
+
+var (
+	c *docker.Container
+
+	// dbErr is the reason the database couldn't be started; tests requiring it are skipped
+	dbErr error
+)
+
+func TestMain(m *testing.M) {
+	c, dbErr = dbtest.StartDB()
+	if dbErr == nil {
+		defer dbtest.StopDB(c)
+	}
+
+	m.Run()
+}
+
+func TestEncryptionKey(t *testing.T) {
+	_, err := security.NewEncryptor(encryptionKey)
+	assert.NoError(t, err)
+}
+
+func Test_Server(t *testing.T) {
+	if dbErr != nil {
+		t.Skipf("the database is not available: %v", dbErr)
+	}
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	server := NewServer(t, Config{Database: test.Config})
+	api := server.Client()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	for i := 0; i < 3; i++ {
+		job, created, err := api.CreateJob(ctx, &client.JobCreate{
+			Type:         client.JobTypeHTTP,
+			CronSchedule: null.StringFrom("*/5 * * * *"),
+			HTTPJob: &client.HTTPJob{
+				URL:    "https://example.com",
+				Method: "GET",
+				Auth:   client.Auth{Type: client.AuthTypeBearer, BearerToken: null.StringFrom("secret")},
+			},
+			Tags:      []string{"clienttest"},
+			UniqueKey: null.StringFrom(fmt.Sprintf("job-%d", i)),
+		})
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, int64(1), job.Version)
+		assert.False(t, job.HTTPJob.Auth.BearerToken.Valid, "secrets are not returned")
+	}
+
+	// The job with the unique key is returned
+	existing, created, err := api.CreateJob(ctx, &client.JobCreate{
+		Type:         client.JobTypeHTTP,
+		CronSchedule: null.StringFrom("*/5 * * * *"),
+		HTTPJob:      &client.HTTPJob{URL: "https://example.com", Method: "GET", Auth: client.Auth{Type: client.AuthTypeNone}},
+		UniqueKey:    null.StringFrom("job-0"),
+	})
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	// Retries with an idempotency key get the original response, with its ETag
+	body := `{"type": "HTTP", "cron_schedule": "*/5 * * * *", "http_job": {"url": "https://example.com", "method": "GET", "auth": {"type": "none"}}}`
+	createJob := func() *http.Response {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/v1/jobs", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(client.IdempotencyKeyHeader, "clienttest-key")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp
+	}
+
+	original, replayed := createJob(), createJob()
+	assert.Equal(t, http.StatusCreated, replayed.StatusCode)
+	assert.Equal(t, "true", replayed.Header.Get("Idempotent-Replayed"))
+	assert.NotEmpty(t, original.Header.Get("ETag"))
+	assert.Equal(t, original.Header.Get("ETag"), replayed.Header.Get("ETag"))
+
+	// List jobs
+	// -------------------------------------------------------------------------
+
+	jobs, err := api.IterateJobs(client.JobQuery{JobFilter: client.JobFilter{Tags: []string{"clienttest"}}, Limit: 2}).All(ctx)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 3)
+
+	// Update and delete a job
+	// -------------------------------------------------------------------------
+
+	cron := "0 * * * *"
+	updated, err := api.UpdateJob(ctx, existing.ID, existing.Version, client.JobUpdate{CronSchedule: &cron})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+
+	_, err = api.UpdateJob(ctx, existing.ID, existing.Version, client.JobUpdate{CronSchedule: &cron})
+	assert.ErrorIs(t, err, client.ErrPreconditionFailed)
+
+	paused, err := api.PatchJob(ctx, existing.ID, client.AnyVersion, map[string]interface{}{"status": client.JobStatusStopped})
+	require.NoError(t, err)
+	assert.Equal(t, client.JobStatusStopped, paused.Status)
+
+	versions, err := api.IterateJobVersions(existing.ID, client.ListOptions{}).All(ctx)
+	require.NoError(t, err)
+	assert.Len(t, versions, 3)
+
+	require.NoError(t, api.DeleteJob(ctx, existing.ID, paused.Version))
+
+	_, err = api.GetJob(ctx, existing.ID)
+	assert.ErrorIs(t, err, client.ErrNotFound)
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// credentialPath returns the path of the credential with the name.
+func credentialPath(name string) string {
+	return "/v1/credentials/" + url.PathEscape(name)
+}
+
+// CreateCredential creates a named credential that jobs can reference. The secrets of the credential are not returned.
+func (c *Client) CreateCredential(ctx context.Context, credential *Credential, opts ...RequestOption) (*Credential, error) {
+	r := newRequest(http.MethodPost, "/v1/credentials", opts)
+	r.body = credential
+
+	created := &Credential{}
+	if _, err := c.do(ctx, r, created); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// GetCredential returns the credential with the name, without its secrets.
+func (c *Client) GetCredential(ctx context.Context, name string, opts ...RequestOption) (*Credential, error) {
+	credential := &Credential{}
+	if _, err := c.do(ctx, newRequest(http.MethodGet, credentialPath(name), opts), credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// UpdateCredential replaces the secrets of the credential with the name.
+func (c *Client) UpdateCredential(ctx context.Context, name string, update CredentialUpdate, opts ...RequestOption) (*Credential, error) {
+	r := newRequest(http.MethodPut, credentialPath(name), opts)
+	r.body = update
+
+	credential := &Credential{}
+	if _, err := c.do(ctx, r, credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// DeleteCredential deletes the credential with the name. Credentials referenced by jobs can't be deleted.
+func (c *Client) DeleteCredential(ctx context.Context, name string, opts ...RequestOption) error {
+	_, err := c.do(ctx, newRequest(http.MethodDelete, credentialPath(name), opts), nil)
+	return err
+}
+
+// ListCredentials returns a page of the credentials of the namespace, without their secrets.
+func (c *Client) ListCredentials(ctx context.Context, page ListOptions, opts ...RequestOption) ([]Credential, error) {
+	r := newRequest(http.MethodGet, "/v1/credentials", opts)
+	page.setQuery(r.query)
+
+	var credentials []Credential
+	if _, err := c.do(ctx, r, &credentials); err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// IterateCredentials iterates over the credentials of the namespace, starting at the page.
+func (c *Client) IterateCredentials(page ListOptions, opts ...RequestOption) *Iterator[Credential] {
+	return offsetIterator(page, func(ctx context.Context, page ListOptions) ([]Credential, error) {
+		return c.ListCredentials(ctx, page, opts...)
+	})
+}
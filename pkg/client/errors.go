@@ -0,0 +1,84 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors matching the status codes of failed requests, e.g. errors.Is(err, client.ErrNotFound). The message and status
+// code of a failed request are returned as an *Error.
+var (
+	ErrBadRequest           = errors.New("bad request")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("forbidden")
+	ErrNotFound             = errors.New("not found")
+	ErrConflict             = errors.New("conflict")
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrUnprocessableEntity  = errors.New("unprocessable entity")
+	ErrPreconditionRequired = errors.New("precondition required")
+	ErrTooManyRequests      = errors.New("too many requests")
+	ErrUnavailable          = errors.New("service unavailable")
+	ErrServer               = errors.New("server error")
+)
+
+// statusErrors maps status codes to the errors matching them.
+var statusErrors = map[int]error{
+	http.StatusBadRequest:           ErrBadRequest,
+	http.StatusUnauthorized:         ErrUnauthorized,
+	http.StatusForbidden:            ErrForbidden,
+	http.StatusNotFound:             ErrNotFound,
+	http.StatusConflict:             ErrConflict,
+	http.StatusPreconditionFailed:   ErrPreconditionFailed,
+	http.StatusUnprocessableEntity:  ErrUnprocessableEntity,
+	http.StatusPreconditionRequired: ErrPreconditionRequired,
+	http.StatusTooManyRequests:      ErrTooManyRequests,
+	http.StatusServiceUnavailable:   ErrUnavailable,
+}
+
+// Error is returned for requests the API responded to with an error status.
+type Error struct {
+	// StatusCode of the response
+	StatusCode int
+
+	// Message of the error, as returned by the API
+	Message string
+
+	// RequestID of the request, as recorded in the audit log and the logs of the API
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("scheduler API: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is matches the error of the status code, e.g. ErrNotFound for 404 Not Found.
+func (e *Error) Is(target error) bool {
+	if err, ok := statusErrors[e.StatusCode]; ok {
+		return target == err
+	}
+
+	return target == ErrServer && e.StatusCode >= http.StatusInternalServerError
+}
+
+// errorResponse is the body of error responses.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// newError returns the error of a response with an error status.
+func newError(resp *response) *Error {
+	apiErr := &Error{
+		StatusCode: resp.status,
+		Message:    http.StatusText(resp.status),
+		RequestID:  resp.header.Get(RequestIDHeader),
+	}
+
+	body := errorResponse{}
+	if err := json.Unmarshal(resp.body, &body); err == nil && body.Error != "" {
+		apiErr.Message = body.Error
+	}
+
+	return apiErr
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultStreamRetry is the wait before reconnecting a stream, unless the API sets it.
+const defaultStreamRetry = time.Second
+
+// errInvalidEvent is returned for events that can't be decoded; the stream isn't reconnected.
+var errInvalidEvent = errors.New("invalid execution event")
+
+// SearchExecutions returns a page of the executions of all jobs in the namespace matching the query, the most recent
+// first, with the total number of matching executions and the cursor of the next page. Error messages are only
+// returned to, and can only be searched by, operators and admins.
+func (c *Client) SearchExecutions(ctx context.Context, query ExecutionQuery, opts ...RequestOption) (*ExecutionPage, error) {
+	r := newRequest(http.MethodGet, "/v1/executions", opts)
+	setString(r.query, "status", string(query.Status))
+	setTime(r.query, "from", query.From)
+	setTime(r.query, "to", query.To)
+	setString(r.query, "jobType", string(query.JobType))
+	setStrings(r.query, "tags", query.Tags)
+	setString(r.query, "tagMatch", string(query.TagMatch))
+	setString(r.query, "instanceId", query.InstanceID)
+	setString(r.query, "error", query.Error)
+	setString(r.query, "cursor", query.Cursor)
+	ListOptions{Limit: query.Limit, Offset: query.Offset}.setQuery(r.query)
+
+	page := &ExecutionPage{}
+	resp, err := c.do(ctx, r, &page.Executions)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Total, err = totalCount(resp.header); err != nil {
+		return nil, err
+	}
+
+	page.NextCursor = resp.header.Get(NextCursorHeader)
+	return page, nil
+}
+
+// IterateExecutions iterates over all the executions matching the query, starting at the page of the query. Pages are
+// fetched by cursor, so no execution is skipped or repeated while new executions are recorded.
+func (c *Client) IterateExecutions(query ExecutionQuery, opts ...RequestOption) *Iterator[JobExecution] {
+	return newIterator(func(ctx context.Context) ([]JobExecution, bool, error) {
+		page, err := c.SearchExecutions(ctx, query, opts...)
+		if err != nil {
+			return nil, false, err
+		}
+
+		query.Cursor = page.NextCursor
+		query.Offset = 0
+		return page.Executions, page.NextCursor != "", nil
+	})
+}
+
+// ExecutionStream receives execution events until its context is done. The stream reconnects when the API ends it,
+// and resumes after the last received event.
+type ExecutionStream struct {
+	ctx    context.Context
+	client *Client
+	path   string
+	opts   []RequestOption
+
+	lastEventID int64
+	retry       time.Duration
+
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+// StreamExecutions streams the execution events of all jobs in the namespace, after the last event ID, or the events
+// from now on if it is 0.
+func (c *Client) StreamExecutions(ctx context.Context, lastEventID int64, opts ...RequestOption) (*ExecutionStream, error) {
+	return c.stream(ctx, "/v1/executions/stream", lastEventID, opts)
+}
+
+// StreamJobExecutions streams the execution events of the job with the ID, after the last event ID, or the events from
+// now on if it is 0.
+func (c *Client) StreamJobExecutions(ctx context.Context, id uuid.UUID, lastEventID int64, opts ...RequestOption) (*ExecutionStream, error) {
+	return c.stream(ctx, jobPath(id, "executions", "stream"), lastEventID, opts)
+}
+
+func (c *Client) stream(ctx context.Context, path string, lastEventID int64, opts []RequestOption) (*ExecutionStream, error) {
+	stream := &ExecutionStream{
+		ctx:         ctx,
+		client:      c,
+		path:        path,
+		opts:        opts,
+		lastEventID: lastEventID,
+		retry:       defaultStreamRetry,
+	}
+
+	if err := stream.connect(); err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// Next returns the next event, waiting until one is emitted. It fails when the context of the stream is done, or the
+// stream couldn't be reconnected.
+func (s *ExecutionStream) Next() (*ExecutionEvent, error) {
+	for {
+		if s.body == nil {
+			if err := s.connect(); err != nil {
+				return nil, err
+			}
+		}
+
+		event, err := s.read()
+		if err == nil {
+			return event, nil
+		}
+
+		_ = s.Close()
+
+		if s.ctx.Err() != nil {
+			return nil, s.ctx.Err()
+		}
+
+		if errors.Is(err, errInvalidEvent) {
+			return nil, err
+		}
+
+		// The API ended the stream, or the connection failed
+		select {
+		case <-s.ctx.Done():
+			return nil, s.ctx.Err()
+		case <-time.After(s.retry):
+		}
+	}
+}
+
+// LastEventID returns the ID of the last received event, from which a new stream can resume.
+func (s *ExecutionStream) LastEventID() int64 {
+	return s.lastEventID
+}
+
+// Close closes the connection of the stream. Next reconnects it.
+func (s *ExecutionStream) Close() error {
+	if s.body == nil {
+		return nil
+	}
+
+	err := s.body.Close()
+	s.body = nil
+	s.reader = nil
+	return err
+}
+
+// connect requests the stream, resuming after the last received event.
+func (s *ExecutionStream) connect() error {
+	r := newRequest(http.MethodGet, s.path, s.opts)
+	r.header.Set("Accept", "text/event-stream")
+	r.stream = true
+	if s.lastEventID > 0 {
+		r.header.Set(LastEventIDHeader, strconv.FormatInt(s.lastEventID, 10))
+	}
+
+	resp, err := s.client.do(s.ctx, r, nil)
+	if err != nil {
+		return err
+	}
+
+	s.body = resp.stream
+	s.reader = bufio.NewReader(resp.stream)
+	return nil
+}
+
+// read reads the next event of the server-sent events. Comments, e.g. heartbeats, are skipped.
+func (s *ExecutionStream) read() (*ExecutionEvent, error) {
+	var data strings.Builder
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if data.Len() == 0 {
+				continue
+			}
+
+			event := &ExecutionEvent{}
+			if err := json.Unmarshal([]byte(data.String()), event); err != nil {
+				return nil, fmt.Errorf("%w: %v", errInvalidEvent, err)
+			}
+
+			s.lastEventID = event.ID
+			return event, nil
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		case "retry":
+			if retry, err := strconv.ParseInt(value, 10, 64); err == nil && retry >= 0 {
+				s.retry = time.Duration(retry) * time.Millisecond
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+)
+
+// Iterator iterates over the items of all pages of a list, fetching the pages as needed:
+//
+//	jobs := c.Jobs(client.JobQuery{})
+//	for jobs.Next(ctx) {
+//		job := jobs.Item()
+//	}
+//	if err := jobs.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	// fetch returns the next page, and whether more pages follow
+	fetch func(ctx context.Context) ([]T, bool, error)
+
+	page []T
+	item T
+	done bool
+	err  error
+}
+
+func newIterator[T any](fetch func(ctx context.Context) ([]T, bool, error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch}
+}
+
+// offsetIterator iterates over a list paginated by offset. Pages are fetched until a page isn't full.
+func offsetIterator[T any](opts ListOptions, list func(ctx context.Context, opts ListOptions) ([]T, error)) *Iterator[T] {
+	if opts.Limit == 0 {
+		opts.Limit = defaultLimit
+	}
+
+	return newIterator(func(ctx context.Context) ([]T, bool, error) {
+		page, err := list(ctx, opts)
+		if err != nil {
+			return nil, false, err
+		}
+
+		opts.Offset += uint64(len(page))
+		return page, uint64(len(page)) == opts.Limit, nil
+	})
+}
+
+// Next advances to the next item, fetching the next page if needed. It returns false when there are no more items or
+// fetching a page failed.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		var more bool
+		it.page, more, it.err = it.fetch(ctx)
+		it.done = !more
+	}
+
+	it.item, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Item returns the current item.
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error fetching a page, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All returns the remaining items.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	for it.Next(ctx) {
+		items = append(items, it.Item())
+	}
+
+	return items, it.Err()
+}
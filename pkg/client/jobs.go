@@ -0,0 +1,319 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// AnyVersion changes or deletes a job whatever its version, instead of failing with ErrPreconditionFailed if the job
+// was changed in the meantime.
+const AnyVersion int64 = 0
+
+// ifMatch returns the If-Match header expecting the version of a job.
+func ifMatch(version int64) string {
+	if version == AnyVersion {
+		return "*"
+	}
+
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// jobPath returns the path of the job, or of the job's subresource.
+func jobPath(id uuid.UUID, elem ...string) string {
+	path := "/v1/jobs/" + id.String()
+	for _, e := range elem {
+		path += "/" + e
+	}
+
+	return path
+}
+
+// CreateJob creates a job. If the namespace already has a job with the unique key of the job, the existing job is
+// returned instead, and created is false.
+func (c *Client) CreateJob(ctx context.Context, create *JobCreate, opts ...RequestOption) (job *Job, created bool, err error) {
+	r := newRequest(http.MethodPost, "/v1/jobs", opts)
+	r.body = create
+	c.idempotencyKey(r)
+
+	job = &Job{}
+	resp, err := c.do(ctx, r, job)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return job, resp.status == http.StatusCreated, nil
+}
+
+// GetJob returns the job with the ID.
+func (c *Client) GetJob(ctx context.Context, id uuid.UUID, opts ...RequestOption) (*Job, error) {
+	job := &Job{}
+	if _, err := c.do(ctx, newRequest(http.MethodGet, jobPath(id), opts), job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// UpdateJob updates the job with the ID, if it still has the version, or whatever its version with AnyVersion.
+func (c *Client) UpdateJob(ctx context.Context, id uuid.UUID, version int64, update JobUpdate, opts ...RequestOption) (*Job, error) {
+	r := newRequest(http.MethodPut, jobPath(id), opts)
+	r.header.Set("If-Match", ifMatch(version))
+	r.body = update
+
+	job := &Job{}
+	if _, err := c.do(ctx, r, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// PatchJob applies the JSON merge patch (RFC 7386) to the job with the ID, if it still has the version, or whatever its
+// version with AnyVersion. The patch is encoded as JSON, unless it is a json.RawMessage, e.g.
+// map[string]interface{}{"status": client.JobStatusStopped} to pause the job.
+func (c *Client) PatchJob(ctx context.Context, id uuid.UUID, version int64, patch interface{}, opts ...RequestOption) (*Job, error) {
+	r := newRequest(http.MethodPatch, jobPath(id), opts)
+	r.header.Set("If-Match", ifMatch(version))
+	r.header.Set("Content-Type", "application/merge-patch+json")
+	r.body = patch
+
+	// Merge patches are idempotent
+	r.idempotent = true
+
+	job := &Job{}
+	if _, err := c.do(ctx, r, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// DeleteJob deletes the job with the ID, if it still has the version, or whatever its version with AnyVersion.
+func (c *Client) DeleteJob(ctx context.Context, id uuid.UUID, version int64, opts ...RequestOption) error {
+	r := newRequest(http.MethodDelete, jobPath(id), opts)
+	r.header.Set("If-Match", ifMatch(version))
+
+	_, err := c.do(ctx, r, nil)
+	return err
+}
+
+// ListJobs returns a page of the jobs matching the query, with the total number of matching jobs and the cursor of
+// the next page.
+func (c *Client) ListJobs(ctx context.Context, query JobQuery, opts ...RequestOption) (*JobPage, error) {
+	r := newRequest(http.MethodGet, "/v1/jobs", opts)
+	setJobQuery(r.query, query)
+
+	page := &JobPage{}
+	resp, err := c.do(ctx, r, &page.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Total, err = totalCount(resp.header); err != nil {
+		return nil, err
+	}
+
+	page.NextCursor = resp.header.Get(NextCursorHeader)
+	return page, nil
+}
+
+// IterateJobs iterates over all the jobs matching the query, starting at the page of the query. Pages are fetched by
+// cursor, so no job is skipped or repeated when jobs are created or deleted in the meantime.
+func (c *Client) IterateJobs(query JobQuery, opts ...RequestOption) *Iterator[Job] {
+	return newIterator(func(ctx context.Context) ([]Job, bool, error) {
+		page, err := c.ListJobs(ctx, query, opts...)
+		if err != nil {
+			return nil, false, err
+		}
+
+		query.Cursor = page.NextCursor
+		query.Offset = 0
+		return page.Jobs, page.NextCursor != "", nil
+	})
+}
+
+// setJobQuery sets the query parameters of the job query.
+func setJobQuery(values url.Values, query JobQuery) {
+	setString(values, "type", string(query.Type))
+	setString(values, "status", string(query.Status))
+	setStrings(values, "tags", query.Tags)
+	setString(values, "tagMatch", string(query.TagMatch))
+	setString(values, "url", query.URL)
+	setString(values, "exchange", query.Exchange)
+	setTime(values, "nextRunFrom", query.NextRunFrom)
+	setTime(values, "nextRunTo", query.NextRunTo)
+	setTime(values, "createdFrom", query.CreatedFrom)
+	setTime(values, "createdTo", query.CreatedTo)
+	setString(values, "sort", string(query.Sort))
+	setString(values, "cursor", query.Cursor)
+	ListOptions{Limit: query.Limit, Offset: query.Offset}.setQuery(values)
+}
+
+// ListJobExecutions returns a page of the executions of the job with the ID, optionally only the failed ones. Error
+// messages are only returned to operators and admins.
+func (c *Client) ListJobExecutions(ctx context.Context, id uuid.UUID, failedOnly bool, page ListOptions, opts ...RequestOption) ([]JobExecution, error) {
+	r := newRequest(http.MethodGet, jobPath(id, "executions"), opts)
+	if failedOnly {
+		r.query.Set("failedOnly", "true")
+	}
+	page.setQuery(r.query)
+
+	response := struct {
+		Executions []JobExecution `json:"executions"`
+	}{}
+	if _, err := c.do(ctx, r, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Executions, nil
+}
+
+// IterateJobExecutions iterates over the executions of the job with the ID, starting at the page.
+func (c *Client) IterateJobExecutions(id uuid.UUID, failedOnly bool, page ListOptions, opts ...RequestOption) *Iterator[JobExecution] {
+	return offsetIterator(page, func(ctx context.Context, page ListOptions) ([]JobExecution, error) {
+		return c.ListJobExecutions(ctx, id, failedOnly, page, opts...)
+	})
+}
+
+// ListJobVersions returns a page of the versions of the job with the ID, newest first.
+func (c *Client) ListJobVersions(ctx context.Context, id uuid.UUID, page ListOptions, opts ...RequestOption) ([]JobVersion, error) {
+	r := newRequest(http.MethodGet, jobPath(id, "versions"), opts)
+	page.setQuery(r.query)
+
+	var versions []JobVersion
+	if _, err := c.do(ctx, r, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// IterateJobVersions iterates over the versions of the job with the ID, starting at the page.
+func (c *Client) IterateJobVersions(id uuid.UUID, page ListOptions, opts ...RequestOption) *Iterator[JobVersion] {
+	return offsetIterator(page, func(ctx context.Context, page ListOptions) ([]JobVersion, error) {
+		return c.ListJobVersions(ctx, id, page, opts...)
+	})
+}
+
+// RollbackJob restores the definition of the version of the job with the ID, as a new version.
+func (c *Client) RollbackJob(ctx context.Context, id uuid.UUID, version int64, opts ...RequestOption) (*Job, error) {
+	r := newRequest(http.MethodPost, jobPath(id, "rollback"), opts)
+	r.query.Set("version", strconv.FormatInt(version, 10))
+
+	job := &Job{}
+	if _, err := c.do(ctx, r, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ListJobAudit returns a page of the audit log of the job with the ID, newest first.
+func (c *Client) ListJobAudit(ctx context.Context, id uuid.UUID, page ListOptions, opts ...RequestOption) ([]JobAudit, error) {
+	r := newRequest(http.MethodGet, jobPath(id, "audit"), opts)
+	page.setQuery(r.query)
+
+	var entries []JobAudit
+	if _, err := c.do(ctx, r, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// IterateJobAudit iterates over the audit log of the job with the ID, starting at the page.
+func (c *Client) IterateJobAudit(id uuid.UUID, page ListOptions, opts ...RequestOption) *Iterator[JobAudit] {
+	return offsetIterator(page, func(ctx context.Context, page ListOptions) ([]JobAudit, error) {
+		return c.ListJobAudit(ctx, id, page, opts...)
+	})
+}
+
+// GetJobStats returns the execution statistics of the job with the ID over the windows, or the default window if
+// none are given.
+func (c *Client) GetJobStats(ctx context.Context, id uuid.UUID, windows []StatsWindow, opts ...RequestOption) (*JobStats, error) {
+	r := newRequest(http.MethodGet, jobPath(id, "stats"), opts)
+	setWindows(r.query, windows)
+
+	stats := &JobStats{}
+	if _, err := c.do(ctx, r, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// BatchCreateJobs creates all the jobs of the batch, or none of them if any of them is invalid. The results of invalid
+// batches, with the errors of the invalid jobs, are returned along with the error.
+func (c *Client) BatchCreateJobs(ctx context.Context, batch *JobBatchCreate, opts ...RequestOption) (*JobBatchResponse, error) {
+	r := newRequest(http.MethodPost, "/v1/jobs:batchCreate", opts)
+	r.body = batch
+	c.idempotencyKey(r)
+
+	return c.batch(ctx, r)
+}
+
+// BatchUpdateJobs applies the update to all the selected jobs, or none of them if any of them would be invalid. The
+// results of invalid batches, with the errors of the invalid jobs, are returned along with the error.
+func (c *Client) BatchUpdateJobs(ctx context.Context, batch *JobBatchUpdate, opts ...RequestOption) (*JobBatchResponse, error) {
+	r := newRequest(http.MethodPost, "/v1/jobs:batchUpdate", opts)
+	r.body = batch
+
+	return c.batch(ctx, r)
+}
+
+// BatchDeleteJobs deletes all the selected jobs. Jobs that don't exist are reported with status 404.
+func (c *Client) BatchDeleteJobs(ctx context.Context, batch *JobBatchDelete, opts ...RequestOption) (*JobBatchResponse, error) {
+	r := newRequest(http.MethodPost, "/v1/jobs:batchDelete", opts)
+	r.body = batch
+
+	return c.batch(ctx, r)
+}
+
+// batch sends a batch request. Invalid batches are rejected with the results of the invalid jobs.
+func (c *Client) batch(ctx context.Context, r *request) (*JobBatchResponse, error) {
+	response := &JobBatchResponse{}
+
+	resp, err := c.do(ctx, r, response)
+
+	apiErr := &Error{}
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		if jsonErr := json.Unmarshal(resp.body, response); jsonErr == nil && len(response.Results) > 0 {
+			return response, err
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// setString sets the query parameter, unless the value is empty.
+func setString(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
+
+// setStrings sets the values of the query parameter.
+func setStrings(values url.Values, key string, value []string) {
+	for _, v := range value {
+		values.Add(key, v)
+	}
+}
+
+// setTime sets the query parameter to the time in RFC 3339, if it is set.
+func setTime(values url.Values, key string, value null.Time) {
+	if value.Valid {
+		values.Set(key, value.Time.Format(time.RFC3339Nano))
+	}
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetStats returns the execution statistics of all jobs in the namespace over the windows, or the default window if
+// none are given.
+func (c *Client) GetStats(ctx context.Context, windows []StatsWindow, opts ...RequestOption) (*NamespaceStats, error) {
+	r := newRequest(http.MethodGet, "/v1/stats", opts)
+	setWindows(r.query, windows)
+
+	stats := &NamespaceStats{}
+	if _, err := c.do(ctx, r, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// setWindows sets the window query parameters of statistics.
+func setWindows(values url.Values, windows []StatsWindow) {
+	for _, window := range windows {
+		values.Add("window", string(window))
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// subscriptionPath returns the path of the webhook subscription, or of the subscription's subresource.
+func subscriptionPath(id uuid.UUID, elem ...string) string {
+	path := "/v1/subscriptions/" + id.String()
+	for _, e := range elem {
+		path += "/" + e
+	}
+
+	return path
+}
+
+// CreateSubscription subscribes a URL to events of the namespace. The returned subscription holds the secret signing
+// the deliveries, which is not returned again.
+func (c *Client) CreateSubscription(ctx context.Context, create *WebhookSubscriptionCreate, opts ...RequestOption) (*WebhookSubscription, error) {
+	r := newRequest(http.MethodPost, "/v1/subscriptions", opts)
+	r.body = create
+
+	subscription := &WebhookSubscription{}
+	if _, err := c.do(ctx, r, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription returns the webhook subscription with the ID, without its secret.
+func (c *Client) GetSubscription(ctx context.Context, id uuid.UUID, opts ...RequestOption) (*WebhookSubscription, error) {
+	subscription := &WebhookSubscription{}
+	if _, err := c.do(ctx, newRequest(http.MethodGet, subscriptionPath(id), opts), subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// UpdateSubscription updates the webhook subscription with the ID. If the update rotates the secret, the new secret is
+// returned.
+func (c *Client) UpdateSubscription(ctx context.Context, id uuid.UUID, update WebhookSubscriptionUpdate, opts ...RequestOption) (*WebhookSubscription, error) {
+	r := newRequest(http.MethodPut, subscriptionPath(id), opts)
+	r.body = update
+
+	// Rotating the secret again would return a different secret
+	r.idempotent = !update.RotateSecret
+
+	subscription := &WebhookSubscription{}
+	if _, err := c.do(ctx, r, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription deletes the webhook subscription with the ID and its delivery log.
+func (c *Client) DeleteSubscription(ctx context.Context, id uuid.UUID, opts ...RequestOption) error {
+	_, err := c.do(ctx, newRequest(http.MethodDelete, subscriptionPath(id), opts), nil)
+	return err
+}
+
+// ListSubscriptions returns a page of the webhook subscriptions of the namespace, without their secrets.
+func (c *Client) ListSubscriptions(ctx context.Context, page ListOptions, opts ...RequestOption) ([]WebhookSubscription, error) {
+	r := newRequest(http.MethodGet, "/v1/subscriptions", opts)
+	page.setQuery(r.query)
+
+	var subscriptions []WebhookSubscription
+	if _, err := c.do(ctx, r, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// IterateSubscriptions iterates over the webhook subscriptions of the namespace, starting at the page.
+func (c *Client) IterateSubscriptions(page ListOptions, opts ...RequestOption) *Iterator[WebhookSubscription] {
+	return offsetIterator(page, func(ctx context.Context, page ListOptions) ([]WebhookSubscription, error) {
+		return c.ListSubscriptions(ctx, page, opts...)
+	})
+}
+
+// ListDeliveries returns a page of the deliveries of the webhook subscription with the ID, newest first.
+func (c *Client) ListDeliveries(ctx context.Context, id uuid.UUID, page ListOptions, opts ...RequestOption) ([]WebhookDelivery, error) {
+	r := newRequest(http.MethodGet, subscriptionPath(id, "deliveries"), opts)
+	page.setQuery(r.query)
+
+	var deliveries []WebhookDelivery
+	if _, err := c.do(ctx, r, &deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// IterateDeliveries iterates over the deliveries of the webhook subscription with the ID, starting at the page.
+func (c *Client) IterateDeliveries(id uuid.UUID, page ListOptions, opts ...RequestOption) *Iterator[WebhookDelivery] {
+	return offsetIterator(page, func(ctx context.Context, page ListOptions) ([]WebhookDelivery, error) {
+		return c.ListDeliveries(ctx, id, page, opts...)
+	})
+}